@@ -1,39 +1,500 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"os/signal"
+	graphqlapi "purchase-cart-service/internal/api/graphql"
+	grpcapi "purchase-cart-service/internal/api/grpc"
 	httpapi "purchase-cart-service/internal/api/http"
 	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/certreload"
 	"purchase-cart-service/internal/config"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/invoice"
 	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/payment"
+	"purchase-cart-service/internal/domain/pricing"
 	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/internal/domain/search"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/internal/domain/vat"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/internal/domain/webhook"
+	"purchase-cart-service/internal/health"
+	"purchase-cart-service/internal/outbox"
+	"purchase-cart-service/models"
 	"purchase-cart-service/repository"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
+const (
+	defaultReadTimeout       = 15 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultShutdownTimeout   = 20 * time.Second
+)
+
+// ShutdownHook is invoked during graceful shutdown, after the HTTP server
+// stopped accepting requests and in-flight ones have been drained
+type ShutdownHook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook ShutdownHook
+}
+
 type Server struct {
-	router   *httpapi.Router
-	hostname string
-	port     int
+	router          *httpapi.Router
+	http            *http.Server
+	grpc            *grpcapi.Server
+	grpcAddr        string
+	checker         *health.Checker
+	tls             config.TLS
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+
+	mu    sync.Mutex
+	hooks []namedHook
 }
 
 func New(cfg *config.Config) *Server {
-	orderRepo := repository.NewOrderRepository(cfg.Database.Type)
+	outboxRepo := repository.NewOutboxRepository(cfg.Database.Type)
+	orderOpts := []repository.Option{repository.WithOutbox(outboxRepo)}
+	if cfg.Database.EventSourcing.Enabled {
+		orderOpts = append(orderOpts, repository.WithEventSourcing(cfg.Database.EventSourcing.SnapshotInterval))
+	}
+	orderRepo := repository.NewOrderRepository(cfg.Database.Type, orderOpts...)
 	vatRepo := repository.NewVatRateRepository(cfg.Database.Type)
-	productRepo := repository.NewProductRepository(cfg.Database.Type)
+	loadVATTerritories(cfg.VATTerritories, vatRepo)
+	productRepo := repository.NewProductRepository(cfg.Database.Type, repository.WithOutbox(outboxRepo))
+	router := httpapi.NewRouter()
 	srv := &Server{
-		router:   httpapi.NewRouter(),
-		hostname: cfg.WebApp.HostName,
-		port:     cfg.WebApp.Port,
+		router: router,
+		http: &http.Server{
+			Addr:              fmt.Sprintf("%s:%d", cfg.WebApp.HostName, cfg.WebApp.Port),
+			Handler:           router.Get(),
+			ReadTimeout:       cfg.WebApp.ReadTimeout.OrDefault(defaultReadTimeout),
+			ReadHeaderTimeout: cfg.WebApp.ReadHeaderTimeout.OrDefault(defaultReadHeaderTimeout),
+			WriteTimeout:      cfg.WebApp.WriteTimeout.OrDefault(defaultWriteTimeout),
+			IdleTimeout:       cfg.WebApp.IdleTimeout.OrDefault(defaultIdleTimeout),
+		},
+		checker:         health.NewChecker(),
+		tls:             cfg.WebApp.TLS,
+		shutdownTimeout: cfg.WebApp.ShutdownTimeout.OrDefault(defaultShutdownTimeout),
+		drainDelay:      cfg.WebApp.DrainDelay.Duration,
+	}
+	if err := router.Engine().SetTrustedProxies(cfg.WebApp.TrustedProxies); err != nil {
+		log.Printf("invalid trusted proxies %v: %v", cfg.WebApp.TrustedProxies, err)
 	}
-	hc := handlers.NewHealthCheckHandler()
-	oh := handlers.NewOrderHandler(order.NewService(orderRepo, vatRepo, productRepo))
-	ph := handlers.NewProductHandler(product.NewService(productRepo, vatRepo))
+
+	// repositories first: shutdown hooks run in reverse order, so they are
+	// closed after the components that use them
+	srv.registerRepository("outbox_repository", outboxRepo)
+	srv.registerRepository("order_repository", orderRepo)
+	srv.registerRepository("vat_rate_repository", vatRepo)
+	srv.registerRepository("product_repository", productRepo)
+
+	srv.router.Use(middleware.SecurityHeaders(cfg.Security), middleware.CORS(cfg.CORS))
+
+	// health probes are registered before the limits so they are never rate limited
+	hc := handlers.NewHealthCheckHandler(srv.checker)
 	srv.router.RegisterMethods("/", hc)
-	srv.router.RegisterMethods("/api/v1", oh, ph)
+
+	if cfg.Limits.MaxBodyBytes > 0 {
+		srv.router.Use(middleware.BodyLimit(cfg.Limits.MaxBodyBytes))
+	}
+	srv.router.Use(middleware.RateLimit(cfg.RateLimit, rateLimitClients(cfg)))
+	srv.router.Use(middleware.Buyer(pricingBuyers(cfg.Pricing)))
+
+	salesTaxRepo := repository.NewSalesTaxRepository(cfg.Database.Type)
+	srv.registerRepository("sales_tax_repository", salesTaxRepo)
+	categoryRepo := repository.NewCategoryRepository(cfg.Database.Type)
+	srv.registerRepository("category_repository", categoryRepo)
+	categorySvc := category.NewService(categoryRepo)
+	priceListRepo := repository.NewPriceListRepository(cfg.Database.Type)
+	srv.registerRepository("price_list_repository", priceListRepo)
+	pricingSvc := pricing.NewService(priceListRepo, productRepo)
+	bundleRepo := repository.NewBundleRepository(cfg.Database.Type)
+	srv.registerRepository("bundle_repository", bundleRepo)
+	bundleSvc := bundle.NewService(bundleRepo, productRepo)
+	orderSvc := order.NewService(orderRepo, vatRepo, productRepo, append(orderOptions(cfg, vatRepo, salesTaxRepo),
+		order.WithCategories(categorySvc), order.WithPricing(pricingSvc), order.WithBundles(bundleSvc))...)
+	productSvc := product.NewService(productRepo, vatRepo, product.WithPriceMode(cfg.Catalog.PriceMode), product.WithCategories(categorySvc), product.WithPricing(pricingSvc))
+	paymentRepo := repository.NewPaymentRepository(cfg.Database.Type)
+	srv.registerRepository("payment_repository", paymentRepo)
+	creditNoteRepo := repository.NewCreditNoteRepository(cfg.Database.Type)
+	srv.registerRepository("credit_note_repository", creditNoteRepo)
+	paymentSvc := payment.NewService(paymentRepo, creditNoteRepo, orderSvc, newPaymentGateway(cfg.Payments), cfg.Payments.Currency)
+	invoiceRepo := repository.NewInvoiceRepository(cfg.Database.Type)
+	srv.registerRepository("invoice_repository", invoiceRepo)
+	invoiceSvc := invoice.NewService(invoiceRepo, orderSvc, newInvoiceNumbering(cfg.Invoicing), newInvoiceSeller(cfg.Invoicing.Seller), cfg.Payments.Currency)
+	oh := handlers.NewOrderHandler(orderSvc, handlers.WithMaxOrderItems(cfg.Limits.MaxOrderItems))
+	ph := handlers.NewProductHandler(productSvc)
+	payh := handlers.NewPaymentHandler(paymentSvc, cfg.Payments.AutoCapture)
+	ih := handlers.NewInvoiceHandler(invoiceSvc)
+	ch := handlers.NewCategoryHandler(categorySvc, productSvc)
+	searchSvc := search.NewService(productRepo, productSvc, categorySvc)
+	sh := handlers.NewSearchHandler(searchSvc)
+	bh := handlers.NewBundleHandler(bundleSvc)
+	srv.router.RegisterMethods("/api/v1", oh, ph, payh, ih, ch, sh, bh)
+
+	bus := events.NewBus()
+	bus.Subscribe("payments", paymentSvc.HandleOrderEvent, events.TypeOrderCancelled)
+	bus.Subscribe("invoices", invoiceSvc.HandleOrderEvent, events.TypeOrderPaid)
+	bus.Subscribe("search", searchSvc.HandleProductEvent, search.ProductEventTypes...)
+	admin := []httpapi.IHandler{handlers.NewOrderAdminHandler(orderSvc), handlers.NewPaymentAdminHandler(paymentSvc), handlers.NewProductAdminHandler(productSvc), handlers.NewPriceListHandler(pricingSvc), handlers.NewBundleAdminHandler(bundleSvc)}
+	if cfg.Webhooks.Enabled {
+		webhookRepo := repository.NewWebhookRepository(cfg.Database.Type)
+		deadLetterRepo := repository.NewWebhookDeadLetterRepository(cfg.Database.Type)
+		dispatcher := webhook.NewDispatcher(webhook.DispatcherConfig{
+			Workers:        cfg.Webhooks.Workers,
+			QueueSize:      cfg.Webhooks.QueueSize,
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff.Duration,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff.Duration,
+			Timeout:        cfg.Webhooks.Timeout.Duration,
+		}, deadLetterRepo, nil)
+		webhookSvc := webhook.NewService(webhookRepo, deadLetterRepo, dispatcher)
+		bus.Subscribe("webhooks", webhookSvc.HandleOrderEvent, webhook.EventTypes...)
+		srv.registerRepository("webhook_repository", webhookRepo)
+		srv.registerRepository("webhook_dead_letter_repository", deadLetterRepo)
+		// registered after its repositories, so it drains before they are closed
+		srv.RegisterShutdownHook("webhook_dispatcher", dispatcher.Close)
+		admin = append(admin, handlers.NewWebhookHandler(webhookSvc))
+	}
+	if cfg.Admin.APIKey != "" {
+		srv.router.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(cfg.Admin.APIKey), admin...)
+	}
+
+	if cfg.GraphQL.Enabled {
+		schema, err := graphqlapi.NewSchema(orderSvc, productSvc, graphqlapi.Limits{
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("Error on building GraphQL schema. Error:%s", err.Error()))
+		}
+		srv.router.RegisterMethods("/", handlers.NewGraphQLHandler(schema))
+	}
+
+	if cfg.GRPC.Enabled {
+		srv.grpc = grpcapi.NewServer(orderSvc, productSvc, vat.NewService(vatRepo), cfg.Limits.MaxOrderItems)
+		srv.grpcAddr = fmt.Sprintf("%s:%d", cfg.GRPC.HostName, cfg.GRPC.Port)
+	}
+
+	relay := outbox.NewRelay(outboxRepo, outbox.RelayConfig{
+		PollInterval: cfg.Outbox.PollInterval.Duration,
+		BatchSize:    cfg.Outbox.BatchSize,
+		MaxAttempts:  cfg.Outbox.MaxAttempts,
+	}, outboxSinks(cfg.Outbox, bus)...)
+	relay.Start()
+	// registered last so that it runs first: the final relay pass still
+	// reaches the webhook dispatcher and the repositories
+	srv.RegisterShutdownHook("outbox_relay", relay.Stop)
+
+	srv.checker.Register("vat_rates", vatRatesLoaded(vatRepo))
+	srv.checker.Register("catalog", catalogNotEmpty(productRepo))
 	return srv
 }
 
+// newRoundingPolicies converts the validated rounding configuration
+func newRoundingPolicies(cfg config.Rounding) rounding.Policies {
+	policies := rounding.Policies{
+		Default:   rounding.Policy{Level: cfg.Level, Method: cfg.Method},
+		Countries: make(map[string]rounding.Policy, len(cfg.Countries)),
+	}
+	for _, c := range cfg.Countries {
+		policies.Countries[strings.ToUpper(c.CountryCode)] = rounding.Policy{Level: c.Level, Method: c.Method}
+	}
+	return policies
+}
+
+// newInvoiceNumbering converts the validated invoicing configuration
+func newInvoiceNumbering(cfg config.Invoicing) invoice.Numbering {
+	location, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	return invoice.Numbering{
+		Format:          cfg.NumberFormat,
+		Digits:          cfg.SequenceDigits,
+		FiscalYearStart: time.Month(cfg.FiscalYearStartMonth),
+		Location:        location,
+	}
+}
+
+func newInvoiceSeller(cfg config.Seller) invoice.Seller {
+	return invoice.Seller{
+		Name:  cfg.Name,
+		VATID: cfg.VATID,
+		Email: cfg.Email,
+		Address: models.Address{
+			Line1:       cfg.Line1,
+			Line2:       cfg.Line2,
+			City:        cfg.City,
+			PostalCode:  cfg.PostalCode,
+			Region:      cfg.Region,
+			CountryCode: cfg.CountryCode,
+		},
+	}
+}
+
+// loadVATTerritories fills the territory table of repo from the configured file
+func loadVATTerritories(cfg config.VATTerritories, repo repository.VatRateRepository) {
+	if cfg.File == "" {
+		return
+	}
+	territories, err := tax.LoadTerritoriesFile(cfg.File)
+	if err != nil {
+		panic(fmt.Sprintf("Error on loading the VAT territories. Error:%s", err.Error()))
+	}
+	if err := repo.ReplaceTerritories(territories); err != nil {
+		panic(fmt.Sprintf("Error on storing the VAT territories. Error:%s", err.Error()))
+	}
+}
+
+// orderOptions enables the US sales tax and the reverse charge when
+// configured. Validation restricts the verifier to config.VATIDVerifiers;
+// "Stub" is the only one so far
+func orderOptions(cfg *config.Config, vatRepo repository.VatRateRepository, salesTaxRepo repository.SalesTaxRepository) []order.ServiceOption {
+	opts := []order.ServiceOption{order.WithPriceMode(cfg.Catalog.PriceMode), order.WithRounding(newRoundingPolicies(cfg.Rounding))}
+	if cfg.SalesTax.RatesFile != "" {
+		regions, err := tax.LoadRatesFile(cfg.SalesTax.RatesFile)
+		if err != nil {
+			panic(fmt.Sprintf("Error on loading the sales tax table. Error:%s", err.Error()))
+		}
+		if err := salesTaxRepo.ReplaceAll(context.Background(), regions); err != nil {
+			panic(fmt.Sprintf("Error on storing the sales tax table. Error:%s", err.Error()))
+		}
+		origin := models.Destination{CountryCode: "US", Region: cfg.SalesTax.OriginState, PostalCode: cfg.SalesTax.OriginPostalCode}
+		opts = append(opts, order.WithTax(tax.NewService(vatRepo, tax.WithSalesTax(salesTaxRepo, origin))))
+	}
+	if cfg.ReverseCharge.Enabled {
+		opts = append(opts, order.WithReverseCharge(vatid.NewStubVerifier(cfg.ReverseCharge.Registered...), cfg.Invoicing.Seller.CountryCode))
+	}
+	return opts
+}
+
+// rateLimitClients maps the configured API keys to the client IDs the rate
+// limiter keys their buckets on
+func rateLimitClients(cfg *config.Config) map[string]string {
+	clients := make(map[string]string, len(cfg.Pricing.Clients)+1)
+	for _, client := range cfg.Pricing.Clients {
+		clients[client.APIKey] = client.ID
+	}
+	if cfg.Admin.APIKey != "" {
+		clients[cfg.Admin.APIKey] = "admin"
+	}
+	return clients
+}
+
+// pricingBuyers maps the API keys of the pricing clients to their buyers
+func pricingBuyers(cfg config.Pricing) map[string]pricing.Buyer {
+	buyers := make(map[string]pricing.Buyer, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		buyers[client.APIKey] = pricing.Buyer{Client: client.ID, Group: client.CustomerGroup}
+	}
+	return buyers
+}
+
+// newPaymentGateway returns the gateway selected in the configuration.
+// Validation restricts it to config.PaymentGateways; "Fake" is the only one so far
+func newPaymentGateway(cfg config.Payments) payment.Gateway {
+	return payment.NewFakeGateway()
+}
+
+func outboxSinks(cfg config.Outbox, bus *events.Bus) []outbox.Sink {
+	sinks := outbox.BusSinks(bus)
+	if cfg.LogSink {
+		sinks = append(sinks, outbox.NewLogSink(nil))
+	}
+	if cfg.FileSink != "" {
+		sinks = append(sinks, outbox.NewFileSink(cfg.FileSink))
+	}
+	if cfg.HTTPSink.URL != "" {
+		sinks = append(sinks, outbox.NewHTTPSink(cfg.HTTPSink.URL, cfg.HTTPSink.Timeout.Duration, nil))
+	}
+	return sinks
+}
+
+func vatRatesLoaded(repo repository.VatRateRepository) health.Check {
+	return func(ctx context.Context) error {
+		rates, err := repo.GetAllVATRates()
+		if err != nil {
+			return err
+		}
+		if len(rates) == 0 {
+			return errors.New("VAT rate table is empty")
+		}
+		return nil
+	}
+}
+
+func catalogNotEmpty(repo repository.ProductRepository) health.Check {
+	return func(ctx context.Context) error {
+		products, err := repo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return errors.New("product catalog is empty")
+		}
+		return nil
+	}
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.http.Addr
+}
+
+// Checker returns the readiness checker, to register additional checks
+func (s *Server) Checker() *health.Checker {
+	return s.checker
+}
+
+// Handler returns the HTTP handler served by the server
+func (s *Server) Handler() http.Handler {
+	return s.http.Handler
+}
+
+// RegisterShutdownHook adds a hook executed on shutdown. Hooks run in reverse
+// registration order, so resources are released in the opposite order they were acquired
+func (s *Server) RegisterShutdownHook(name string, hook ShutdownHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, namedHook{name: name, hook: hook})
+}
+
+func (s *Server) registerRepository(name string, repo interface{}) {
+	if closer, ok := repo.(repository.Closer); ok {
+		s.RegisterShutdownHook(name, closer.Close)
+	}
+	if pinger, ok := repo.(repository.Pinger); ok {
+		s.checker.Register(name, pinger.Ping)
+	}
+}
+
+// Start listens on the configured address and serves requests until
+// SIGINT or SIGTERM is received, then shuts down gracefully
 func (s *Server) Start() error {
-	return http.ListenAndServe(fmt.Sprintf("%s:%d", s.hostname, s.port), s.router.Get())
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return s.Run(ctx)
+}
+
+// Run listens on the configured addresses and serves HTTP and, when enabled,
+// gRPC requests until ctx is done
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	if s.grpc != nil {
+		grpcLn, err := net.Listen("tcp", s.grpcAddr)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		log.Printf("gRPC API listening on %s", s.grpcAddr)
+		go func() {
+			if err := s.grpc.Serve(grpcLn); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is done, then drains in-flight
+// requests within the shutdown timeout and runs the shutdown hooks.
+// When TLS is enabled the certificate is reloaded as it changes on disk
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	errCh := make(chan error, 1)
+	if s.tls.Enabled {
+		reloader, err := certreload.New(s.tls.CertFile, s.tls.KeyFile)
+		if err != nil {
+			return err
+		}
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		defer stopWatch()
+		go reloader.Watch(watchCtx, s.tls.ReloadInterval.OrDefault(time.Minute))
+
+		s.http.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+		go func() {
+			errCh <- s.http.ServeTLS(ln, "", "")
+		}()
+	} else {
+		go func() {
+			errCh <- s.http.Serve(ln)
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutdown signal received, draining connections")
+	return s.Shutdown()
+}
+
+// Shutdown marks the service as not ready and keeps serving for the drain
+// delay, so that load balancers stop routing to it; then it stops accepting
+// new connections, waits for in-flight requests to complete within the
+// shutdown timeout and runs the registered hooks
+func (s *Server) Shutdown() error {
+	s.checker.SetShuttingDown()
+	if s.drainDelay > 0 {
+		log.Printf("Reporting not ready, still serving for %s", s.drainDelay)
+		time.Sleep(s.drainDelay)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	var wg sync.WaitGroup
+	if s.grpc != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.grpc.Shutdown(ctx)
+		}()
+	}
+	if err := s.http.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	hooks := make([]namedHook, len(s.hooks))
+	copy(hooks, s.hooks)
+	s.mu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %q: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,122 @@
+package graphqlapi
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listMultiplier is the assumed size of list fields when estimating the cost of a query
+const listMultiplier = 10
+
+// Limits bounds the queries accepted by the endpoint
+type Limits struct {
+	// MaxComplexity is the maximum estimated cost: every field costs 1 and
+	// the cost of the selection of a list field is multiplied by listMultiplier
+	MaxComplexity int
+	// MaxDepth is the maximum nesting of selection sets
+	MaxDepth int
+}
+
+type complexityWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+	maxDepth  int
+}
+
+// checkLimits estimates the cost and depth of the operation to execute
+func checkLimits(schema graphql.Schema, doc *ast.Document, operationName string, limits Limits) error {
+	w := &complexityWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			w.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				if operation == nil {
+					operation = d
+				}
+			}
+		}
+	}
+	if operation == nil {
+		// the executor reports the missing operation
+		return nil
+	}
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	if root == nil {
+		return nil
+	}
+	cost := w.selectionSet(root, operation.SelectionSet, 1)
+	if limits.MaxDepth > 0 && w.maxDepth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", w.maxDepth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && cost > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", cost, limits.MaxComplexity)
+	}
+	return nil
+}
+
+func (w *complexityWalker) selectionSet(parent *graphql.Object, set *ast.SelectionSet, depth int) int {
+	if set == nil {
+		return 0
+	}
+	if depth > w.maxDepth {
+		w.maxDepth = depth
+	}
+	cost := 0
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			cost += w.field(parent, s, depth)
+		case *ast.InlineFragment:
+			cost += w.selectionSet(parent, s.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			w.visiting[name] = true
+			cost += w.selectionSet(parent, fragment.SelectionSet, depth)
+			w.visiting[name] = false
+		}
+	}
+	return cost
+}
+
+func (w *complexityWalker) field(parent *graphql.Object, f *ast.Field, depth int) int {
+	name := f.Name.Value
+	def, ok := parent.Fields()[name]
+	if !ok {
+		// introspection and unknown fields: validation handles the latter
+		return 1
+	}
+	multiplier := 1
+	fieldType := def.Type
+	for {
+		switch t := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = t.OfType
+			continue
+		case *graphql.List:
+			multiplier *= listMultiplier
+			fieldType = t.OfType
+			continue
+		}
+		break
+	}
+	object, ok := fieldType.(*graphql.Object)
+	if !ok {
+		return 1
+	}
+	return 1 + multiplier*w.selectionSet(object, f.SelectionSet, depth+1)
+}
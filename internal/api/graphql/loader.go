@@ -0,0 +1,91 @@
+package graphqlapi
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/product"
+	"sync"
+)
+
+type productKey struct {
+	id          string
+	countryCode string
+}
+
+// productLoader batches the product lookups of a single request. Load only
+// queues the key and returns a thunk; the executor resolves thunks breadth
+// first, so the first one evaluated fetches every key queued so far with a
+// single GetProductsByIDs call per country instead of one call per order line
+type productLoader struct {
+	products *product.Service
+
+	mu      sync.Mutex
+	pending map[string][]string
+	cache   map[productKey]*product.Detail
+	errs    map[string]error
+}
+
+func newProductLoader(products *product.Service) *productLoader {
+	return &productLoader{
+		products: products,
+		pending:  make(map[string][]string),
+		cache:    make(map[productKey]*product.Detail),
+		errs:     make(map[string]error),
+	}
+}
+
+func (l *productLoader) Load(ctx context.Context, id, countryCode string) func() (interface{}, error) {
+	key := productKey{id: id, countryCode: countryCode}
+	l.mu.Lock()
+	if _, ok := l.cache[key]; !ok {
+		l.pending[countryCode] = append(l.pending[countryCode], id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.cache[key]; !ok {
+			l.dispatch(ctx)
+		}
+		if err := l.errs[countryCode]; err != nil {
+			return nil, err
+		}
+		if detail := l.cache[key]; detail != nil {
+			return *detail, nil
+		}
+		return nil, nil
+	}
+}
+
+// dispatch fetches every pending key; it must be called with l.mu held
+func (l *productLoader) dispatch(ctx context.Context) {
+	for countryCode, ids := range l.pending {
+		details, err := l.products.GetProductsByIDs(ctx, ids, countryCode)
+		for _, id := range ids {
+			key := productKey{id: id, countryCode: countryCode}
+			l.cache[key] = nil
+			if detail, ok := details[id]; ok {
+				l.cache[key] = &detail
+			}
+		}
+		if err != nil {
+			l.errs[countryCode] = err
+		}
+	}
+	l.pending = make(map[string][]string)
+}
+
+type loadersKey struct{}
+
+type loaders struct {
+	products *productLoader
+}
+
+func withLoaders(ctx context.Context, products *product.Service) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{products: newProductLoader(products)})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
@@ -0,0 +1,205 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// ErrLimitExceeded is returned when a query exceeds the complexity or depth limits
+var ErrLimitExceeded = errors.New("query limits exceeded")
+
+// Request is a GraphQL over HTTP request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Schema exposes the catalog and the orders as a GraphQL schema
+type Schema struct {
+	schema   graphql.Schema
+	products *product.Service
+	orders   *order.Service
+	limits   Limits
+}
+
+func NewSchema(orders *order.Service, products *product.Service, limits Limits) (*Schema, error) {
+	s := &Schema{products: products, orders: orders, limits: limits}
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: productField(func(p product.Detail) interface{} { return p.ID })},
+			"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: productField(func(p product.Detail) interface{} { return p.Name })},
+			"description":  &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: productField(func(p product.Detail) interface{} { return p.Description })},
+			"price":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Net unit price", Resolve: productField(func(p product.Detail) interface{} { return p.Price })},
+			"vat":          &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "VAT rate applied for the requested country", Resolve: productField(func(p product.Detail) interface{} { return p.VAT })},
+			"priceWithVat": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: productField(func(p product.Detail) interface{} { return p.PriceWithVAT })},
+		},
+	})
+
+	orderLineType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderLine",
+		Fields: graphql.Fields{
+			"productId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: lineField(func(l order.ProductDetail) interface{} { return l.ID })},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: lineField(func(l order.ProductDetail) interface{} { return l.Name })},
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: lineField(func(l order.ProductDetail) interface{} { return l.Quantity })},
+			"unitPrice": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: lineField(func(l order.ProductDetail) interface{} { return l.Price })},
+			"vat":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: lineField(func(l order.ProductDetail) interface{} { return l.VAT })},
+			"product": &graphql.Field{
+				Type:        productType,
+				Description: "Current catalog entry of the product, priced for the given country",
+				Args: graphql.FieldConfigArgument{
+					"countryCode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					line, ok := p.Source.(order.ProductDetail)
+					if !ok {
+						return nil, nil
+					}
+					return loadersFrom(p.Context).products.Load(p.Context, line.ID, countryArg(p)), nil
+				},
+			},
+		},
+	})
+
+	orderType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: orderField(func(o *order.Detail) interface{} { return o.Id })},
+			"status":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: orderField(func(o *order.Detail) interface{} { return o.Status })},
+			"totalPrice": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: orderField(func(o *order.Detail) interface{} { return o.TotalPrice })},
+			"totalVat":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: orderField(func(o *order.Detail) interface{} { return o.TotalVAT })},
+			"lines": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderLineType))), Resolve: orderField(func(o *order.Detail) interface{} {
+				if o.Items == nil {
+					return []order.ProductDetail{}
+				}
+				return o.Items
+			})},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"countryCode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					return loadersFrom(p.Context).products.Load(p.Context, id, countryArg(p)), nil
+				},
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+				Args: graphql.FieldConfigArgument{
+					"countryCode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.products.GetAllProducts(p.Context, countryArg(p))
+				},
+			},
+			"order": &graphql.Field{
+				Type: orderType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					ord, err := s.orders.GetOrderByID(p.Context, id)
+					if err != nil || ord == nil {
+						return nil, err
+					}
+					return ord, nil
+				},
+			},
+			"orders": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					orders, err := s.orders.GetAllOrders(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					if orders == nil {
+						orders = []*order.Detail{}
+					}
+					return orders, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Execute validates the request against the query limits and runs it.
+// Requests rejected before execution are reported with ErrLimitExceeded or
+// as a result without data
+func (s *Schema) Execute(ctx context.Context, req Request) (*graphql.Result, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}, nil
+	}
+	if err := checkLimits(s.schema, doc, req.OperationName, s.limits); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}, ErrLimitExceeded
+	}
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, nil
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, s.products),
+	}), nil
+}
+
+func countryArg(p graphql.ResolveParams) string {
+	country, _ := p.Args["countryCode"].(string)
+	return strings.ToUpper(country)
+}
+
+func productField(get func(product.Detail) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if detail, ok := p.Source.(product.Detail); ok {
+			return get(detail), nil
+		}
+		return nil, nil
+	}
+}
+
+func lineField(get func(order.ProductDetail) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if line, ok := p.Source.(order.ProductDetail); ok {
+			return get(line), nil
+		}
+		return nil, nil
+	}
+}
+
+func orderField(get func(*order.Detail) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if detail, ok := p.Source.(*order.Detail); ok {
+			return get(detail), nil
+		}
+		return nil, nil
+	}
+}
@@ -0,0 +1,26 @@
+package grpcapi
+
+import (
+	"errors"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/vat"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps domain errors to gRPC status codes; unknown errors are internal
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, order.ErrInvalidItem):
+		return status.Error(codes.InvalidArgument, "invalid item in order")
+	case errors.Is(err, order.ErrInvalidVATRate), errors.Is(err, product.ErrInvalidVATRate):
+		return status.Error(codes.InvalidArgument, "invalid VAT rate for country")
+	case errors.Is(err, order.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, vat.ErrRateNotFound):
+		return status.Error(codes.NotFound, "VAT rate not found")
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,102 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"purchase-cart-service/internal/api/grpc/pb"
+	"purchase-cart-service/internal/domain/order"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type orderServer struct {
+	pb.UnimplementedOrderServiceServer
+	domain   *order.Service
+	maxItems int
+}
+
+func (s *orderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	if req.GetCountryCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "country code is required")
+	}
+	if len(req.GetItems()) > s.maxItems {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("too many items in order, maximum is %d", s.maxItems))
+	}
+	items := make([]order.CreateItem, 0, len(req.GetItems()))
+	for _, it := range req.GetItems() {
+		if it.GetQuantity() <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "item quantity must be greater than zero")
+		}
+		items = append(items, order.CreateItem{
+			ProductID: it.GetProductId(),
+			Quantity:  int(it.GetQuantity()),
+		})
+	}
+	ord, err := s.domain.CreateOrder(ctx, strings.ToUpper(req.GetCountryCode()), items)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.Order{
+		OrderId:    ord.ID,
+		Status:     ord.Status,
+		TotalPrice: ord.TotalPrice,
+		TotalVat:   ord.TotalVAT,
+	}
+	for _, it := range ord.Items {
+		resp.Items = append(resp.Items, &pb.OrderItem{
+			ProductId: it.ProductID,
+			Name:      it.Name,
+			Quantity:  int32(it.Quantity),
+			UnitPrice: it.UnitPrice,
+			Vat:       it.VAT,
+		})
+	}
+	return resp, nil
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid order ID")
+	}
+	ord, err := s.domain.GetOrderByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if ord == nil {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return orderDetailToPB(ord), nil
+}
+
+func (s *orderServer) ListOrders(ctx context.Context, _ *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	orders, err := s.domain.GetAllOrders(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListOrdersResponse{}
+	for _, ord := range orders {
+		resp.Orders = append(resp.Orders, orderDetailToPB(ord))
+	}
+	return resp, nil
+}
+
+func orderDetailToPB(ord *order.Detail) *pb.Order {
+	resp := &pb.Order{
+		OrderId:    ord.Id,
+		Status:     ord.Status,
+		TotalPrice: ord.TotalPrice,
+		TotalVat:   ord.TotalVAT,
+	}
+	for _, it := range ord.Items {
+		resp.Items = append(resp.Items, &pb.OrderItem{
+			ProductId: it.ID,
+			Name:      it.Name,
+			Quantity:  int32(it.Quantity),
+			UnitPrice: it.Price,
+			Vat:       it.VAT,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,954 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: purchasecart/v1/purchase_cart.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryCode   string                 `protobuf:"bytes,1,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	Items         []*CreateOrderItem     `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateOrderRequest) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetItems() []*CreateOrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CreateOrderItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderItem) Reset() {
+	*x = CreateOrderItem{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderItem) ProtoMessage() {}
+
+func (x *CreateOrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderItem.ProtoReflect.Descriptor instead.
+func (*CreateOrderItem) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateOrderItem) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *CreateOrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetOrderRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{3}
+}
+
+type ListOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type Order struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	OrderId    string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	TotalPrice float64                `protobuf:"fixed64,2,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	TotalVat   float64                `protobuf:"fixed64,3,opt,name=total_vat,json=totalVat,proto3" json:"total_vat,omitempty"`
+	Items      []*OrderItem           `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+	// status is one of created, cancelled, shipped
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Order) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Order) GetTotalPrice() float64 {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return 0
+}
+
+func (x *Order) GetTotalVat() float64 {
+	if x != nil {
+		return x.TotalVat
+	}
+	return 0
+}
+
+func (x *Order) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type OrderItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     float64                `protobuf:"fixed64,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Vat           float64                `protobuf:"fixed64,5,opt,name=vat,proto3" json:"vat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItem) Reset() {
+	*x = OrderItem{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItem) ProtoMessage() {}
+
+func (x *OrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OrderItem) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *OrderItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderItem) GetUnitPrice() float64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *OrderItem) GetVat() float64 {
+	if x != nil {
+		return x.Vat
+	}
+	return 0
+}
+
+type GetProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// country_code selects the VAT rate applied to the price
+	CountryCode   string `protobuf:"bytes,2,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetProductRequest) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+type ListProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryCode   string                 `protobuf:"bytes,1,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListProductsRequest) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+type ListProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	Vat           float64                `protobuf:"fixed64,5,opt,name=vat,proto3" json:"vat,omitempty"`
+	PriceWithVat  float64                `protobuf:"fixed64,6,opt,name=price_with_vat,json=priceWithVat,proto3" json:"price_with_vat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Product) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Product) GetVat() float64 {
+	if x != nil {
+		return x.Vat
+	}
+	return 0
+}
+
+func (x *Product) GetPriceWithVat() float64 {
+	if x != nil {
+		return x.PriceWithVat
+	}
+	return 0
+}
+
+type GetVatRateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryCode   string                 `protobuf:"bytes,1,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVatRateRequest) Reset() {
+	*x = GetVatRateRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVatRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVatRateRequest) ProtoMessage() {}
+
+func (x *GetVatRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVatRateRequest.ProtoReflect.Descriptor instead.
+func (*GetVatRateRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetVatRateRequest) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+type ListVatRatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVatRatesRequest) Reset() {
+	*x = ListVatRatesRequest{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVatRatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVatRatesRequest) ProtoMessage() {}
+
+func (x *ListVatRatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVatRatesRequest.ProtoReflect.Descriptor instead.
+func (*ListVatRatesRequest) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{12}
+}
+
+type ListVatRatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rates         []*VatRate             `protobuf:"bytes,1,rep,name=rates,proto3" json:"rates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVatRatesResponse) Reset() {
+	*x = ListVatRatesResponse{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVatRatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVatRatesResponse) ProtoMessage() {}
+
+func (x *ListVatRatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVatRatesResponse.ProtoReflect.Descriptor instead.
+func (*ListVatRatesResponse) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListVatRatesResponse) GetRates() []*VatRate {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+type VatRate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryCode   string                 `protobuf:"bytes,1,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	Rate          float64                `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VatRate) Reset() {
+	*x = VatRate{}
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VatRate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VatRate) ProtoMessage() {}
+
+func (x *VatRate) ProtoReflect() protoreflect.Message {
+	mi := &file_purchasecart_v1_purchase_cart_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VatRate.ProtoReflect.Descriptor instead.
+func (*VatRate) Descriptor() ([]byte, []int) {
+	return file_purchasecart_v1_purchase_cart_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VatRate) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *VatRate) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+var File_purchasecart_v1_purchase_cart_proto protoreflect.FileDescriptor
+
+const file_purchasecart_v1_purchase_cart_proto_rawDesc = "" +
+	"\n" +
+	"#purchasecart/v1/purchase_cart.proto\x12\x0fpurchasecart.v1\"o\n" +
+	"\x12CreateOrderRequest\x12!\n" +
+	"\fcountry_code\x18\x01 \x01(\tR\vcountryCode\x126\n" +
+	"\x05items\x18\x02 \x03(\v2 .purchasecart.v1.CreateOrderItemR\x05items\"L\n" +
+	"\x0fCreateOrderItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"!\n" +
+	"\x0fGetOrderRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x13\n" +
+	"\x11ListOrdersRequest\"D\n" +
+	"\x12ListOrdersResponse\x12.\n" +
+	"\x06orders\x18\x01 \x03(\v2\x16.purchasecart.v1.OrderR\x06orders\"\xaa\x01\n" +
+	"\x05Order\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1f\n" +
+	"\vtotal_price\x18\x02 \x01(\x01R\n" +
+	"totalPrice\x12\x1b\n" +
+	"\ttotal_vat\x18\x03 \x01(\x01R\btotalVat\x120\n" +
+	"\x05items\x18\x04 \x03(\v2\x1a.purchasecart.v1.OrderItemR\x05items\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\"\x8b\x01\n" +
+	"\tOrderItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x04 \x01(\x01R\tunitPrice\x12\x10\n" +
+	"\x03vat\x18\x05 \x01(\x01R\x03vat\"F\n" +
+	"\x11GetProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fcountry_code\x18\x02 \x01(\tR\vcountryCode\"8\n" +
+	"\x13ListProductsRequest\x12!\n" +
+	"\fcountry_code\x18\x01 \x01(\tR\vcountryCode\"L\n" +
+	"\x14ListProductsResponse\x124\n" +
+	"\bproducts\x18\x01 \x03(\v2\x18.purchasecart.v1.ProductR\bproducts\"\x9d\x01\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x10\n" +
+	"\x03vat\x18\x05 \x01(\x01R\x03vat\x12$\n" +
+	"\x0eprice_with_vat\x18\x06 \x01(\x01R\fpriceWithVat\"6\n" +
+	"\x11GetVatRateRequest\x12!\n" +
+	"\fcountry_code\x18\x01 \x01(\tR\vcountryCode\"\x15\n" +
+	"\x13ListVatRatesRequest\"F\n" +
+	"\x14ListVatRatesResponse\x12.\n" +
+	"\x05rates\x18\x01 \x03(\v2\x18.purchasecart.v1.VatRateR\x05rates\"@\n" +
+	"\aVatRate\x12!\n" +
+	"\fcountry_code\x18\x01 \x01(\tR\vcountryCode\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x01R\x04rate2\xf7\x01\n" +
+	"\fOrderService\x12J\n" +
+	"\vCreateOrder\x12#.purchasecart.v1.CreateOrderRequest\x1a\x16.purchasecart.v1.Order\x12D\n" +
+	"\bGetOrder\x12 .purchasecart.v1.GetOrderRequest\x1a\x16.purchasecart.v1.Order\x12U\n" +
+	"\n" +
+	"ListOrders\x12\".purchasecart.v1.ListOrdersRequest\x1a#.purchasecart.v1.ListOrdersResponse2\xb9\x01\n" +
+	"\x0eProductService\x12J\n" +
+	"\n" +
+	"GetProduct\x12\".purchasecart.v1.GetProductRequest\x1a\x18.purchasecart.v1.Product\x12[\n" +
+	"\fListProducts\x12$.purchasecart.v1.ListProductsRequest\x1a%.purchasecart.v1.ListProductsResponse2\xb5\x01\n" +
+	"\n" +
+	"VatService\x12J\n" +
+	"\n" +
+	"GetVatRate\x12\".purchasecart.v1.GetVatRateRequest\x1a\x18.purchasecart.v1.VatRate\x12[\n" +
+	"\fListVatRates\x12$.purchasecart.v1.ListVatRatesRequest\x1a%.purchasecart.v1.ListVatRatesResponseB/Z-purchase-cart-service/internal/api/grpc/pb;pbb\x06proto3"
+
+var (
+	file_purchasecart_v1_purchase_cart_proto_rawDescOnce sync.Once
+	file_purchasecart_v1_purchase_cart_proto_rawDescData []byte
+)
+
+func file_purchasecart_v1_purchase_cart_proto_rawDescGZIP() []byte {
+	file_purchasecart_v1_purchase_cart_proto_rawDescOnce.Do(func() {
+		file_purchasecart_v1_purchase_cart_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_purchasecart_v1_purchase_cart_proto_rawDesc), len(file_purchasecart_v1_purchase_cart_proto_rawDesc)))
+	})
+	return file_purchasecart_v1_purchase_cart_proto_rawDescData
+}
+
+var file_purchasecart_v1_purchase_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_purchasecart_v1_purchase_cart_proto_goTypes = []any{
+	(*CreateOrderRequest)(nil),   // 0: purchasecart.v1.CreateOrderRequest
+	(*CreateOrderItem)(nil),      // 1: purchasecart.v1.CreateOrderItem
+	(*GetOrderRequest)(nil),      // 2: purchasecart.v1.GetOrderRequest
+	(*ListOrdersRequest)(nil),    // 3: purchasecart.v1.ListOrdersRequest
+	(*ListOrdersResponse)(nil),   // 4: purchasecart.v1.ListOrdersResponse
+	(*Order)(nil),                // 5: purchasecart.v1.Order
+	(*OrderItem)(nil),            // 6: purchasecart.v1.OrderItem
+	(*GetProductRequest)(nil),    // 7: purchasecart.v1.GetProductRequest
+	(*ListProductsRequest)(nil),  // 8: purchasecart.v1.ListProductsRequest
+	(*ListProductsResponse)(nil), // 9: purchasecart.v1.ListProductsResponse
+	(*Product)(nil),              // 10: purchasecart.v1.Product
+	(*GetVatRateRequest)(nil),    // 11: purchasecart.v1.GetVatRateRequest
+	(*ListVatRatesRequest)(nil),  // 12: purchasecart.v1.ListVatRatesRequest
+	(*ListVatRatesResponse)(nil), // 13: purchasecart.v1.ListVatRatesResponse
+	(*VatRate)(nil),              // 14: purchasecart.v1.VatRate
+}
+var file_purchasecart_v1_purchase_cart_proto_depIdxs = []int32{
+	1,  // 0: purchasecart.v1.CreateOrderRequest.items:type_name -> purchasecart.v1.CreateOrderItem
+	5,  // 1: purchasecart.v1.ListOrdersResponse.orders:type_name -> purchasecart.v1.Order
+	6,  // 2: purchasecart.v1.Order.items:type_name -> purchasecart.v1.OrderItem
+	10, // 3: purchasecart.v1.ListProductsResponse.products:type_name -> purchasecart.v1.Product
+	14, // 4: purchasecart.v1.ListVatRatesResponse.rates:type_name -> purchasecart.v1.VatRate
+	0,  // 5: purchasecart.v1.OrderService.CreateOrder:input_type -> purchasecart.v1.CreateOrderRequest
+	2,  // 6: purchasecart.v1.OrderService.GetOrder:input_type -> purchasecart.v1.GetOrderRequest
+	3,  // 7: purchasecart.v1.OrderService.ListOrders:input_type -> purchasecart.v1.ListOrdersRequest
+	7,  // 8: purchasecart.v1.ProductService.GetProduct:input_type -> purchasecart.v1.GetProductRequest
+	8,  // 9: purchasecart.v1.ProductService.ListProducts:input_type -> purchasecart.v1.ListProductsRequest
+	11, // 10: purchasecart.v1.VatService.GetVatRate:input_type -> purchasecart.v1.GetVatRateRequest
+	12, // 11: purchasecart.v1.VatService.ListVatRates:input_type -> purchasecart.v1.ListVatRatesRequest
+	5,  // 12: purchasecart.v1.OrderService.CreateOrder:output_type -> purchasecart.v1.Order
+	5,  // 13: purchasecart.v1.OrderService.GetOrder:output_type -> purchasecart.v1.Order
+	4,  // 14: purchasecart.v1.OrderService.ListOrders:output_type -> purchasecart.v1.ListOrdersResponse
+	10, // 15: purchasecart.v1.ProductService.GetProduct:output_type -> purchasecart.v1.Product
+	9,  // 16: purchasecart.v1.ProductService.ListProducts:output_type -> purchasecart.v1.ListProductsResponse
+	14, // 17: purchasecart.v1.VatService.GetVatRate:output_type -> purchasecart.v1.VatRate
+	13, // 18: purchasecart.v1.VatService.ListVatRates:output_type -> purchasecart.v1.ListVatRatesResponse
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_purchasecart_v1_purchase_cart_proto_init() }
+func file_purchasecart_v1_purchase_cart_proto_init() {
+	if File_purchasecart_v1_purchase_cart_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_purchasecart_v1_purchase_cart_proto_rawDesc), len(file_purchasecart_v1_purchase_cart_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_purchasecart_v1_purchase_cart_proto_goTypes,
+		DependencyIndexes: file_purchasecart_v1_purchase_cart_proto_depIdxs,
+		MessageInfos:      file_purchasecart_v1_purchase_cart_proto_msgTypes,
+	}.Build()
+	File_purchasecart_v1_purchase_cart_proto = out.File
+	file_purchasecart_v1_purchase_cart_proto_goTypes = nil
+	file_purchasecart_v1_purchase_cart_proto_depIdxs = nil
+}
@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"context"
+	"purchase-cart-service/internal/api/grpc/pb"
+	"purchase-cart-service/internal/domain/product"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type productServer struct {
+	pb.UnimplementedProductServiceServer
+	domain *product.Service
+}
+
+func (s *productServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	p, err := s.domain.GetProductByID(ctx, req.GetId(), strings.ToUpper(req.GetCountryCode()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if p == nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return productToPB(*p), nil
+}
+
+func (s *productServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	products, err := s.domain.GetAllProducts(ctx, strings.ToUpper(req.GetCountryCode()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListProductsResponse{}
+	for _, p := range products {
+		resp.Products = append(resp.Products, productToPB(p))
+	}
+	return resp, nil
+}
+
+func productToPB(p product.Detail) *pb.Product {
+	return &pb.Product{
+		Id:           p.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		Price:        p.Price,
+		Vat:          p.VAT,
+		PriceWithVat: p.PriceWithVAT,
+	}
+}
@@ -0,0 +1,62 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"purchase-cart-service/internal/api/grpc/pb"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/vat"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server exposes the order, product and VAT use cases over gRPC, together
+// with the standard health and reflection services
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// NewServer registers the services; maxItems bounds the items of a single order
+func NewServer(orders *order.Service, products *product.Service, rates *vat.Service, maxItems int, opts ...grpc.ServerOption) *Server {
+	s := &Server{
+		grpc:   grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+	pb.RegisterOrderServiceServer(s.grpc, &orderServer{domain: orders, maxItems: maxItems})
+	pb.RegisterProductServiceServer(s.grpc, &productServer{domain: products})
+	pb.RegisterVatServiceServer(s.grpc, &vatServer{domain: rates})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	for name := range s.grpc.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return s
+}
+
+// Serve accepts gRPC connections on ln until the server is stopped
+func (s *Server) Serve(ln net.Listener) error {
+	return s.grpc.Serve(ln)
+}
+
+// Shutdown reports NOT_SERVING to health clients and waits for in-flight
+// RPCs to complete; when ctx expires the remaining ones are cancelled
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
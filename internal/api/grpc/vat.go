@@ -0,0 +1,33 @@
+package grpcapi
+
+import (
+	"context"
+	"purchase-cart-service/internal/api/grpc/pb"
+	"purchase-cart-service/internal/domain/vat"
+	"strings"
+)
+
+type vatServer struct {
+	pb.UnimplementedVatServiceServer
+	domain *vat.Service
+}
+
+func (s *vatServer) GetVatRate(ctx context.Context, req *pb.GetVatRateRequest) (*pb.VatRate, error) {
+	rate, err := s.domain.GetRate(ctx, strings.ToUpper(req.GetCountryCode()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.VatRate{CountryCode: rate.CountryCode, Rate: rate.Rate}, nil
+}
+
+func (s *vatServer) ListVatRates(ctx context.Context, _ *pb.ListVatRatesRequest) (*pb.ListVatRatesResponse, error) {
+	rates, err := s.domain.GetAllRates(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListVatRatesResponse{}
+	for _, r := range rates {
+		resp.Rates = append(resp.Rates, &pb.VatRate{CountryCode: r.CountryCode, Rate: r.Rate})
+	}
+	return resp, nil
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/models"
+)
+
+// BundleHandler exposes the bundles of the catalog
+type BundleHandler struct {
+	domain *bundle.Service
+}
+
+func NewBundleHandler(domain *bundle.Service) *BundleHandler {
+	return &BundleHandler{domain: domain}
+}
+
+// BundleRequest è un kit di prodotti venduto come una sola riga a un prezzo proprio, nella modalità price_mode
+// (net o gross, di default quella del catalogo)
+type BundleRequest struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Price       float64                  `json:"price"`
+	PriceMode   string                   `json:"price_mode,omitempty"`
+	Components  []BundleComponentRequest `json:"components"`
+}
+
+// BundleComponentRequest è un prodotto del kit, per SKU se il prodotto ha varianti
+type BundleComponentRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// BundleResponse è un kit del catalogo
+type BundleResponse struct {
+	ID string `json:"id"`
+	BundleRequest
+}
+
+func (h *BundleHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "GET",
+			Route:   "/bundles",
+			Handler: h.GetBundles,
+		},
+		{
+			Method:  "GET",
+			Route:   "/bundles/:id",
+			Handler: h.GetBundle,
+		},
+	}
+}
+
+// GetBundles
+// @Summary Elenco dei kit
+// @Description Restituisce i kit del catalogo con i loro componenti, per ID; il preventivo di un ordine (POST /orders/quote) con bundle_id mostra l'IVA di ogni componente
+// @Tags Bundles
+// @Produce json
+// @Success 200 {array} handlers.BundleResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/bundles [get]
+func (h *BundleHandler) GetBundles(c *gin.Context) {
+	bundles, err := h.domain.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to retrieve bundles"})
+		return
+	}
+	response := []BundleResponse{}
+	for _, b := range bundles {
+		response = append(response, toBundleResponse(b))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBundle
+// @Summary Dettaglio di un kit
+// @Description Restituisce un kit con i suoi componenti
+// @Tags Bundles
+// @Produce json
+// @Param id path string true "ID del kit"
+// @Success 200 {object} handlers.BundleResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/bundles/{id} [get]
+func (h *BundleHandler) GetBundle(c *gin.Context) {
+	b, err := h.domain.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to retrieve bundle"})
+		return
+	}
+	if b == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Bundle not found"})
+		return
+	}
+	c.JSON(http.StatusOK, toBundleResponse(*b))
+}
+
+// BundleAdminHandler manages the bundles in the admin API
+type BundleAdminHandler struct {
+	domain *bundle.Service
+}
+
+func NewBundleAdminHandler(domain *bundle.Service) *BundleAdminHandler {
+	return &BundleAdminHandler{domain: domain}
+}
+
+func (h *BundleAdminHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "PUT",
+			Route:   "/bundles/:id",
+			Handler: h.SaveBundle,
+		},
+	}
+}
+
+// SaveBundle
+// @Summary Crea o sostituisce un kit
+// @Description Salva un kit con il suo prezzo e i prodotti o SKU che lo compongono
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID del kit"
+// @Param bundle body handlers.BundleRequest true "Kit"
+// @Success 200 {object} handlers.BundleResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/bundles/{id} [put]
+func (h *BundleAdminHandler) SaveBundle(c *gin.Context) {
+	var req BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	b := models.Bundle{ID: c.Param("id"), Name: req.Name, Description: req.Description, Price: req.Price, PriceMode: req.PriceMode}
+	for _, component := range req.Components {
+		b.Components = append(b.Components, models.BundleComponent{ProductID: component.ProductID, Quantity: component.Quantity})
+	}
+	if err := h.domain.Save(c.Request.Context(), &b); err != nil {
+		if errors.Is(err, bundle.ErrInvalidBundle) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to save bundle"})
+		return
+	}
+	c.JSON(http.StatusOK, toBundleResponse(b))
+}
+
+func toBundleResponse(b models.Bundle) BundleResponse {
+	response := BundleResponse{ID: b.ID, BundleRequest: BundleRequest{
+		Name:        b.Name,
+		Description: b.Description,
+		Price:       b.Price,
+		PriceMode:   b.PriceMode,
+		Components:  []BundleComponentRequest{},
+	}}
+	for _, component := range b.Components {
+		response.Components = append(response.Components, BundleComponentRequest{ProductID: component.ProductID, Quantity: component.Quantity})
+	}
+	return response
+}
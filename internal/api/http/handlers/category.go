@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/product"
+	"strings"
+)
+
+type CategoryHandler struct {
+	domain   *category.Service
+	products *product.Service
+}
+
+func NewCategoryHandler(domain *category.Service, products *product.Service) *CategoryHandler {
+	return &CategoryHandler{domain: domain, products: products}
+}
+
+// CategoryResponse rappresenta una categoria del catalogo con le sue sottocategorie
+type CategoryResponse struct {
+	ID       string `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	// TaxClass è la classe fiscale predefinita dei prodotti della categoria; vuota eredita quella della categoria padre
+	TaxClass string             `json:"tax_class,omitempty"`
+	Children []CategoryResponse `json:"children,omitempty"`
+}
+
+func (h *CategoryHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "GET",
+			Route:   "/categories",
+			Handler: h.GetCategories,
+		},
+		{
+			Method:  "GET",
+			Route:   "/categories/:slug/products",
+			Handler: h.GetCategoryProducts,
+		},
+	}
+}
+
+// GetCategories
+// @Summary Albero delle categorie
+// @Description Restituisce le categorie radice con le sottocategorie, ordinate per posizione
+// @Tags Categories
+// @Produce json
+// @Success 200 {array} handlers.CategoryResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	tree, err := h.domain.Tree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to retrieve categories"})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponses(tree))
+}
+
+// GetCategoryProducts
+// @Summary Prodotti di una categoria
+// @Description Restituisce i prodotti della categoria e delle sue sottocategorie
+// @Tags Categories
+// @Produce json
+// @Param slug path string true "Slug della categoria"
+// @Param country_code query string false "Country Code for VAT calculation"
+// @Success 200 {array} handlers.ProductResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/categories/{slug}/products [get]
+func (h *CategoryHandler) GetCategoryProducts(c *gin.Context) {
+	products, err := h.products.GetProductsByCategory(c.Request.Context(), c.Param("slug"), strings.ToUpper(c.Query("country_code")))
+	if err != nil {
+		if err == category.ErrCategoryNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Category not found"})
+			return
+		}
+		if err == product.ErrInvalidVATRate {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid VAT rate for country"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to retrieve products"})
+		return
+	}
+	response := []ProductResponse{}
+	for _, p := range products {
+		response = append(response, toProductResponse(p))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func toCategoryResponses(nodes []*category.Node) []CategoryResponse {
+	response := []CategoryResponse{}
+	for _, n := range nodes {
+		item := CategoryResponse{ID: n.ID, Slug: n.Slug, Name: n.Name, Position: n.Position, TaxClass: n.TaxClass}
+		if len(n.Children) > 0 {
+			item.Children = toCategoryResponses(n.Children)
+		}
+		response = append(response, item)
+	}
+	return response
+}
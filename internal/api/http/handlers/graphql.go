@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	graphqlapi "purchase-cart-service/internal/api/graphql"
+	httpapi "purchase-cart-service/internal/api/http"
+)
+
+type GraphQLHandler struct {
+	schema *graphqlapi.Schema
+}
+
+func NewGraphQLHandler(schema *graphqlapi.Schema) *GraphQLHandler {
+	return &GraphQLHandler{schema: schema}
+}
+
+func (h *GraphQLHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "POST",
+			Route:   "/graphql",
+			Handler: h.Query,
+		},
+		{
+			Method:  "GET",
+			Route:   "/graphql",
+			Handler: h.Query,
+		},
+	}
+}
+
+// Query GraphQL endpoint
+// @Summary GraphQL endpoint
+// @Description Esegue una query GraphQL su catalogo prodotti e ordini (POST con body JSON oppure GET con parametri query, operationName, variables)
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body graphqlapi.Request false "Richiesta GraphQL"
+// @Success 200 {object} object
+// @Failure 400 {object} object
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphqlapi.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid variables"})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Message: "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Query is required"})
+		return
+	}
+
+	result, err := h.schema.Execute(c.Request.Context(), req)
+	if err != nil || result.Data == nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
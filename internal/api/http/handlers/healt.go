@@ -2,14 +2,22 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"net/http"
 	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/health"
 )
 
 type HealthCheckHandler struct {
+	checker *health.Checker
 }
 
-func NewHealthCheckHandler() *HealthCheckHandler {
-	return &HealthCheckHandler{}
+// LivenessResponse rappresenta la risposta della liveness probe
+type LivenessResponse struct {
+	Status string `json:"status"`
+}
+
+func NewHealthCheckHandler(checker *health.Checker) *HealthCheckHandler {
+	return &HealthCheckHandler{checker: checker}
 }
 
 func (h *HealthCheckHandler) GetHandlers() []httpapi.HandlersMethods {
@@ -19,15 +27,54 @@ func (h *HealthCheckHandler) GetHandlers() []httpapi.HandlersMethods {
 			Route:   "/health",
 			Handler: h.Healthcheck,
 		},
+		{
+			Method:  "GET",
+			Route:   "/livez",
+			Handler: h.Livez,
+		},
+		{
+			Method:  "GET",
+			Route:   "/readyz",
+			Handler: h.Readyz,
+		},
 	}
 }
 
 // Health check
 // @Summary Health check
+// @Description Alias della liveness probe, mantenuto per compatibilità
 // @Tags health
 // @Produce json
 // @Success 200 {string} string "ok"
 // @Router /health [get]
 func (h *HealthCheckHandler) Healthcheck(c *gin.Context) {
-	c.String(200, "ok")
+	c.String(http.StatusOK, "ok")
+}
+
+// Livez liveness probe
+// @Summary Liveness probe
+// @Description Indica che il processo è attivo e in grado di servire richieste
+// @Tags health
+// @Produce json
+// @Success 200 {object} handlers.LivenessResponse
+// @Router /livez [get]
+func (h *HealthCheckHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{Status: health.StatusOK})
+}
+
+// Readyz readiness probe
+// @Summary Readiness probe
+// @Description Esegue i controlli sulle dipendenze (repository, tabella IVA, catalogo) e restituisce il dettaglio
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthCheckHandler) Readyz(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
 }
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/invoice"
+	"purchase-cart-service/models"
+	"time"
+)
+
+type InvoiceHandler struct {
+	domain *invoice.Service
+}
+
+// InvoiceResponse rappresenta la fattura di un ordine pagato
+type InvoiceResponse struct {
+	Number        string            `json:"number"`
+	OrderID       string            `json:"order_id"`
+	CountryCode   string            `json:"country_code"`
+	FiscalYear    int               `json:"fiscal_year"`
+	Currency      string            `json:"currency"`
+	BuyerVATID    string            `json:"buyer_vat_id,omitempty"`
+	ReverseCharge bool              `json:"reverse_charge"`
+	Lines         []InvoiceLineItem `json:"lines"`
+	TotalNet      float64           `json:"total_net"`
+	TotalVAT      float64           `json:"total_vat"`
+	Total         float64           `json:"total"`
+	IssuedAt      time.Time         `json:"issued_at"`
+}
+
+// InvoiceLineItem è una riga d'ordine fatturata
+type InvoiceLineItem struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	VATRate   float64 `json:"vat_rate"`
+	Net       float64 `json:"net"`
+	VAT       float64 `json:"vat"`
+	Total     float64 `json:"total"`
+}
+
+func NewInvoiceHandler(domain *invoice.Service) *InvoiceHandler {
+	return &InvoiceHandler{domain: domain}
+}
+
+func (h *InvoiceHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/invoice",
+			Handler: h.GetInvoice,
+		},
+	}
+}
+
+// MIMEPDF is the media type of the PDF invoices
+const MIMEPDF = "application/pdf"
+
+// GetInvoice
+// @Summary Ottieni la fattura di un ordine
+// @Description La fattura è emessa quando l'ordine viene pagato, con un numero progressivo per paese e anno fiscale. L'header Accept sceglie il formato: JSON (predefinito), HTML o PDF
+// @Tags Invoices
+// @Produce json
+// @Produce html
+// @Produce application/pdf
+// @Param id path string true "ID Ordine"
+// @Success 200 {object} handlers.InvoiceResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 406 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/invoice [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	format := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML, MIMEPDF)
+	if format == "" {
+		c.JSON(http.StatusNotAcceptable, ErrorResponse{Message: "Invoices are available as application/json, text/html or application/pdf"})
+		return
+	}
+	doc, err := h.domain.GetDocument(c.Request.Context(), c.Param("id"))
+	if err == invoice.ErrInvoiceNotFound {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Invoice not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.Header("Vary", "Accept")
+	var buf bytes.Buffer
+	var contentType string
+	switch format {
+	case gin.MIMEHTML:
+		contentType = "text/html; charset=utf-8"
+		err = invoice.RenderHTML(&buf, *doc)
+	case MIMEPDF:
+		contentType = MIMEPDF
+		err = invoice.RenderPDF(&buf, *doc)
+	default:
+		c.JSON(http.StatusOK, toInvoiceResponse(&doc.Invoice))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	if format == MIMEPDF {
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="invoice-%s.pdf"`, doc.Number))
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func toInvoiceResponse(inv *models.Invoice) InvoiceResponse {
+	resp := InvoiceResponse{
+		Number:        inv.Number,
+		OrderID:       inv.OrderID,
+		CountryCode:   inv.CountryCode,
+		FiscalYear:    inv.FiscalYear,
+		Currency:      inv.Currency,
+		BuyerVATID:    inv.BuyerVATID,
+		ReverseCharge: inv.ReverseCharge,
+		Lines:         make([]InvoiceLineItem, 0, len(inv.Lines)),
+		TotalNet:      inv.TotalNet,
+		TotalVAT:      inv.TotalVAT,
+		Total:         inv.Total,
+		IssuedAt:      inv.IssuedAt,
+	}
+	for _, line := range inv.Lines {
+		resp.Lines = append(resp.Lines, InvoiceLineItem{
+			ProductID: line.ProductID,
+			Name:      line.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+			VATRate:   line.VATRate,
+			Net:       line.Net,
+			VAT:       line.VAT,
+			Total:     line.Total,
+		})
+	}
+	return resp
+}
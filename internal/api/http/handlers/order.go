@@ -1,39 +1,138 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/bundle"
 	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/utils"
 	"strings"
+	"time"
 )
 
+// DefaultMaxOrderItems is the maximum number of items accepted in a single order
+const DefaultMaxOrderItems = 100
+
 type OrderHandler struct {
-	domain *order.Service
+	domain   *order.Service
+	maxItems int
+}
+
+// OrderHandlerOption customizes an OrderHandler
+type OrderHandlerOption func(*OrderHandler)
+
+// WithMaxOrderItems sets the maximum number of items accepted in a single order
+func WithMaxOrderItems(n int) OrderHandlerOption {
+	return func(h *OrderHandler) {
+		if n > 0 {
+			h.maxItems = n
+		}
+	}
 }
 
 type OrderRequest struct {
 	Items []struct {
 		ProductID string `json:"product_id"`
-		Quantity  int    `json:"quantity"`
+		// SKU è la variante ordinata, obbligatoria per i prodotti con varianti; può sostituire product_id
+		SKU string `json:"sku,omitempty"`
+		// BundleID ordina un kit, al posto di product_id e sku: la riga si espande nei componenti del kit
+		BundleID string `json:"bundle_id,omitempty"`
+		Quantity int    `json:"quantity"`
 	} `json:"items"`
 	CountryCode string `json:"country_code"`
+	// BillingAddress è l'indirizzo del cliente riportato in fattura
+	BillingAddress *AddressRequest `json:"billing_address,omitempty"`
+	// ShippingAddress è l'indirizzo di consegna; per gli Stati Uniti region (stato) e postal_code (ZIP) determinano la sales tax
+	ShippingAddress *AddressRequest `json:"shipping_address,omitempty"`
+	// BuyerVATID è la partita IVA di un cliente business; nelle vendite intracomunitarie applica il reverse charge
+	BuyerVATID string `json:"buyer_vat_id,omitempty"`
+}
+
+// AddressRequest rappresenta un indirizzo postale
+type AddressRequest struct {
+	Name        string `json:"name"`
+	Line1       string `json:"line1"`
+	Line2       string `json:"line2,omitempty"`
+	City        string `json:"city"`
+	PostalCode  string `json:"postal_code"`
+	Region      string `json:"region,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
 }
 
 // OrderResponse rappresenta la risposta dopo la creazione di un ordine
 type OrderResponse struct {
-	OrderID    string           `json:"order_id"`
-	TotalPrice float64          `json:"total_price"`
-	TotalVAT   float64          `json:"total_vat"`
-	Items      []orderItemReply `json:"items"`
+	OrderID    string  `json:"order_id"`
+	Status     string  `json:"status"`
+	TotalPrice float64 `json:"total_price"`
+	TotalVAT   float64 `json:"total_vat"`
+	// TotalNet è il totale al netto dell'IVA, total_price - total_vat
+	TotalNet float64          `json:"total_net"`
+	Items    []orderItemReply `json:"items"`
+	// BuyerVATID è la partita IVA normalizzata del cliente business
+	BuyerVATID string `json:"buyer_vat_id,omitempty"`
+	// ReverseCharge indica che l'IVA non è addebitata ed è assolta dal cliente
+	ReverseCharge bool `json:"reverse_charge"`
+	// TaxTerritory è il territorio con regole IVA proprie dell'indirizzo di consegna (es. Canary Islands)
+	TaxTerritory string `json:"tax_territory,omitempty"`
+	// Bundles raggruppa le righe dei componenti dei kit ordinati
+	Bundles []OrderBundleReply `json:"bundles,omitempty"`
+}
+
+// OrderBundleReply è un kit ordinato: le righe in items con lo stesso bundle_line sono i suoi componenti
+type OrderBundleReply struct {
+	// Line è la posizione del kit tra le righe della richiesta, da 1
+	Line     int    `json:"line"`
+	BundleID string `json:"bundle_id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	// TotalPrice è il totale IVA inclusa delle righe dei componenti
+	TotalPrice float64 `json:"total_price"`
 }
 
 type orderItemReply struct {
-	ProductID string  `json:"product_id"`
+	// Line è la posizione della riga nell'ordine, da 1, con cui la si rimborsa
+	Line      int    `json:"line"`
+	ProductID string `json:"product_id"`
+	// SKU è la variante ordinata
+	SKU       string  `json:"sku,omitempty"`
 	Name      string  `json:"name"`
 	Quantity  int     `json:"quantity"`
 	UnitPrice float64 `json:"unit_price"`
+	// VATRate è l'aliquota della riga, secondo la classe fiscale (tax_class) del prodotto
+	VATRate  float64 `json:"vat_rate"`
+	TaxClass string  `json:"tax_class,omitempty"`
+	// UnitPriceWithVAT è il prezzo unitario IVA inclusa
+	UnitPriceWithVAT float64 `json:"unit_price_with_vat"`
+	// PriceMode dice se il prezzo di listino era netto (net) o IVA inclusa (gross)
+	PriceMode string `json:"price_mode,omitempty"`
+	// PriceList è il listino da cui viene il prezzo della riga, assente per il prezzo di catalogo
+	PriceList string  `json:"price_list,omitempty"`
 	VAT       float64 `json:"vat"`
+	// Taxes ripartisce l'imposta della riga tra le giurisdizioni (paese, stato, contea, città, distretto)
+	Taxes []LineTaxReply `json:"taxes,omitempty"`
+	// BundleLine è la riga del kit di cui la riga è un componente, con il prezzo unitario pari alla sua quota del prezzo del kit
+	BundleLine int `json:"bundle_line,omitempty"`
+}
+
+// LineTaxReply è la quota dell'imposta di una riga dovuta a una giurisdizione
+type LineTaxReply struct {
+	Type   string  `json:"type"`
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// OrderHistoryEntry rappresenta un evento immutabile nella storia di un ordine
+type OrderHistoryEntry struct {
+	Version    int             `json:"version"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
 }
 
 // ErrorResponse rappresenta una risposta di errore
@@ -41,8 +140,12 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-func NewOrderHandler(domain *order.Service) *OrderHandler {
-	return &OrderHandler{domain: domain}
+func NewOrderHandler(domain *order.Service, opts ...OrderHandlerOption) *OrderHandler {
+	h := &OrderHandler{domain: domain, maxItems: DefaultMaxOrderItems}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *OrderHandler) GetHandlers() []httpapi.HandlersMethods {
@@ -52,6 +155,11 @@ func (h *OrderHandler) GetHandlers() []httpapi.HandlersMethods {
 			Route:   "/orders",
 			Handler: h.CreateOrder,
 		},
+		{
+			Method:  "POST",
+			Route:   "/orders/quote",
+			Handler: h.QuoteOrder,
+		},
 		{
 			Method:  "GET",
 			Route:   "/orders/:id",
@@ -62,6 +170,11 @@ func (h *OrderHandler) GetHandlers() []httpapi.HandlersMethods {
 			Route:   "/orders",
 			Handler: h.GetOrders,
 		},
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/history",
+			Handler: h.GetOrderHistory,
+		},
 	}
 }
 
@@ -74,62 +187,184 @@ func (h *OrderHandler) GetHandlers() []httpapi.HandlersMethods {
 // @Param order body handlers.OrderRequest true "Dati ordine"
 // @Success 201 {object} handlers.OrderResponse
 // @Failure 400 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 413 {object} handlers.ErrorResponse
+// @Failure 422 {object} handlers.ErrorResponse
+// @Failure 429 {object} middleware.Problem
+// @Failure 503 {object} handlers.ErrorResponse
 // @Router /api/v1/orders [put]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	countryCode, items, opts, ok := h.bindOrderRequest(c)
+	if !ok {
+		return
+	}
+	ord, err := h.domain.CreateOrder(c.Request.Context(), countryCode, items, opts...)
+	if err != nil {
+		writeCreateOrderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toCreatedOrderResponse(ord))
+}
+
+// QuoteOrder Orders
+// @Summary Preventivo di un ordine
+// @Description Calcola prezzi, scaglioni di quantità, listini e IVA di un ordine come la creazione, senza salvarlo né impegnare lo stock; order_id e status sono vuoti
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param order body handlers.OrderRequest true "Dati ordine"
+// @Success 200 {object} handlers.OrderResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 413 {object} handlers.ErrorResponse
+// @Failure 422 {object} handlers.ErrorResponse
+// @Failure 429 {object} middleware.Problem
+// @Failure 503 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/quote [post]
+func (h *OrderHandler) QuoteOrder(c *gin.Context) {
+	countryCode, items, opts, ok := h.bindOrderRequest(c)
+	if !ok {
+		return
+	}
+	quote, err := h.domain.QuoteOrder(c.Request.Context(), countryCode, items, opts...)
+	if err != nil {
+		writeCreateOrderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toCreatedOrderResponse(quote))
+}
+
+// bindOrderRequest reads the order of the request body; on invalid input it
+// writes the error response and returns false
+func (h *OrderHandler) bindOrderRequest(c *gin.Context) (string, []order.CreateItem, []order.CreateOption, bool) {
 	var req OrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Message: "Request body too large"})
+			return "", nil, nil, false
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
-		return
+		return "", nil, nil, false
 	}
 	if req.CountryCode == "" {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Country code is required"})
-		return
+		return "", nil, nil, false
+	}
+	if len(req.Items) > h.maxItems {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Message: fmt.Sprintf("Too many items in order, maximum is %d", h.maxItems)})
+		return "", nil, nil, false
 	}
 	items := make([]order.CreateItem, 0, len(req.Items))
 	for _, it := range req.Items {
 		if it.Quantity == 0 {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Item quantity must be greater than zero"})
-			return
+			return "", nil, nil, false
 		}
 		items = append(items, order.CreateItem{
 			ProductID: it.ProductID,
+			SKU:       it.SKU,
+			BundleID:  it.BundleID,
 			Quantity:  it.Quantity,
 		})
 	}
-	ord, err := h.domain.CreateOrder(c.Request.Context(), strings.ToUpper(req.CountryCode), items)
-	if err != nil {
-		if err == order.ErrInvalidItem {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid item in order"})
-			return
-		}
-		if err == order.ErrInvalidVATRate {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid VAT rate for country"})
-			return
-		}
-		if err == order.ErrProductNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Product not found"})
-			return
+	var opts []order.CreateOption
+	if req.BillingAddress != nil {
+		opts = append(opts, order.WithBillingAddress(req.BillingAddress.toAddress(req.CountryCode)))
+	}
+	if req.ShippingAddress != nil {
+		address := req.ShippingAddress.toAddress(req.CountryCode)
+		if address.CountryCode != strings.ToUpper(req.CountryCode) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Shipping address must be in the order country"})
+			return "", nil, nil, false
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		opts = append(opts, order.WithShippingAddress(address))
+	}
+	if req.BuyerVATID != "" {
+		opts = append(opts, order.WithBuyerVATID(req.BuyerVATID))
+	}
+	return strings.ToUpper(req.CountryCode), items, opts, true
+}
+
+// writeCreateOrderError maps the errors of pricing a new order to responses
+func writeCreateOrderError(c *gin.Context, err error) {
+	if err == order.ErrInvalidItem {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid item in order"})
+		return
+	}
+	if err == order.ErrInvalidVATRate {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid VAT rate for country"})
+		return
+	}
+	if err == order.ErrProductNotFound {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Product not found"})
+		return
+	}
+	if err == bundle.ErrBundleNotFound {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Bundle not found"})
+		return
+	}
+	if err == order.ErrVariantRequired {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Product has variants, a SKU is required"})
+		return
+	}
+	if err == order.ErrOutOfStock {
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "SKU out of stock"})
+		return
+	}
+	if errors.Is(err, order.ErrInvalidDestination) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, order.ErrInvalidVATID) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err == order.ErrVATIDNotRegistered {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Message: "Buyer VAT ID is not registered for intra-community trade"})
 		return
 	}
+	if errors.Is(err, order.ErrVATIDVerificationUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Message: "Buyer VAT ID could not be verified, retry later"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+}
+
+// toCreatedOrderResponse describes a new order, or a quote, from the lines
+// priced at creation
+func toCreatedOrderResponse(ord *models.Order) OrderResponse {
 	resp := OrderResponse{
-		OrderID:    ord.ID,
-		TotalPrice: ord.TotalPrice,
-		TotalVAT:   ord.TotalVAT,
+		OrderID:       ord.ID,
+		Status:        ord.Status,
+		TotalPrice:    ord.TotalPrice,
+		TotalVAT:      ord.TotalVAT,
+		TotalNet:      utils.Round2(ord.TotalPrice - ord.TotalVAT),
+		BuyerVATID:    ord.BuyerVATID,
+		ReverseCharge: ord.ReverseCharge,
+		TaxTerritory:  ord.TaxTerritory,
 	}
 
-	for _, it := range ord.Items {
+	for i, it := range ord.Items {
+		resp.Bundles = addBundleLine(resp.Bundles, it.Bundle, it.VAT)
 		resp.Items = append(resp.Items, orderItemReply{
-			ProductID: it.ProductID,
-			Name:      it.Name,
-			Quantity:  it.Quantity,
-			UnitPrice: it.UnitPrice,
-			VAT:       it.VAT,
+			Line:             i + 1,
+			ProductID:        it.ProductID,
+			SKU:              it.SKU,
+			Name:             it.Name,
+			Quantity:         it.Quantity,
+			UnitPrice:        it.UnitPrice,
+			VATRate:          it.VATRate,
+			TaxClass:         it.TaxClass,
+			UnitPriceWithVAT: it.UnitPriceWithVAT,
+			PriceMode:        it.PriceMode,
+			PriceList:        it.PriceList,
+			VAT:              it.VAT,
+			Taxes:            toLineTaxReplies(it.Taxes),
+			BundleLine:       bundleLine(it.Bundle),
 		})
 	}
-	c.JSON(http.StatusCreated, resp)
-
+	return resp
 }
 
 // GetOrder
@@ -158,21 +393,7 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Order not found"})
 		return
 	}
-	resp := OrderResponse{
-		OrderID:    ord.Id,
-		TotalPrice: ord.TotalPrice,
-		TotalVAT:   ord.TotalVAT,
-	}
-
-	for _, it := range ord.Items {
-		resp.Items = append(resp.Items, orderItemReply{
-			ProductID: it.ID,
-			Name:      it.Name,
-			Quantity:  it.Quantity,
-			UnitPrice: it.Price,
-			VAT:       it.VAT,
-		})
-	}
+	resp := toOrderResponse(ord)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -193,22 +414,193 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		return
 	}
 	for _, ord := range orders {
-		orderResp := OrderResponse{
-			OrderID:    ord.Id,
-			TotalPrice: ord.TotalPrice,
-			TotalVAT:   ord.TotalVAT,
-		}
+		resp = append(resp, toOrderResponse(ord))
+	}
+	c.JSON(http.StatusOK, resp)
+}
 
-		for _, it := range ord.Items {
-			orderResp.Items = append(orderResp.Items, orderItemReply{
-				ProductID: it.ID,
-				Name:      it.Name,
-				Quantity:  it.Quantity,
-				UnitPrice: it.Price,
-				VAT:       it.VAT,
-			})
+// GetOrderHistory
+// @Summary Storia di un ordine
+// @Description Elenca in ordine gli eventi immutabili registrati per un ordine
+// @Tags Orders
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Success 200 {array} handlers.OrderHistoryEntry
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Failure 501 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/history [get]
+func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
+	history, err := h.domain.GetOrderHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == order.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Order not found"})
+			return
+		}
+		if err == order.ErrHistoryUnavailable {
+			c.JSON(http.StatusNotImplemented, ErrorResponse{Message: "Order history is not available"})
+			return
 		}
-		resp = append(resp, orderResp)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	resp := make([]OrderHistoryEntry, 0, len(history))
+	for _, e := range history {
+		resp = append(resp, OrderHistoryEntry{
+			Version:    e.Version,
+			Type:       e.Type,
+			OccurredAt: e.OccurredAt,
+			Data:       e.Data,
+		})
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+// OrderAdminHandler moves the orders through their lifecycle in the admin API
+type OrderAdminHandler struct {
+	domain *order.Service
+}
+
+func NewOrderAdminHandler(domain *order.Service) *OrderAdminHandler {
+	return &OrderAdminHandler{domain: domain}
+}
+
+func (h *OrderAdminHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/cancel",
+			Handler: h.CancelOrder,
+		},
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/ship",
+			Handler: h.ShipOrder,
+		},
+	}
+}
+
+// CancelOrder
+// @Summary Annulla un ordine
+// @Description Annulla un ordine non ancora pagato né spedito
+// @Tags Orders
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Ordine"
+// @Success 200 {object} handlers.OrderResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/orders/{id}/cancel [post]
+func (h *OrderAdminHandler) CancelOrder(c *gin.Context) {
+	ord, err := h.domain.CancelOrder(c.Request.Context(), c.Param("id"))
+	writeTransition(c, ord, err)
+}
+
+// ShipOrder
+// @Summary Spedisci un ordine
+// @Description Segna come spedito un ordine pagato
+// @Tags Orders
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Ordine"
+// @Success 200 {object} handlers.OrderResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/orders/{id}/ship [post]
+func (h *OrderAdminHandler) ShipOrder(c *gin.Context) {
+	ord, err := h.domain.ShipOrder(c.Request.Context(), c.Param("id"))
+	writeTransition(c, ord, err)
+}
+
+func writeTransition(c *gin.Context, ord *order.Detail, err error) {
+	if err != nil {
+		if err == order.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Order not found"})
+			return
+		}
+		if err == order.ErrInvalidStatusTransition {
+			c.JSON(http.StatusConflict, ErrorResponse{Message: "Order status does not allow this operation"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOrderResponse(ord))
+}
+
+func toOrderResponse(ord *order.Detail) OrderResponse {
+	resp := OrderResponse{
+		OrderID:       ord.Id,
+		Status:        ord.Status,
+		TotalPrice:    ord.TotalPrice,
+		TotalVAT:      ord.TotalVAT,
+		TotalNet:      utils.Round2(ord.TotalPrice - ord.TotalVAT),
+		BuyerVATID:    ord.BuyerVATID,
+		ReverseCharge: ord.ReverseCharge,
+		TaxTerritory:  ord.TaxTerritory,
+	}
+	for _, it := range ord.Items {
+		resp.Bundles = addBundleLine(resp.Bundles, it.Bundle, it.VAT)
+		resp.Items = append(resp.Items, orderItemReply{
+			Line:             it.Line,
+			ProductID:        it.ID,
+			SKU:              it.SKU,
+			Name:             it.Name,
+			Quantity:         it.Quantity,
+			UnitPrice:        it.Price,
+			VATRate:          it.VATRate,
+			TaxClass:         it.TaxClass,
+			UnitPriceWithVAT: it.PriceWithVAT,
+			PriceMode:        it.PriceMode,
+			PriceList:        it.PriceList,
+			VAT:              it.VAT,
+			Taxes:            toLineTaxReplies(it.Taxes),
+			BundleLine:       bundleLine(it.Bundle),
+		})
+	}
+	return resp
+}
+
+// addBundleLine adds a component line, of the given total, to the bundle it
+// belongs to; the lines outside bundles leave bundles as they are
+func addBundleLine(bundles []OrderBundleReply, b *models.ItemBundle, total float64) []OrderBundleReply {
+	if b == nil {
+		return bundles
+	}
+	for i := range bundles {
+		if bundles[i].Line == b.Line {
+			bundles[i].TotalPrice = utils.Round2(bundles[i].TotalPrice + total)
+			return bundles
+		}
+	}
+	return append(bundles, OrderBundleReply{Line: b.Line, BundleID: b.ID, Name: b.Name, Quantity: b.Quantity, TotalPrice: total})
+}
+
+func bundleLine(b *models.ItemBundle) int {
+	if b == nil {
+		return 0
+	}
+	return b.Line
+}
+
+func toLineTaxReplies(taxes []models.LineTax) []LineTaxReply {
+	var replies []LineTaxReply
+	for _, t := range taxes {
+		replies = append(replies, LineTaxReply{Type: t.Type, Name: t.Name, Rate: t.Rate, Amount: t.Amount})
+	}
+	return replies
+}
+
+// toAddress converte l'indirizzo; il paese, se assente, è quello dell'ordine
+func (a AddressRequest) toAddress(orderCountry string) models.Address {
+	address := models.Address(a)
+	address.CountryCode = strings.ToUpper(address.CountryCode)
+	if address.CountryCode == "" {
+		address.CountryCode = strings.ToUpper(orderCountry)
+	}
+	return address
+}
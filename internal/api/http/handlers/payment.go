@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/payment"
+	"purchase-cart-service/models"
+	"time"
+)
+
+type PaymentHandler struct {
+	domain      *payment.Service
+	autoCapture bool
+}
+
+// PaymentRequest rappresenta la richiesta di pagamento di un ordine
+type PaymentRequest struct {
+	// PaymentMethod è il token del metodo di pagamento del cliente
+	PaymentMethod string `json:"payment_method"`
+	// Capture incassa subito l'importo autorizzato; se omesso vale la configurazione del servizio
+	Capture *bool `json:"capture,omitempty"`
+}
+
+// PaymentResponse rappresenta un payment intent di un ordine
+type PaymentResponse struct {
+	ID             string    `json:"id"`
+	OrderID        string    `json:"order_id"`
+	Status         string    `json:"status"`
+	Amount         float64   `json:"amount"`
+	Currency       string    `json:"currency"`
+	CapturedAmount float64   `json:"captured_amount"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	FailureReason  string    `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// RefundRequest rappresenta la richiesta di rimborso di righe di un ordine pagato
+type RefundRequest struct {
+	Lines  []RefundLineRequest `json:"lines"`
+	Reason string              `json:"reason,omitempty"`
+}
+
+// RefundLineRequest indica la quantità da rimborsare di una riga dell'ordine
+type RefundLineRequest struct {
+	// Line è la posizione della riga nell'ordine (campo line delle righe), da 1
+	Line     int `json:"line"`
+	Quantity int `json:"quantity"`
+}
+
+// CreditNoteResponse rappresenta la nota di credito emessa per un rimborso
+type CreditNoteResponse struct {
+	Number    string               `json:"number"`
+	OrderID   string               `json:"order_id"`
+	PaymentID string               `json:"payment_id"`
+	Reason    string               `json:"reason,omitempty"`
+	Currency  string               `json:"currency"`
+	Lines     []CreditNoteLineItem `json:"lines"`
+	TotalNet  float64              `json:"total_net"`
+	TotalVAT  float64              `json:"total_vat"`
+	Total     float64              `json:"total"`
+	IssuedAt  time.Time            `json:"issued_at"`
+}
+
+// CreditNoteLineItem è la parte rimborsata di una riga d'ordine, con l'aliquota IVA della vendita
+type CreditNoteLineItem struct {
+	Line      int     `json:"line"`
+	ProductID string  `json:"product_id"`
+	SKU       string  `json:"sku,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	VATRate   float64 `json:"vat_rate"`
+	Net       float64 `json:"net"`
+	VAT       float64 `json:"vat"`
+	Total     float64 `json:"total"`
+}
+
+// NewPaymentHandler creates the handler; autoCapture is the default of
+// PaymentRequest.Capture
+func NewPaymentHandler(domain *payment.Service, autoCapture bool) *PaymentHandler {
+	return &PaymentHandler{domain: domain, autoCapture: autoCapture}
+}
+
+func (h *PaymentHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/payments",
+			Handler: h.StartPayment,
+		},
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/payments",
+			Handler: h.GetPayments,
+		},
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/payments/:paymentId",
+			Handler: h.GetPayment,
+		},
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/credit-notes",
+			Handler: h.GetCreditNotes,
+		},
+		{
+			Method:  "GET",
+			Route:   "/orders/:id/credit-notes/:number",
+			Handler: h.GetCreditNote,
+		},
+	}
+}
+
+// PaymentAdminHandler captures, voids and refunds payments in the admin API
+type PaymentAdminHandler struct {
+	domain *payment.Service
+}
+
+func NewPaymentAdminHandler(domain *payment.Service) *PaymentAdminHandler {
+	return &PaymentAdminHandler{domain: domain}
+}
+
+func (h *PaymentAdminHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/payments/:paymentId/capture",
+			Handler: h.CapturePayment,
+		},
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/payments/:paymentId/void",
+			Handler: h.VoidPayment,
+		},
+		{
+			Method:  "POST",
+			Route:   "/orders/:id/refunds",
+			Handler: h.Refund,
+		},
+	}
+}
+
+// StartPayment
+// @Summary Avvia il pagamento di un ordine
+// @Description Autorizza il totale dell'ordine sul metodo di pagamento e, se richiesto, lo incassa portando l'ordine in stato paid
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Param payment body handlers.PaymentRequest true "Dati pagamento"
+// @Success 201 {object} handlers.PaymentResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 402 {object} handlers.PaymentResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 502 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/payments [post]
+func (h *PaymentHandler) StartPayment(c *gin.Context) {
+	var req PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	capture := h.autoCapture
+	if req.Capture != nil {
+		capture = *req.Capture
+	}
+	intent, err := h.domain.StartPayment(c.Request.Context(), c.Param("id"), payment.StartInput{
+		PaymentMethod: req.PaymentMethod,
+		Capture:       capture,
+	})
+	writePayment(c, http.StatusCreated, intent, err)
+}
+
+// GetPayments
+// @Summary Elenca i pagamenti di un ordine
+// @Tags Payments
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Success 200 {array} handlers.PaymentResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/payments [get]
+func (h *PaymentHandler) GetPayments(c *gin.Context) {
+	intents, err := h.domain.GetPayments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	resp := make([]PaymentResponse, 0, len(intents))
+	for _, intent := range intents {
+		resp = append(resp, toPaymentResponse(intent))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetPayment
+// @Summary Ottieni un pagamento di un ordine
+// @Tags Payments
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Param paymentId path string true "ID Pagamento"
+// @Success 200 {object} handlers.PaymentResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/payments/{paymentId} [get]
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	intent, err := h.domain.GetPayment(c.Request.Context(), c.Param("id"), c.Param("paymentId"))
+	writePayment(c, http.StatusOK, intent, err)
+}
+
+// CapturePayment
+// @Summary Incassa un pagamento autorizzato
+// @Description Incassa l'importo autorizzato e porta l'ordine in stato paid
+// @Tags Payments
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Ordine"
+// @Param paymentId path string true "ID Pagamento"
+// @Success 200 {object} handlers.PaymentResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 402 {object} handlers.PaymentResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 502 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/orders/{id}/payments/{paymentId}/capture [post]
+func (h *PaymentAdminHandler) CapturePayment(c *gin.Context) {
+	intent, err := h.domain.Capture(c.Request.Context(), c.Param("id"), c.Param("paymentId"))
+	writePayment(c, http.StatusOK, intent, err)
+}
+
+// VoidPayment
+// @Summary Annulla un pagamento autorizzato
+// @Description Rilascia un'autorizzazione non ancora incassata
+// @Tags Payments
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Ordine"
+// @Param paymentId path string true "ID Pagamento"
+// @Success 200 {object} handlers.PaymentResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 502 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/orders/{id}/payments/{paymentId}/void [post]
+func (h *PaymentAdminHandler) VoidPayment(c *gin.Context) {
+	intent, err := h.domain.Void(c.Request.Context(), c.Param("id"), c.Param("paymentId"))
+	writePayment(c, http.StatusOK, intent, err)
+}
+
+// Refund
+// @Summary Rimborsa righe di un ordine pagato
+// @Description Rimborsa le quantità indicate stornando l'IVA all'aliquota della vendita ed emette una nota di credito numerata
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Ordine"
+// @Param refund body handlers.RefundRequest true "Righe da rimborsare"
+// @Success 201 {object} handlers.CreditNoteResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 409 {object} handlers.ErrorResponse
+// @Failure 502 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/orders/{id}/refunds [post]
+func (h *PaymentAdminHandler) Refund(c *gin.Context) {
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	in := payment.RefundInput{Reason: req.Reason}
+	for _, line := range req.Lines {
+		in.Lines = append(in.Lines, payment.RefundLine{Line: line.Line, Quantity: line.Quantity})
+	}
+	note, err := h.domain.Refund(c.Request.Context(), c.Param("id"), in)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toCreditNoteResponse(note))
+}
+
+// GetCreditNotes
+// @Summary Elenca le note di credito di un ordine
+// @Tags Payments
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Success 200 {array} handlers.CreditNoteResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/credit-notes [get]
+func (h *PaymentHandler) GetCreditNotes(c *gin.Context) {
+	notes, err := h.domain.GetCreditNotes(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	resp := make([]CreditNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		resp = append(resp, toCreditNoteResponse(note))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetCreditNote
+// @Summary Ottieni una nota di credito di un ordine
+// @Tags Payments
+// @Produce json
+// @Param id path string true "ID Ordine"
+// @Param number path string true "Numero nota di credito"
+// @Success 200 {object} handlers.CreditNoteResponse
+// @Failure 404 {object} handlers.ErrorResponse
+// @Router /api/v1/orders/{id}/credit-notes/{number} [get]
+func (h *PaymentHandler) GetCreditNote(c *gin.Context) {
+	note, err := h.domain.GetCreditNote(c.Request.Context(), c.Param("id"), c.Param("number"))
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toCreditNoteResponse(note))
+}
+
+func writePayment(c *gin.Context, status int, intent *models.PaymentIntent, err error) {
+	if err != nil {
+		// a declined operation still returns the intent with its failure reason
+		if errors.Is(err, payment.ErrDeclined) && intent != nil {
+			c.JSON(http.StatusPaymentRequired, toPaymentResponse(intent))
+			return
+		}
+		writePaymentError(c, err)
+		return
+	}
+	c.JSON(status, toPaymentResponse(intent))
+}
+
+func writePaymentError(c *gin.Context, err error) {
+	var gatewayErr *payment.GatewayError
+	switch {
+	case err == payment.ErrInvalidPaymentMethod:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Payment method is required"})
+	case err == payment.ErrOrderNotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Order not found"})
+	case err == payment.ErrPaymentNotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Payment not found"})
+	case err == payment.ErrOrderNotPayable:
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "Order is not awaiting payment"})
+	case err == payment.ErrInvalidRefund:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Refund lines must name products of the order with a positive quantity"})
+	case err == payment.ErrCreditNoteNotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Credit note not found"})
+	case err == payment.ErrNotRefundable:
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "Order has no captured payment to refund"})
+	case err == payment.ErrRefundExceedsQuantity:
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "Refund exceeds the quantity sold"})
+	case err == payment.ErrRefundExceedsCaptured:
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "Refund exceeds the captured amount"})
+	case err == payment.ErrInvalidPaymentState, errors.Is(err, payment.ErrDeclined):
+		c.JSON(http.StatusConflict, ErrorResponse{Message: "Payment status does not allow this operation"})
+	case errors.As(err, &gatewayErr):
+		c.JSON(http.StatusBadGateway, ErrorResponse{Message: "Payment provider unavailable"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+	}
+}
+
+func toPaymentResponse(intent *models.PaymentIntent) PaymentResponse {
+	return PaymentResponse{
+		ID:             intent.ID,
+		OrderID:        intent.OrderID,
+		Status:         intent.Status,
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		CapturedAmount: intent.CapturedAmount,
+		RefundedAmount: intent.RefundedAmount,
+		FailureReason:  intent.FailureReason,
+		CreatedAt:      intent.CreatedAt,
+		UpdatedAt:      intent.UpdatedAt,
+	}
+}
+
+func toCreditNoteResponse(note *models.CreditNote) CreditNoteResponse {
+	resp := CreditNoteResponse{
+		Number:    note.Number,
+		OrderID:   note.OrderID,
+		PaymentID: note.PaymentID,
+		Reason:    note.Reason,
+		Currency:  note.Currency,
+		Lines:     make([]CreditNoteLineItem, 0, len(note.Lines)),
+		TotalNet:  note.TotalNet,
+		TotalVAT:  note.TotalVAT,
+		Total:     note.Total,
+		IssuedAt:  note.IssuedAt,
+	}
+	for _, line := range note.Lines {
+		resp.Lines = append(resp.Lines, CreditNoteLineItem{
+			Line:      line.Line,
+			ProductID: line.ProductID,
+			SKU:       line.SKU,
+			Name:      line.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+			VATRate:   line.VATRate,
+			Net:       line.Net,
+			VAT:       line.VAT,
+			Total:     line.Total,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/models"
+	"time"
+)
+
+// PriceListHandler exposes the price lists in the admin API
+type PriceListHandler struct {
+	domain *pricing.Service
+}
+
+func NewPriceListHandler(domain *pricing.Service) *PriceListHandler {
+	return &PriceListHandler{domain: domain}
+}
+
+// PriceListRequest è un listino con i prezzi contrattuali e i clienti e gruppi di clienti a cui è assegnato
+type PriceListRequest struct {
+	Name           string             `json:"name"`
+	CustomerGroups []string           `json:"customer_groups,omitempty"`
+	Clients        []string           `json:"clients,omitempty"`
+	Prices         []ListPriceRequest `json:"prices"`
+}
+
+// ListPriceRequest è il prezzo di un prodotto o di uno SKU nel listino, nella modalità (netto o lordo) del prodotto;
+// vale da valid_from a valid_to escluso, e senza date vale sempre. Gli scaglioni sostituiscono quelli del catalogo
+type ListPriceRequest struct {
+	ProductID string            `json:"product_id"`
+	Price     float64           `json:"price"`
+	Tiers     []ListTierRequest `json:"tiers,omitempty"`
+	ValidFrom *time.Time        `json:"valid_from,omitempty"`
+	ValidTo   *time.Time        `json:"valid_to,omitempty"`
+}
+
+// ListTierRequest è uno scaglione di quantità del prezzo: da min_quantity unità (almeno 2) in su ogni unità costa price
+type ListTierRequest struct {
+	MinQuantity int     `json:"min_quantity"`
+	Price       float64 `json:"price"`
+}
+
+// PriceListResponse è un listino salvato
+type PriceListResponse struct {
+	ID string `json:"id"`
+	PriceListRequest
+}
+
+func (h *PriceListHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "GET",
+			Route:   "/price-lists",
+			Handler: h.GetPriceLists,
+		},
+		{
+			Method:  "PUT",
+			Route:   "/price-lists/:id",
+			Handler: h.SavePriceList,
+		},
+	}
+}
+
+// GetPriceLists
+// @Summary Elenco dei listini
+// @Description Restituisce i listini con i loro prezzi e le assegnazioni, per ID
+// @Tags PriceLists
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} handlers.PriceListResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/price-lists [get]
+func (h *PriceListHandler) GetPriceLists(c *gin.Context) {
+	lists, err := h.domain.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to retrieve price lists"})
+		return
+	}
+	response := []PriceListResponse{}
+	for _, l := range lists {
+		response = append(response, toPriceListResponse(l))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SavePriceList
+// @Summary Crea o sostituisce un listino
+// @Description Salva un listino con i prezzi per prodotto o SKU, gli scaglioni di quantità, le date di validità e l'assegnazione a gruppi di clienti o a client API
+// @Tags PriceLists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID del listino"
+// @Param list body handlers.PriceListRequest true "Listino"
+// @Success 200 {object} handlers.PriceListResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/price-lists/{id} [put]
+func (h *PriceListHandler) SavePriceList(c *gin.Context) {
+	var req PriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	list := models.PriceList{ID: c.Param("id"), Name: req.Name, CustomerGroups: req.CustomerGroups, Clients: req.Clients}
+	for _, p := range req.Prices {
+		price := models.ListPrice{ProductID: p.ProductID, Price: p.Price}
+		for _, t := range p.Tiers {
+			price.Tiers = append(price.Tiers, models.PriceTier{MinQuantity: t.MinQuantity, Price: t.Price})
+		}
+		if p.ValidFrom != nil {
+			price.ValidFrom = *p.ValidFrom
+		}
+		if p.ValidTo != nil {
+			price.ValidTo = *p.ValidTo
+		}
+		list.Prices = append(list.Prices, price)
+	}
+	if err := h.domain.Save(c.Request.Context(), &list); err != nil {
+		if errors.Is(err, pricing.ErrInvalidPriceList) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to save price list"})
+		return
+	}
+	c.JSON(http.StatusOK, toPriceListResponse(list))
+}
+
+func toPriceListResponse(l models.PriceList) PriceListResponse {
+	response := PriceListResponse{ID: l.ID, PriceListRequest: PriceListRequest{
+		Name:           l.Name,
+		CustomerGroups: l.CustomerGroups,
+		Clients:        l.Clients,
+		Prices:         []ListPriceRequest{},
+	}}
+	for _, p := range l.Prices {
+		price := ListPriceRequest{ProductID: p.ProductID, Price: p.Price}
+		for _, t := range p.Tiers {
+			price.Tiers = append(price.Tiers, ListTierRequest{MinQuantity: t.MinQuantity, Price: t.Price})
+		}
+		if !p.ValidFrom.IsZero() {
+			price.ValidFrom = &p.ValidFrom
+		}
+		if !p.ValidTo.IsZero() {
+			price.ValidTo = &p.ValidTo
+		}
+		response.Prices = append(response.Prices, price)
+	}
+	return response
+}
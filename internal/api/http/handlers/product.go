@@ -23,6 +23,81 @@ type ProductResponse struct {
 	Price        float64 `json:"price"`
 	VAT          float64 `json:"vat"`
 	PriceWithVAT float64 `json:"price_with_vat"`
+	// PriceMode dice se il prezzo di listino è netto (net) o IVA inclusa (gross)
+	PriceMode string `json:"price_mode"`
+	// PriceList è il listino del cliente da cui viene il prezzo, assente per il prezzo di catalogo
+	PriceList string `json:"price_list,omitempty"`
+	// Tiers sono gli scaglioni di quantità: da min_quantity unità in su ogni unità della riga costa il prezzo dello scaglione
+	Tiers []PriceTierResponse `json:"tiers,omitempty"`
+	// TaxClass è la classe fiscale da cui dipende l'aliquota IVA (vat), CategoryIDs le categorie del prodotto
+	TaxClass    string   `json:"tax_class,omitempty"`
+	CategoryIDs []string `json:"category_ids,omitempty"`
+	// Options e Variants sono la matrice delle varianti: i valori di ogni opzione (es. taglia, colore) e gli SKU
+	Options  []VariantOptionResponse `json:"options,omitempty"`
+	Variants []VariantResponse       `json:"variants,omitempty"`
+}
+
+// VariantOptionResponse è un'opzione in cui differiscono le varianti, con i suoi valori
+type VariantOptionResponse struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// VariantResponse è uno SKU del prodotto, con prezzo e disponibilità propri
+type VariantResponse struct {
+	SKU          string              `json:"sku"`
+	Attributes   map[string]string   `json:"attributes"`
+	Price        float64             `json:"price"`
+	PriceWithVAT float64             `json:"price_with_vat"`
+	PriceList    string              `json:"price_list,omitempty"`
+	Tiers        []PriceTierResponse `json:"tiers,omitempty"`
+	Stock        int                 `json:"stock"`
+}
+
+// PriceTierResponse è uno scaglione di quantità, con il prezzo unitario netto e IVA inclusa
+type PriceTierResponse struct {
+	MinQuantity  int     `json:"min_quantity"`
+	Price        float64 `json:"price"`
+	PriceWithVAT float64 `json:"price_with_vat"`
+}
+
+func toProductResponse(p product.Detail) ProductResponse {
+	response := ProductResponse{
+		ID:           p.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		Price:        p.Price,
+		VAT:          p.VAT,
+		PriceWithVAT: p.PriceWithVAT,
+		PriceMode:    p.PriceMode,
+		PriceList:    p.PriceList,
+		TaxClass:     p.TaxClass,
+		CategoryIDs:  p.CategoryIDs,
+		Tiers:        toPriceTierResponses(p.Tiers),
+	}
+	for _, o := range p.Options {
+		response.Options = append(response.Options, VariantOptionResponse{Name: o.Name, Values: o.Values})
+	}
+	for _, v := range p.Variants {
+		response.Variants = append(response.Variants, VariantResponse{
+			SKU:          v.SKU,
+			Attributes:   v.Attributes,
+			Price:        v.Price,
+			PriceWithVAT: v.PriceWithVAT,
+			PriceList:    v.PriceList,
+			Tiers:        toPriceTierResponses(v.Tiers),
+			Stock:        v.Stock,
+		})
+	}
+	return response
+}
+
+func toPriceTierResponses(tiers []product.Tier) []PriceTierResponse {
+	var response []PriceTierResponse
+	for _, t := range tiers {
+		response = append(response, PriceTierResponse{MinQuantity: t.MinQuantity, Price: t.Price, PriceWithVAT: t.PriceWithVAT})
+	}
+	return response
 }
 
 func (h *ProductHandler) GetHandlers() []httpapi.HandlersMethods {
@@ -60,14 +135,7 @@ func (h *ProductHandler) GetAllProducts(c *gin.Context) {
 
 	var response []ProductResponse
 	for _, p := range products {
-		response = append(response, ProductResponse{
-			ID:           p.ID,
-			Name:         p.Name,
-			Description:  p.Description,
-			Price:        p.Price,
-			VAT:          p.VAT,
-			PriceWithVAT: p.PriceWithVAT,
-		})
+		response = append(response, toProductResponse(p))
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -98,15 +166,85 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 		return
 	}
 
-	response := ProductResponse{
-		ID:           productDetail.ID,
-		Name:         productDetail.Name,
-		Description:  productDetail.Description,
-		Price:        productDetail.Price,
-		VAT:          productDetail.VAT,
-		PriceWithVAT: productDetail.PriceWithVAT,
+	c.JSON(http.StatusOK, toProductResponse(*productDetail))
+
+}
+
+// ProductAdminHandler exposes the catalog changes of the admin API
+type ProductAdminHandler struct {
+	domain *product.Service
+}
+
+func NewProductAdminHandler(domain *product.Service) *ProductAdminHandler {
+	return &ProductAdminHandler{domain: domain}
+}
+
+// ProductPriceRequest rappresenta il nuovo prezzo di listino di un prodotto;
+// price_mode (net o gross) dice se è netto o IVA inclusa, vuoto lascia quello attuale
+type ProductPriceRequest struct {
+	Price     float64 `json:"price"`
+	PriceMode string  `json:"price_mode,omitempty"`
+}
+
+// ProductPriceResponse rappresenta il prezzo di listino corrente di un prodotto
+type ProductPriceResponse struct {
+	ID        string  `json:"id"`
+	Price     float64 `json:"price"`
+	PriceMode string  `json:"price_mode,omitempty"`
+}
+
+func (h *ProductAdminHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "PUT",
+			Route:   "/products/:id/price",
+			Handler: h.UpdatePrice,
+		},
 	}
+}
 
+// UpdatePrice
+// @Summary Aggiorna il prezzo di un prodotto
+// @Description Imposta il prezzo di listino di un prodotto, netto o IVA inclusa, e pubblica l'evento product.price_changed
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Product ID o SKU"
+// @Param price body handlers.ProductPriceRequest true "Nuovo prezzo"
+// @Success 200 {object} handlers.ProductPriceResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/products/{id}/price [put]
+func (h *ProductAdminHandler) UpdatePrice(c *gin.Context) {
+	var req ProductPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return
+	}
+	p, err := h.domain.UpdatePrice(c.Request.Context(), c.Param("id"), req.Price, req.PriceMode)
+	if err != nil {
+		if err == product.ErrInvalidPrice {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Price must be greater than zero"})
+			return
+		}
+		if err == product.ErrInvalidPriceMode {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Price mode must be net or gross"})
+			return
+		}
+		if err == product.ErrProductNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Message: "Product not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	response := ProductPriceResponse{ID: p.ID, Price: p.Price, PriceMode: p.PriceMode}
+	// il prezzo di uno SKU è quello della variante
+	if v, ok := p.Variant(c.Param("id")); ok {
+		response.ID, response.Price = v.SKU, p.PriceOf(v)
+	}
 	c.JSON(http.StatusOK, response)
-
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/search"
+	"strconv"
+	"strings"
+)
+
+type SearchHandler struct {
+	domain *search.Service
+}
+
+func NewSearchHandler(domain *search.Service) *SearchHandler {
+	return &SearchHandler{domain: domain}
+}
+
+// SearchResponse contiene i prodotti trovati, dal più rilevante, e le faccette dei risultati
+type SearchResponse struct {
+	Query string `json:"query"`
+	// Total conta tutti i risultati, anche oltre il limite
+	Total   int                  `json:"total"`
+	Results []SearchHitResponse  `json:"results"`
+	Facets  SearchFacetsResponse `json:"facets"`
+}
+
+// SearchHitResponse è un prodotto trovato con il suo punteggio di rilevanza
+type SearchHitResponse struct {
+	ProductResponse
+	Score float64 `json:"score"`
+}
+
+// SearchFacetsResponse conta i risultati per categoria e per fascia di prezzo con IVA
+type SearchFacetsResponse struct {
+	Categories []CategoryFacetResponse `json:"categories"`
+	Prices     []PriceFacetResponse    `json:"prices"`
+}
+
+type CategoryFacetResponse struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// PriceFacetResponse è una fascia di prezzo da from a to escluso; l'ultima non ha to
+type PriceFacetResponse struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to,omitempty"`
+	Count int      `json:"count"`
+}
+
+func (h *SearchHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "GET",
+			Route:   "/products/search",
+			Handler: h.SearchProducts,
+		},
+	}
+}
+
+// SearchProducts
+// @Summary Ricerca prodotti
+// @Description Cerca nel catalogo per parole intere o iniziali, in italiano e in inglese, con le faccette per categoria e prezzo
+// @Tags Products
+// @Produce json
+// @Param q query string true "Testo da cercare"
+// @Param country_code query string true "Country Code for VAT calculation"
+// @Param category query string false "Slug della categoria, sottocategorie comprese"
+// @Param min_price query number false "Prezzo minimo con IVA"
+// @Param max_price query number false "Prezzo massimo con IVA"
+// @Param limit query int false "Numero massimo di risultati (default 20, massimo 100)"
+// @Success 200 {object} handlers.SearchResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/products/search [get]
+func (h *SearchHandler) SearchProducts(c *gin.Context) {
+	q := search.Query{
+		Text:        c.Query("q"),
+		CountryCode: strings.ToUpper(c.Query("country_code")),
+		Category:    c.Query("category"),
+	}
+	var err error
+	if q.MinPrice, err = floatQuery(c, "min_price"); err != nil || q.MinPrice < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid min_price"})
+		return
+	}
+	if q.MaxPrice, err = floatQuery(c, "max_price"); err != nil || q.MaxPrice < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid max_price"})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid limit"})
+			return
+		}
+	}
+
+	result, err := h.domain.Search(c.Request.Context(), q)
+	if err != nil {
+		switch err {
+		case search.ErrEmptyQuery:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Missing search query"})
+		case category.ErrCategoryNotFound:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Unknown category"})
+		case product.ErrInvalidVATRate:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid VAT rate for country"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Failed to search products"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toSearchResponse(q.Text, result))
+}
+
+// floatQuery parses an optional number of the query string, zero when absent
+func floatQuery(c *gin.Context, name string) (float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func toSearchResponse(query string, result *search.Result) SearchResponse {
+	response := SearchResponse{
+		Query:   query,
+		Total:   result.Total,
+		Results: []SearchHitResponse{},
+		Facets:  SearchFacetsResponse{Categories: []CategoryFacetResponse{}, Prices: []PriceFacetResponse{}},
+	}
+	for _, hit := range result.Hits {
+		response.Results = append(response.Results, SearchHitResponse{ProductResponse: toProductResponse(hit.Detail), Score: hit.Score})
+	}
+	for _, f := range result.Facets.Categories {
+		response.Facets.Categories = append(response.Facets.Categories, CategoryFacetResponse{Slug: f.Slug, Name: f.Name, Count: f.Count})
+	}
+	for _, f := range result.Facets.Prices {
+		price := PriceFacetResponse{From: f.From, Count: f.Count}
+		if f.To != 0 {
+			price.To = &f.To
+		}
+		response.Facets.Prices = append(response.Facets.Prices, price)
+	}
+	return response
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/domain/webhook"
+	"purchase-cart-service/models"
+	"time"
+)
+
+type WebhookHandler struct {
+	domain *webhook.Service
+}
+
+// WebhookRequest rappresenta i dati di una sottoscrizione webhook
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret firma le consegne; se vuoto in creazione viene generato
+	Secret string `json:"secret,omitempty"`
+	// Active vale true se omesso
+	Active *bool `json:"active,omitempty"`
+}
+
+// WebhookResponse rappresenta una sottoscrizione webhook. Il secret è
+// restituito solo alla creazione
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeadLetterResponse rappresenta una consegna fallita dopo tutti i tentativi
+type DeadLetterResponse struct {
+	ID             string    `json:"id"`
+	DeliveryID     string    `json:"delivery_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+func NewWebhookHandler(domain *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{domain: domain}
+}
+
+func (h *WebhookHandler) GetHandlers() []httpapi.HandlersMethods {
+	return []httpapi.HandlersMethods{
+		{
+			Method:  "POST",
+			Route:   "/webhooks",
+			Handler: h.CreateWebhook,
+		},
+		{
+			Method:  "GET",
+			Route:   "/webhooks",
+			Handler: h.GetWebhooks,
+		},
+		{
+			Method:  "GET",
+			Route:   "/webhooks/:id",
+			Handler: h.GetWebhook,
+		},
+		{
+			Method:  "PUT",
+			Route:   "/webhooks/:id",
+			Handler: h.UpdateWebhook,
+		},
+		{
+			Method:  "DELETE",
+			Route:   "/webhooks/:id",
+			Handler: h.DeleteWebhook,
+		},
+		{
+			Method:  "GET",
+			Route:   "/webhooks/dead-letters",
+			Handler: h.GetDeadLetters,
+		},
+		{
+			Method:  "POST",
+			Route:   "/webhooks/dead-letters/:id/redeliver",
+			Handler: h.Redeliver,
+		},
+	}
+}
+
+// CreateWebhook
+// @Summary Registra una sottoscrizione webhook
+// @Description Registra un endpoint che riceve gli eventi degli ordini firmati con HMAC-SHA256
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param webhook body handlers.WebhookRequest true "Dati sottoscrizione"
+// @Success 201 {object} handlers.WebhookResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	in, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+	sub, err := h.domain.CreateSubscription(c.Request.Context(), in)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	resp := toWebhookResponse(sub)
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetWebhooks
+// @Summary Elenca le sottoscrizioni webhook
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} handlers.WebhookResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	subs, err := h.domain.GetAllSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	resp := make([]WebhookResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, toWebhookResponse(sub))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWebhook
+// @Summary Ottieni una sottoscrizione webhook per ID
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Sottoscrizione"
+// @Success 200 {object} handlers.WebhookResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	sub, err := h.domain.GetSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(sub))
+}
+
+// UpdateWebhook
+// @Summary Aggiorna una sottoscrizione webhook
+// @Description Sostituisce URL, eventi e stato; un secret vuoto mantiene quello corrente
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID Sottoscrizione"
+// @Param webhook body handlers.WebhookRequest true "Dati sottoscrizione"
+// @Success 200 {object} handlers.WebhookResponse
+// @Failure 400 {object} handlers.ErrorResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	in, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+	sub, err := h.domain.UpdateSubscription(c.Request.Context(), c.Param("id"), in)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(sub))
+}
+
+// DeleteWebhook
+// @Summary Elimina una sottoscrizione webhook
+// @Tags Webhooks
+// @Security ApiKeyAuth
+// @Param id path string true "ID Sottoscrizione"
+// @Success 204
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.domain.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeadLetters
+// @Summary Elenca le consegne webhook fallite
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} handlers.DeadLetterResponse
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks/dead-letters [get]
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	deadLetters, err := h.domain.GetAllDeadLetters(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+	resp := make([]DeadLetterResponse, 0, len(deadLetters))
+	for _, dl := range deadLetters {
+		resp = append(resp, DeadLetterResponse{
+			ID:             dl.ID,
+			DeliveryID:     dl.DeliveryID,
+			SubscriptionID: dl.SubscriptionID,
+			EventID:        dl.EventID,
+			EventType:      dl.EventType,
+			Attempts:       dl.Attempts,
+			LastError:      dl.LastError,
+			FailedAt:       dl.FailedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Redeliver
+// @Summary Riprova una consegna webhook fallita
+// @Description Rimette in coda la consegna verso l'URL corrente della sottoscrizione
+// @Tags Webhooks
+// @Security ApiKeyAuth
+// @Param id path string true "ID Dead letter"
+// @Success 202
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /api/v1/admin/webhooks/dead-letters/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	if err := h.domain.Redeliver(c.Request.Context(), c.Param("id")); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func bindWebhookRequest(c *gin.Context) (webhook.SubscriptionInput, bool) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid request"})
+		return webhook.SubscriptionInput{}, false
+	}
+	in := webhook.SubscriptionInput{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+		Active: true,
+	}
+	if req.Active != nil {
+		in.Active = *req.Active
+	}
+	return in, true
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+	switch err {
+	case webhook.ErrInvalidURL:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Webhook URL must be an absolute http or https URL"})
+	case webhook.ErrInvalidEvents:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Webhook events must be a non-empty subset of the supported events"})
+	case webhook.ErrSubscriptionNotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Webhook subscription not found"})
+	case webhook.ErrDeadLetterNotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: "Dead letter not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+	}
+}
+
+func toWebhookResponse(sub *models.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		Active:    sub.Active,
+		CreatedAt: sub.CreatedAt,
+		UpdatedAt: sub.UpdatedAt,
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAPIKey rejects requests whose X-API-Key header does not match key
+func RequireAPIKey(key string) gin.HandlerFunc {
+	expected := []byte(key)
+	return func(c *gin.Context) {
+		got := []byte(c.GetHeader(APIKeyHeader))
+		if len(expected) == 0 || subtle.ConstantTimeCompare(got, expected) != 1 {
+			AbortWithProblem(c, http.StatusUnauthorized, "a valid API key is required")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit caps the size of request bodies. Requests declaring a larger
+// Content-Length are rejected with 413 straight away; for the others the body
+// reader fails once the limit is exceeded and handlers report 413
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			AbortWithProblem(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
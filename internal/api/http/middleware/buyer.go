@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"purchase-cart-service/internal/domain/pricing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Buyer identifies the API client of a request by its X-API-Key header and
+// passes it, with its customer group, to the pricing through the request
+// context. clients maps the API keys to their buyers; requests with no key
+// or an unknown one are priced from the catalog
+func Buyer(clients map[string]pricing.Buyer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := []byte(c.GetHeader(APIKeyHeader))
+		if len(got) == 0 {
+			c.Next()
+			return
+		}
+		for key, buyer := range clients {
+			if subtle.ConstantTimeCompare(got, []byte(key)) == 1 {
+				c.Request = c.Request.WithContext(pricing.NewContext(c.Request.Context(), buyer))
+				break
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"purchase-cart-service/internal/config"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS applies the cross-origin policy: simple requests from allowed origins
+// get the Access-Control-Allow-* headers, preflight requests are answered
+// directly with 204, and requests from other origins are served without them
+func CORS(cfg config.CORS) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+		origins[strings.TrimSuffix(strings.ToLower(o), "/")] = true
+	}
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		if !anyOrigin && !origins[strings.ToLower(origin)] {
+			c.Next()
+			return
+		}
+
+		if anyOrigin && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				c.Header("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if cfg.MaxAge.Duration > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		c.Next()
+	}
+}
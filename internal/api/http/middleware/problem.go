@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AbortWithProblem writes a problem details response and stops the chain
+func AbortWithProblem(c *gin.Context, status int, detail string) {
+	b, _ := json.Marshal(Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+	c.Data(status, ProblemContentType, b)
+	c.Abort()
+}
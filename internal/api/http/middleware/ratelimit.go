@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"purchase-cart-service/internal/config"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the header carrying the client API key
+const APIKeyHeader = "X-API-Key"
+
+// idleBucketTTL is the time after which the bucket of an inactive client is dropped
+const idleBucketTTL = 10 * time.Minute
+
+// DefaultMaxBuckets caps the buckets kept by a Limiter
+const DefaultMaxBuckets = 10000
+
+// overflowKey is the bucket shared by the new clients once the cap is reached
+const overflowKey = "overflow"
+
+// Budget is the token bucket size and refill rate of a route
+type Budget struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is an in-memory token bucket limiter keyed by client and route.
+// It keeps at most maxBuckets buckets: once they are all in use, the new
+// clients share a single bucket until the idle ones expire
+type Limiter struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	maxBuckets int
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// NewLimiter keeps up to maxBuckets buckets, DefaultMaxBuckets when not positive
+func NewLimiter(maxBuckets int) *Limiter {
+	if maxBuckets <= 0 {
+		maxBuckets = DefaultMaxBuckets
+	}
+	return &Limiter{buckets: make(map[string]*bucket), maxBuckets: maxBuckets, now: time.Now}
+}
+
+// Result is the outcome of a rate limit decision
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again or, when the request
+	// is rejected, until the next request is allowed
+	Reset time.Duration
+}
+
+// Allow consumes a token from the bucket identified by key
+func (l *Limiter) Allow(key string, budget Budget) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now, false)
+	b, ok := l.buckets[key]
+	if !ok && len(l.buckets) >= l.maxBuckets {
+		l.sweep(now, true)
+		if len(l.buckets) >= l.maxBuckets {
+			key = overflowKey
+			b, ok = l.buckets[key]
+		}
+	}
+	if !ok {
+		b = &bucket{tokens: float64(budget.Burst), last: now}
+		l.buckets[key] = b
+	}
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(budget.Burst), b.tokens+elapsed*budget.RequestsPerSecond)
+	b.last = now
+
+	res := Result{Limit: budget.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+		res.Remaining = int(b.tokens)
+		res.Reset = secondsToDuration((float64(budget.Burst) - b.tokens) / budget.RequestsPerSecond)
+		return res
+	}
+	res.Reset = secondsToDuration((1 - b.tokens) / budget.RequestsPerSecond)
+	return res
+}
+
+// sweep drops the idle buckets, at most once per idleBucketTTL unless forced
+func (l *Limiter) sweep(now time.Time, force bool) {
+	if !force && now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idleBucketTTL && key != overflowKey {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RateLimit limits the requests of every client with a token bucket per
+// route budget, setting the RateLimit-* headers and answering 429 with a
+// problem document once the budget is exhausted. clients maps the known API
+// keys to the IDs of their clients, see ClientKey
+func RateLimit(cfg config.RateLimit, clients map[string]string) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limiter := NewLimiter(DefaultMaxBuckets)
+	defaultBudget := Budget{RequestsPerSecond: cfg.RequestsPerSecond, Burst: cfg.Burst}
+	routes := make(map[string]Budget, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		routes[strings.ToUpper(r.Method)+" "+r.Route] = Budget{RequestsPerSecond: r.RequestsPerSecond, Burst: r.Burst}
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		budget, ok := routes[route]
+		if !ok {
+			budget = defaultBudget
+			route = "default"
+		}
+		res := limiter.Allow(route+"|"+ClientKey(c, clients), budget)
+
+		reset := int(math.Ceil(res.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(reset))
+			AbortWithProblem(c, http.StatusTooManyRequests, "Rate limit exceeded, retry in "+strconv.Itoa(reset)+" seconds")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientKey identifies the caller by its API key, when it is one of the
+// known clients, else by client IP. Unknown keys are ignored: any caller
+// could otherwise get a fresh bucket by sending a new value
+func ClientKey(c *gin.Context, clients map[string]string) string {
+	if got := []byte(c.GetHeader(APIKeyHeader)); len(got) > 0 {
+		for key, id := range clients {
+			if subtle.ConstantTimeCompare(got, []byte(key)) == 1 {
+				return "client:" + id
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"purchase-cart-service/internal/config"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders adds the standard hardening headers to every response.
+// HSTS is only sent on HTTPS requests, as browsers ignore it over plain HTTP
+func SecurityHeaders(cfg config.Security) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge.Duration > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		if cfg.ContentTypeNosniff {
+			h.Set("X-Content-Type-Options", "nosniff")
+		}
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if hsts != "" && isHTTPS(c) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+func isHTTPS(c *gin.Context) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	// X-Forwarded-Proto is honoured only when the request came through a
+	// trusted proxy, i.e. when the client IP was resolved from X-Forwarded-For
+	return c.ClientIP() != c.RemoteIP() && strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
@@ -26,6 +26,9 @@ type IHandler interface {
 // @host localhost:8080
 // @BasePath /
 // @schemes http
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 
 // NewRouter configures and returns the HTTP engine for the service
 func NewRouter() *Router {
@@ -45,8 +48,21 @@ func NewRouter() *Router {
 	return &Router{engine: router}
 }
 
+// Use attaches global middleware; it must be called before registering the routes
+func (r *Router) Use(middleware ...gin.HandlerFunc) {
+	r.engine.Use(middleware...)
+}
+
 func (r *Router) RegisterMethods(group string, handlers ...IHandler) {
-	routes := r.engine.Group(group)
+	r.register(r.engine.Group(group), handlers)
+}
+
+// RegisterProtectedMethods registers the handlers in a group guarded by the given middleware
+func (r *Router) RegisterProtectedMethods(group string, guard gin.HandlerFunc, handlers ...IHandler) {
+	r.register(r.engine.Group(group, guard), handlers)
+}
+
+func (r *Router) register(routes *gin.RouterGroup, handlers []IHandler) {
 	for _, h := range handlers {
 		for _, handler := range h.GetHandlers() {
 			switch handler.Method {
@@ -0,0 +1,104 @@
+package certreload
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a TLS certificate loaded from disk and reloads it when the
+// certificate or key file changes, so that renewed certificates are picked
+// up without restarting the service
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// New loads the certificate pair, failing if it cannot be read or parsed
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate pair from disk and swaps it in
+func (r *Reloader) Reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	return nil
+}
+
+// GetCertificate is meant to be used as tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch checks the files every interval and reloads them when their
+// modification time changes, until ctx is done. A pair that fails to load
+// (e.g. key and certificate written at different times) is retried at the
+// next tick while the previous certificate keeps being served
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				log.Printf("TLS certificate reload failed: %v", err)
+				continue
+			}
+			log.Printf("TLS certificate reloaded from %s", r.certFile)
+		}
+	}
+}
+
+func (r *Reloader) changed() bool {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)
+}
+
+func (r *Reloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("TLS certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("TLS key: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
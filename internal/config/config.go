@@ -1,51 +1,576 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration values
+//
+// Every field can be set (in increasing order of precedence) by the defaults,
+// the configuration file, the environment variable in the `env` tag and the
+// command line flag in the `flag` tag. Fields tagged `secret:"true"` are
+// redacted when the configuration is printed.
 type Config struct {
-	VATRate     float64
-	ServiceName string
-	WebApp      Server
-	Database    Database
+	VATRate        float64        `yaml:"VATRate" env:"VAT_RATE"`
+	ServiceName    string         `yaml:"ServiceName" env:"SERVICE_NAME" flag:"service-name" usage:"service name"`
+	WebApp         Server         `yaml:"WebApp"`
+	GRPC           GRPC           `yaml:"GRPC"`
+	GraphQL        GraphQL        `yaml:"GraphQL"`
+	Database       Database       `yaml:"Database"`
+	RateLimit      RateLimit      `yaml:"RateLimit"`
+	Limits         Limits         `yaml:"Limits"`
+	CORS           CORS           `yaml:"CORS"`
+	Security       Security       `yaml:"Security"`
+	Admin          Admin          `yaml:"Admin"`
+	Webhooks       Webhooks       `yaml:"Webhooks"`
+	Outbox         Outbox         `yaml:"Outbox"`
+	Payments       Payments       `yaml:"Payments"`
+	Invoicing      Invoicing      `yaml:"Invoicing"`
+	ReverseCharge  ReverseCharge  `yaml:"ReverseCharge"`
+	SalesTax       SalesTax       `yaml:"SalesTax"`
+	VATTerritories VATTerritories `yaml:"VATTerritories"`
+	Catalog        Catalog        `yaml:"Catalog"`
+	Rounding       Rounding       `yaml:"Rounding"`
+	Pricing        Pricing        `yaml:"Pricing"`
 }
 type Server struct {
-	HostName string
-	Port     int
+	HostName          string   `yaml:"HostName" env:"WEBAPP_HOSTNAME" flag:"host" usage:"HTTP server bind address"`
+	Port              int      `yaml:"Port" env:"WEBAPP_PORT" flag:"port" usage:"HTTP server port"`
+	ReadTimeout       Duration `yaml:"ReadTimeout" env:"WEBAPP_READ_TIMEOUT"`
+	ReadHeaderTimeout Duration `yaml:"ReadHeaderTimeout" env:"WEBAPP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      Duration `yaml:"WriteTimeout" env:"WEBAPP_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `yaml:"IdleTimeout" env:"WEBAPP_IDLE_TIMEOUT"`
+	// ShutdownTimeout is the deadline for draining in-flight requests on shutdown
+	ShutdownTimeout Duration `yaml:"ShutdownTimeout" env:"WEBAPP_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"deadline for graceful shutdown"`
+	// DrainDelay is how long the server keeps serving, reporting not ready,
+	// before it stops accepting connections, so that load balancers see the
+	// 503 of /readyz and stop routing to it
+	DrainDelay Duration `yaml:"DrainDelay" env:"WEBAPP_DRAIN_DELAY" flag:"drain-delay" usage:"time served as not ready before shutdown"`
+	// TrustedProxies are the proxies whose X-Forwarded-For header is honoured
+	// when resolving the client IP; when empty the remote address is used
+	TrustedProxies []string `yaml:"TrustedProxies" env:"WEBAPP_TRUSTED_PROXIES"`
+	TLS            TLS      `yaml:"TLS"`
+}
+
+// GRPC configures the gRPC API, served on its own port next to the HTTP one
+type GRPC struct {
+	Enabled  bool   `yaml:"Enabled" env:"GRPC_ENABLED" flag:"grpc" usage:"serve the gRPC API"`
+	HostName string `yaml:"HostName" env:"GRPC_HOSTNAME"`
+	Port     int    `yaml:"Port" env:"GRPC_PORT" flag:"grpc-port" usage:"gRPC server port"`
+}
+
+// GraphQL configures the /graphql endpoint and the limits on accepted queries
+type GraphQL struct {
+	Enabled       bool `yaml:"Enabled" env:"GRAPHQL_ENABLED"`
+	MaxComplexity int  `yaml:"MaxComplexity" env:"GRAPHQL_MAX_COMPLEXITY"`
+	MaxDepth      int  `yaml:"MaxDepth" env:"GRAPHQL_MAX_DEPTH"`
+}
+
+// TLS enables native HTTPS. Certificate and key are reloaded when the files
+// change on disk, checked every ReloadInterval
+type TLS struct {
+	Enabled        bool     `yaml:"Enabled" env:"WEBAPP_TLS_ENABLED" flag:"tls" usage:"serve HTTPS"`
+	CertFile       string   `yaml:"CertFile" env:"WEBAPP_TLS_CERT_FILE" flag:"tls-cert" usage:"TLS certificate file (PEM)"`
+	KeyFile        string   `yaml:"KeyFile" env:"WEBAPP_TLS_KEY_FILE" flag:"tls-key" usage:"TLS private key file (PEM)"`
+	ReloadInterval Duration `yaml:"ReloadInterval" env:"WEBAPP_TLS_RELOAD_INTERVAL"`
 }
 type Database struct {
-	Type     string
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Name     string
-}
-
-// Load loads configuration from environment variables
-// and applies sensible defaults for local development
-func Load() *Config {
-	var cfg Config
-	//get directory of executable
-	exePath, err := os.Executable()
-	if err != nil {
-		log.Fatal(err)
-	}
-	dir := filepath.Dir(exePath)
-	bConfig, err := os.ReadFile(fmt.Sprintf("%s/config.json", dir))
-	if err != nil {
-		panic(fmt.Sprintf("Error on reading config file. Error:%s", err.Error()))
-	}
-
-	err = json.Unmarshal(bConfig, &cfg)
-	if err != nil {
-		panic(fmt.Sprintf("Error on unmarshalling configuration. Error:%s", err.Error()))
-	}
-	return &cfg
+	Type     string `yaml:"Type" env:"DATABASE_TYPE" flag:"db-type" usage:"storage type (InMemory)"`
+	Host     string `yaml:"Host" env:"DATABASE_HOST" flag:"db-host" usage:"database host"`
+	Port     int    `yaml:"Port" env:"DATABASE_PORT" flag:"db-port" usage:"database port"`
+	Username string `yaml:"Username" env:"DATABASE_USERNAME" flag:"db-username" usage:"database user"`
+	// User is the former key of Username, still accepted in the configuration
+	// files written before the rename; Username wins when both are set
+	User     string `yaml:"User" json:"User,omitempty"`
+	Password string `yaml:"Password" env:"DATABASE_PASSWORD" secret:"true"`
+	Name     string `yaml:"Name" env:"DATABASE_NAME" flag:"db-name" usage:"database name"`
+	// EventSourcing stores every order change as an immutable event
+	EventSourcing EventSourcing `yaml:"EventSourcing"`
+}
+
+// EventSourcing configures the event-sourced order repository. A snapshot is
+// taken every SnapshotInterval events to bound the replay of long streams
+type EventSourcing struct {
+	Enabled          bool `yaml:"Enabled" env:"DATABASE_EVENT_SOURCING_ENABLED"`
+	SnapshotInterval int  `yaml:"SnapshotInterval" env:"DATABASE_EVENT_SOURCING_SNAPSHOT_INTERVAL"`
+}
+
+// RateLimit configures the per-client token bucket limiter. Clients are
+// identified by the API key of the known clients (Pricing.Clients and the
+// admin key), else by IP address
+type RateLimit struct {
+	Enabled bool `yaml:"Enabled" env:"RATELIMIT_ENABLED"`
+	// RequestsPerSecond and Burst are the budget of routes without a specific entry
+	RequestsPerSecond float64          `yaml:"RequestsPerSecond" env:"RATELIMIT_REQUESTS_PER_SECOND"`
+	Burst             int              `yaml:"Burst" env:"RATELIMIT_BURST"`
+	Routes            []RouteRateLimit `yaml:"Routes"`
+}
+
+// RouteRateLimit is the budget of a single route, identified by method and
+// route pattern as registered in the router (e.g. PUT /api/v1/orders)
+type RouteRateLimit struct {
+	Method            string  `yaml:"Method"`
+	Route             string  `yaml:"Route"`
+	RequestsPerSecond float64 `yaml:"RequestsPerSecond"`
+	Burst             int     `yaml:"Burst"`
+}
+
+// Limits holds hard limits on the size of incoming requests
+type Limits struct {
+	MaxBodyBytes  int64 `yaml:"MaxBodyBytes" env:"LIMITS_MAX_BODY_BYTES"`
+	MaxOrderItems int   `yaml:"MaxOrderItems" env:"LIMITS_MAX_ORDER_ITEMS"`
+}
+
+// CORS configures the cross-origin resource sharing policy
+type CORS struct {
+	Enabled bool `yaml:"Enabled" env:"CORS_ENABLED"`
+	// AllowedOrigins lists the allowed origins; "*" allows any origin
+	AllowedOrigins   []string `yaml:"AllowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `yaml:"AllowedMethods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `yaml:"AllowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `yaml:"ExposedHeaders" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `yaml:"AllowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           Duration `yaml:"MaxAge" env:"CORS_MAX_AGE"`
+}
+
+// Security configures the security headers added to every response.
+// Empty values disable the corresponding header
+type Security struct {
+	// HSTSMaxAge is sent only on HTTPS requests (native TLS or X-Forwarded-Proto)
+	HSTSMaxAge            Duration `yaml:"HSTSMaxAge" env:"SECURITY_HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool     `yaml:"HSTSIncludeSubdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+	FrameOptions          string   `yaml:"FrameOptions" env:"SECURITY_FRAME_OPTIONS"`
+	ContentTypeNosniff    bool     `yaml:"ContentTypeNosniff" env:"SECURITY_CONTENT_TYPE_NOSNIFF"`
+	ReferrerPolicy        string   `yaml:"ReferrerPolicy" env:"SECURITY_REFERRER_POLICY"`
+	ContentSecurityPolicy string   `yaml:"ContentSecurityPolicy" env:"SECURITY_CONTENT_SECURITY_POLICY"`
+}
+
+// Admin configures the administrative API under /api/v1/admin, which is
+// mounted only when APIKey is set. Clients send the key in the X-API-Key header
+type Admin struct {
+	APIKey string `yaml:"APIKey" env:"ADMIN_API_KEY" secret:"true"`
+}
+
+// Webhooks configures the asynchronous delivery of order events to the
+// registered subscriptions. Failed deliveries are retried with exponential
+// backoff, from InitialBackoff up to MaxBackoff, and dead-lettered after MaxAttempts
+type Webhooks struct {
+	Enabled        bool     `yaml:"Enabled" env:"WEBHOOKS_ENABLED"`
+	Workers        int      `yaml:"Workers" env:"WEBHOOKS_WORKERS"`
+	QueueSize      int      `yaml:"QueueSize" env:"WEBHOOKS_QUEUE_SIZE"`
+	MaxAttempts    int      `yaml:"MaxAttempts" env:"WEBHOOKS_MAX_ATTEMPTS"`
+	InitialBackoff Duration `yaml:"InitialBackoff" env:"WEBHOOKS_INITIAL_BACKOFF"`
+	MaxBackoff     Duration `yaml:"MaxBackoff" env:"WEBHOOKS_MAX_BACKOFF"`
+	Timeout        Duration `yaml:"Timeout" env:"WEBHOOKS_TIMEOUT"`
+}
+
+// Outbox configures the relay forwarding the domain events, stored in the
+// outbox together with the changes that raised them, to the sinks. The
+// in-process event bus is always a sink; the others are enabled by their
+// settings. A message a sink keeps failing is dead-lettered after MaxAttempts passes
+type Outbox struct {
+	PollInterval Duration `yaml:"PollInterval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int      `yaml:"BatchSize" env:"OUTBOX_BATCH_SIZE"`
+	MaxAttempts  int      `yaml:"MaxAttempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	// LogSink writes every event to the service log
+	LogSink bool `yaml:"LogSink" env:"OUTBOX_LOG_SINK"`
+	// FileSink is the path of a JSON lines file the events are appended to
+	FileSink string   `yaml:"FileSink" env:"OUTBOX_FILE_SINK"`
+	HTTPSink HTTPSink `yaml:"HTTPSink"`
+}
+
+// HTTPSink POSTs every event to URL
+type HTTPSink struct {
+	URL     string   `yaml:"URL" env:"OUTBOX_HTTP_SINK_URL"`
+	Timeout Duration `yaml:"Timeout" env:"OUTBOX_HTTP_SINK_TIMEOUT"`
+}
+
+// Payments configures the payment provider. Orders are charged in Currency;
+// with AutoCapture a payment is captured right after the authorization
+// unless the request says otherwise
+type Payments struct {
+	Gateway     string `yaml:"Gateway" env:"PAYMENTS_GATEWAY"`
+	Currency    string `yaml:"Currency" env:"PAYMENTS_CURRENCY"`
+	AutoCapture bool   `yaml:"AutoCapture" env:"PAYMENTS_AUTO_CAPTURE"`
+}
+
+// Invoicing configures the numbering of the invoices, issued when an order is
+// paid. Every country has a gap-free sequence per fiscal year; NumberFormat
+// must contain the {country}, {year} and {seq} placeholders, the latter padded
+// with zeros to SequenceDigits
+type Invoicing struct {
+	NumberFormat   string `yaml:"NumberFormat" env:"INVOICING_NUMBER_FORMAT"`
+	SequenceDigits int    `yaml:"SequenceDigits" env:"INVOICING_SEQUENCE_DIGITS"`
+	// FiscalYearStartMonth is the first month of the fiscal year, 1 to 12
+	FiscalYearStartMonth int `yaml:"FiscalYearStartMonth" env:"INVOICING_FISCAL_YEAR_START_MONTH"`
+	// TimeZone is the IANA zone the issue dates are read in
+	TimeZone string `yaml:"TimeZone" env:"INVOICING_TIME_ZONE"`
+	Seller   Seller `yaml:"Seller"`
+}
+
+// Seller is the issuer printed on the invoices
+type Seller struct {
+	Name        string `yaml:"Name" env:"INVOICING_SELLER_NAME"`
+	VATID       string `yaml:"VATID" env:"INVOICING_SELLER_VAT_ID"`
+	Email       string `yaml:"Email" env:"INVOICING_SELLER_EMAIL"`
+	Line1       string `yaml:"Line1" env:"INVOICING_SELLER_LINE1"`
+	Line2       string `yaml:"Line2" env:"INVOICING_SELLER_LINE2"`
+	City        string `yaml:"City" env:"INVOICING_SELLER_CITY"`
+	PostalCode  string `yaml:"PostalCode" env:"INVOICING_SELLER_POSTAL_CODE"`
+	Region      string `yaml:"Region" env:"INVOICING_SELLER_REGION"`
+	CountryCode string `yaml:"CountryCode" env:"INVOICING_SELLER_COUNTRY_CODE"`
+}
+
+// ReverseCharge configures the EU reverse charge: orders of business buyers
+// in another member state, whose VAT ID is confirmed by Verifier, carry no
+// VAT. The seller country is Invoicing.Seller.CountryCode
+type ReverseCharge struct {
+	Enabled  bool   `yaml:"Enabled" env:"REVERSE_CHARGE_ENABLED"`
+	Verifier string `yaml:"Verifier" env:"REVERSE_CHARGE_VERIFIER"`
+	// Registered lists the VAT IDs the Stub verifier confirms; it reports
+	// every other number as not registered
+	Registered []string `yaml:"Registered"`
+}
+
+// SalesTax configures the US sales tax. Without RatesFile, a CSV table of
+// the state and local rates by ZIP code, US orders are taxed at the flat
+// VAT rate of "US". OriginState and OriginPostalCode locate the warehouse,
+// whose rates apply to sales within origin-based states
+type SalesTax struct {
+	RatesFile        string `yaml:"RatesFile" env:"SALES_TAX_RATES_FILE"`
+	OriginState      string `yaml:"OriginState" env:"SALES_TAX_ORIGIN_STATE"`
+	OriginPostalCode string `yaml:"OriginPostalCode" env:"SALES_TAX_ORIGIN_POSTAL_CODE"`
+}
+
+// VATTerritories configures the parts of a country with their own VAT
+// rules, such as the Canary Islands or Northern Ireland, read from the CSV
+// File; without it the country rates apply everywhere
+type VATTerritories struct {
+	File string `yaml:"File" env:"VAT_TERRITORIES_FILE"`
+}
+
+// Catalog configures the product catalog. PriceMode tells whether the
+// catalog prices are net ("net", VAT added on top) or include VAT ("gross");
+// a product can override it
+type Catalog struct {
+	PriceMode string `yaml:"PriceMode" env:"CATALOG_PRICE_MODE"`
+}
+
+// Rounding configures how the VAT of an order is rounded to the cent: Level
+// is "unit", "line" or "total" (once per rate), Method "half-up",
+// "half-even" or "bankers". Countries overrides them for single countries
+type Rounding struct {
+	Level     string            `yaml:"Level" env:"ROUNDING_LEVEL"`
+	Method    string            `yaml:"Method" env:"ROUNDING_METHOD"`
+	Countries []CountryRounding `yaml:"Countries"`
+}
+
+// CountryRounding is the rounding of the orders of a country
+type CountryRounding struct {
+	CountryCode string `yaml:"CountryCode"`
+	Level       string `yaml:"Level"`
+	Method      string `yaml:"Method"`
+}
+
+// Pricing configures the API clients entitled to the price lists assigned
+// to them or to their customer group. A client is recognized by the APIKey
+// it sends in the X-API-Key header
+type Pricing struct {
+	Clients []PricingClient `yaml:"Clients"`
+}
+
+// PricingClient is an API client; CustomerGroup is optional
+type PricingClient struct {
+	ID            string `yaml:"ID"`
+	APIKey        string `yaml:"APIKey" secret:"true"`
+	CustomerGroup string `yaml:"CustomerGroup"`
+}
+
+// RoundingLevels lists the supported values of Rounding.Level
+var RoundingLevels = []string{"unit", "line", "total"}
+
+// RoundingMethods lists the supported values of Rounding.Method
+var RoundingMethods = []string{"half-up", "half-even", "bankers"}
+
+// PriceModes lists the supported values of Catalog.PriceMode
+var PriceModes = []string{"net", "gross"}
+
+// VATIDVerifiers lists the supported values of ReverseCharge.Verifier
+var VATIDVerifiers = []string{"Stub"}
+
+// PaymentGateways lists the supported values of Payments.Gateway
+var PaymentGateways = []string{"Fake"}
+
+// DatabaseTypes lists the supported values of Database.Type
+var DatabaseTypes = []string{"InMemory"}
+
+// Default returns the configuration used when no other source sets a value
+func Default() *Config {
+	return &Config{
+		ServiceName: "purchase-cart",
+		WebApp: Server{
+			HostName:          "0.0.0.0",
+			Port:              8080,
+			ReadTimeout:       Duration{15 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{60 * time.Second},
+			ShutdownTimeout:   Duration{20 * time.Second},
+			DrainDelay:        Duration{5 * time.Second},
+			TLS: TLS{
+				ReloadInterval: Duration{time.Minute},
+			},
+		},
+		GRPC: GRPC{
+			Enabled:  true,
+			HostName: "0.0.0.0",
+			Port:     9090,
+		},
+		GraphQL: GraphQL{
+			Enabled:       true,
+			MaxComplexity: 1000,
+			MaxDepth:      8,
+		},
+		Database: Database{
+			Type: "InMemory",
+			EventSourcing: EventSourcing{
+				Enabled:          true,
+				SnapshotInterval: 50,
+			},
+		},
+		RateLimit: RateLimit{
+			Enabled:           true,
+			RequestsPerSecond: 10,
+			Burst:             20,
+			Routes: []RouteRateLimit{
+				{Method: "PUT", Route: "/api/v1/orders", RequestsPerSecond: 2, Burst: 5},
+			},
+		},
+		Limits: Limits{
+			MaxBodyBytes:  1 << 20,
+			MaxOrderItems: 100,
+		},
+		CORS: CORS{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key"},
+			ExposedHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			MaxAge:         Duration{10 * time.Minute},
+		},
+		Security: Security{
+			HSTSMaxAge:            Duration{365 * 24 * time.Hour},
+			HSTSIncludeSubdomains: true,
+			FrameOptions:          "DENY",
+			ContentTypeNosniff:    true,
+			ReferrerPolicy:        "no-referrer",
+		},
+		Webhooks: Webhooks{
+			Enabled:        true,
+			Workers:        4,
+			QueueSize:      1000,
+			MaxAttempts:    8,
+			InitialBackoff: Duration{time.Second},
+			MaxBackoff:     Duration{5 * time.Minute},
+			Timeout:        Duration{10 * time.Second},
+		},
+		Payments: Payments{
+			Gateway:     "Fake",
+			Currency:    "EUR",
+			AutoCapture: true,
+		},
+		Invoicing: Invoicing{
+			NumberFormat:         "{country}-{year}-{seq}",
+			SequenceDigits:       6,
+			FiscalYearStartMonth: 1,
+			TimeZone:             "UTC",
+			Seller: Seller{
+				Name: "Purchase Cart",
+			},
+		},
+		ReverseCharge: ReverseCharge{
+			Verifier: "Stub",
+		},
+		Catalog: Catalog{
+			PriceMode: "net",
+		},
+		Rounding: Rounding{
+			Level:  "line",
+			Method: "half-up",
+		},
+		Outbox: Outbox{
+			PollInterval: Duration{500 * time.Millisecond},
+			BatchSize:    100,
+			MaxAttempts:  10,
+			HTTPSink: HTTPSink{
+				Timeout: Duration{10 * time.Second},
+			},
+		},
+	}
+}
+
+// Validate checks the configuration and reports every invalid field
+func (c *Config) Validate() error {
+	var errs []error
+	if c.ServiceName == "" {
+		errs = append(errs, errors.New("ServiceName: must not be empty"))
+	}
+	if c.VATRate < 0 || c.VATRate > 1 {
+		errs = append(errs, fmt.Errorf("VATRate: must be between 0 and 1, got %v", c.VATRate))
+	}
+	if c.WebApp.Port < 1 || c.WebApp.Port > 65535 {
+		errs = append(errs, fmt.Errorf("WebApp.Port: must be between 1 and 65535, got %d", c.WebApp.Port))
+	}
+	if c.GRPC.Enabled {
+		if c.GRPC.Port < 1 || c.GRPC.Port > 65535 {
+			errs = append(errs, fmt.Errorf("GRPC.Port: must be between 1 and 65535, got %d", c.GRPC.Port))
+		} else if c.GRPC.Port == c.WebApp.Port {
+			errs = append(errs, fmt.Errorf("GRPC.Port: must differ from WebApp.Port (%d)", c.WebApp.Port))
+		}
+	}
+	if c.GraphQL.Enabled && (c.GraphQL.MaxComplexity < 1 || c.GraphQL.MaxDepth < 1) {
+		errs = append(errs, errors.New("GraphQL: MaxComplexity and MaxDepth must be positive"))
+	}
+	for name, d := range map[string]Duration{
+		"WebApp.ReadTimeout":       c.WebApp.ReadTimeout,
+		"WebApp.ReadHeaderTimeout": c.WebApp.ReadHeaderTimeout,
+		"WebApp.WriteTimeout":      c.WebApp.WriteTimeout,
+		"WebApp.IdleTimeout":       c.WebApp.IdleTimeout,
+		"WebApp.ShutdownTimeout":   c.WebApp.ShutdownTimeout,
+		"WebApp.DrainDelay":        c.WebApp.DrainDelay,
+	} {
+		if d.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", name, d))
+		}
+	}
+	if !contains(DatabaseTypes, c.Database.Type) {
+		errs = append(errs, fmt.Errorf("Database.Type: unsupported value %q, expected one of %v", c.Database.Type, DatabaseTypes))
+	}
+	if c.Database.EventSourcing.Enabled && c.Database.EventSourcing.SnapshotInterval < 1 {
+		errs = append(errs, fmt.Errorf("Database.EventSourcing.SnapshotInterval: must be positive, got %d", c.Database.EventSourcing.SnapshotInterval))
+	}
+	if c.Database.Type != "" && c.Database.Type != "InMemory" {
+		if c.Database.Host == "" {
+			errs = append(errs, errors.New("Database.Host: required when Database.Type is not InMemory"))
+		}
+		if c.Database.Port < 1 || c.Database.Port > 65535 {
+			errs = append(errs, fmt.Errorf("Database.Port: must be between 1 and 65535, got %d", c.Database.Port))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, errors.New("Database.Name: required when Database.Type is not InMemory"))
+		}
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RequestsPerSecond <= 0 || c.RateLimit.Burst < 1 {
+			errs = append(errs, errors.New("RateLimit: RequestsPerSecond must be positive and Burst at least 1"))
+		}
+		for i, r := range c.RateLimit.Routes {
+			if r.Method == "" || r.Route == "" {
+				errs = append(errs, fmt.Errorf("RateLimit.Routes[%d]: Method and Route are required", i))
+			}
+			if r.RequestsPerSecond <= 0 || r.Burst < 1 {
+				errs = append(errs, fmt.Errorf("RateLimit.Routes[%d]: RequestsPerSecond must be positive and Burst at least 1", i))
+			}
+		}
+	}
+	if c.WebApp.TLS.Enabled && (c.WebApp.TLS.CertFile == "" || c.WebApp.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("WebApp.TLS: CertFile and KeyFile are required when TLS is enabled"))
+	}
+	if c.CORS.Enabled {
+		if len(c.CORS.AllowedOrigins) == 0 {
+			errs = append(errs, errors.New("CORS.AllowedOrigins: at least one origin is required when CORS is enabled"))
+		}
+		if c.CORS.AllowCredentials && contains(c.CORS.AllowedOrigins, "*") {
+			errs = append(errs, errors.New("CORS: AllowCredentials cannot be combined with the \"*\" origin"))
+		}
+	}
+	if c.Limits.MaxBodyBytes <= 0 {
+		errs = append(errs, fmt.Errorf("Limits.MaxBodyBytes: must be positive, got %d", c.Limits.MaxBodyBytes))
+	}
+	if c.Limits.MaxOrderItems <= 0 {
+		errs = append(errs, fmt.Errorf("Limits.MaxOrderItems: must be positive, got %d", c.Limits.MaxOrderItems))
+	}
+	if c.Webhooks.Enabled {
+		if c.Webhooks.Workers < 1 || c.Webhooks.QueueSize < 1 || c.Webhooks.MaxAttempts < 1 {
+			errs = append(errs, errors.New("Webhooks: Workers, QueueSize and MaxAttempts must be positive"))
+		}
+		if c.Webhooks.InitialBackoff.Duration <= 0 || c.Webhooks.MaxBackoff.Duration < c.Webhooks.InitialBackoff.Duration {
+			errs = append(errs, errors.New("Webhooks: InitialBackoff must be positive and not greater than MaxBackoff"))
+		}
+		if c.Webhooks.Timeout.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("Webhooks.Timeout: must be positive, got %s", c.Webhooks.Timeout))
+		}
+	}
+	if !contains(PaymentGateways, c.Payments.Gateway) {
+		errs = append(errs, fmt.Errorf("Payments.Gateway: unsupported value %q, expected one of %v", c.Payments.Gateway, PaymentGateways))
+	}
+	if len(c.Payments.Currency) != 3 {
+		errs = append(errs, fmt.Errorf("Payments.Currency: must be an ISO 4217 code, got %q", c.Payments.Currency))
+	}
+	for _, placeholder := range []string{"{country}", "{year}", "{seq}"} {
+		if !strings.Contains(c.Invoicing.NumberFormat, placeholder) {
+			errs = append(errs, fmt.Errorf("Invoicing.NumberFormat: must contain %s, got %q", placeholder, c.Invoicing.NumberFormat))
+		}
+	}
+	if c.Invoicing.SequenceDigits < 1 || c.Invoicing.SequenceDigits > 18 {
+		errs = append(errs, fmt.Errorf("Invoicing.SequenceDigits: must be between 1 and 18, got %d", c.Invoicing.SequenceDigits))
+	}
+	if c.Invoicing.FiscalYearStartMonth < 1 || c.Invoicing.FiscalYearStartMonth > 12 {
+		errs = append(errs, fmt.Errorf("Invoicing.FiscalYearStartMonth: must be between 1 and 12, got %d", c.Invoicing.FiscalYearStartMonth))
+	}
+	if c.Invoicing.Seller.Name == "" {
+		errs = append(errs, errors.New("Invoicing.Seller.Name: must not be empty"))
+	}
+	if _, err := time.LoadLocation(c.Invoicing.TimeZone); err != nil {
+		errs = append(errs, fmt.Errorf("Invoicing.TimeZone: %v", err))
+	}
+	if !contains(VATIDVerifiers, c.ReverseCharge.Verifier) {
+		errs = append(errs, fmt.Errorf("ReverseCharge.Verifier: unsupported value %q, expected one of %v", c.ReverseCharge.Verifier, VATIDVerifiers))
+	}
+	if c.ReverseCharge.Enabled && c.Invoicing.Seller.CountryCode == "" {
+		errs = append(errs, errors.New("Invoicing.Seller.CountryCode: required when ReverseCharge is enabled"))
+	}
+	if c.SalesTax.RatesFile != "" && (c.SalesTax.OriginState == "") != (c.SalesTax.OriginPostalCode == "") {
+		errs = append(errs, errors.New("SalesTax: OriginState and OriginPostalCode must be set together"))
+	}
+	if !contains(PriceModes, c.Catalog.PriceMode) {
+		errs = append(errs, fmt.Errorf("Catalog.PriceMode: unsupported value %q, expected one of %v", c.Catalog.PriceMode, PriceModes))
+	}
+	if !contains(RoundingLevels, c.Rounding.Level) || !contains(RoundingMethods, c.Rounding.Method) {
+		errs = append(errs, fmt.Errorf("Rounding: unsupported Level %q or Method %q, expected one of %v and %v", c.Rounding.Level, c.Rounding.Method, RoundingLevels, RoundingMethods))
+	}
+	for i, r := range c.Rounding.Countries {
+		if len(r.CountryCode) != 2 || !contains(RoundingLevels, r.Level) || !contains(RoundingMethods, r.Method) {
+			errs = append(errs, fmt.Errorf("Rounding.Countries[%d]: CountryCode, a supported Level and Method are required", i))
+		}
+	}
+	keys := make(map[string]bool)
+	for i, client := range c.Pricing.Clients {
+		if client.ID == "" || client.APIKey == "" {
+			errs = append(errs, fmt.Errorf("Pricing.Clients[%d]: ID and APIKey are required", i))
+		} else if keys[client.APIKey] || client.APIKey == c.Admin.APIKey {
+			errs = append(errs, fmt.Errorf("Pricing.Clients[%d]: APIKey must be unique and differ from Admin.APIKey", i))
+		}
+		keys[client.APIKey] = true
+	}
+	if c.Outbox.PollInterval.Duration <= 0 || c.Outbox.BatchSize < 1 || c.Outbox.MaxAttempts < 1 {
+		errs = append(errs, errors.New("Outbox: PollInterval, BatchSize and MaxAttempts must be positive"))
+	}
+	if c.Outbox.HTTPSink.URL != "" {
+		if u, err := url.Parse(c.Outbox.HTTPSink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("Outbox.HTTPSink.URL: must be an absolute http or https URL, got %q", c.Outbox.HTTPSink.URL))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration wraps time.Duration so that it can be expressed in the
+// configuration file as a string ("15s", "1m30s") or as seconds (15)
+type Duration struct {
+	time.Duration
+}
+
+// ParseDuration parses a duration string or a number of seconds
+func ParseDuration(s string) (Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return Duration{time.Duration(seconds * float64(time.Second))}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return Duration{}, fmt.Errorf("invalid duration %q", s)
+	}
+	return Duration{d}, nil
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case float64:
+		d.Duration = time.Duration(value * float64(time.Second))
+	case string:
+		parsed, err := ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	default:
+		return fmt.Errorf("invalid duration %s", string(b))
+	}
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// OrDefault returns the configured duration, or def when it is not set
+func (d Duration) OrDefault(def time.Duration) time.Duration {
+	if d.Duration <= 0 {
+		return def
+	}
+	return d.Duration
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPathEnv is the environment variable holding the configuration file path
+const ConfigPathEnv = "CONFIG_PATH"
+
+const redacted = "******"
+
+// Flags holds the command line options of the service
+type Flags struct {
+	// ConfigPath is the configuration file; when empty CONFIG_PATH is used,
+	// then config.json next to the executable or in the working directory
+	ConfigPath string
+	// PrintConfig asks to print the effective configuration and exit
+	PrintConfig bool
+	// overrides holds the values of the configuration flags explicitly set
+	overrides map[string]string
+}
+
+var durationType = reflect.TypeOf(Duration{})
+
+// ParseFlags parses the command line arguments. Besides --config and
+// --print-config, every configuration field with a `flag` tag is accepted
+func ParseFlags(name string, args []string) (*Flags, error) {
+	flags := &Flags{overrides: map[string]string{}}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&flags.ConfigPath, "config", "", "path of the configuration file (JSON or YAML)")
+	fs.BoolVar(&flags.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	_ = walk(reflect.ValueOf(Default()).Elem(), "", func(path string, field reflect.StructField, _ reflect.Value) error {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return nil
+		}
+		fs.Func(name, field.Tag.Get("usage"), func(v string) error {
+			flags.overrides[path] = v
+			return nil
+		})
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return flags, nil
+}
+
+// Load builds the configuration applying, in order, the defaults, the
+// configuration file, the environment variables and the command line flags,
+// then validates the result
+func Load(flags *Flags) (*Config, error) {
+	if flags == nil {
+		flags = &Flags{}
+	}
+	cfg := Default()
+
+	path, required := resolvePath(flags.ConfigPath)
+	if path != "" {
+		if err := loadFile(path, cfg, required); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := applyOverrides(cfg, flags.overrides); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// resolvePath returns the configuration file to read and whether it must exist
+func resolvePath(path string) (string, bool) {
+	if path != "" {
+		return path, true
+	}
+	if env := os.Getenv(ConfigPathEnv); env != "" {
+		return env, true
+	}
+	if exePath, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exePath), "config.json")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, false
+		}
+	}
+	if _, err := os.Stat("config.json"); err == nil {
+		return "config.json", false
+	}
+	return "", false
+}
+
+func loadFile(path string, cfg *Config, required bool) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+	if cfg.Database.Username == "" {
+		cfg.Database.Username = cfg.Database.User
+	}
+	cfg.Database.User = ""
+	return nil
+}
+
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return walk(reflect.ValueOf(cfg).Elem(), "", func(path string, field reflect.StructField, value reflect.Value) error {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return nil
+		}
+		raw, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("environment variable %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+func applyOverrides(cfg *Config, overrides map[string]string) error {
+	return walk(reflect.ValueOf(cfg).Elem(), "", func(path string, field reflect.StructField, value reflect.Value) error {
+		raw, ok := overrides[path]
+		if !ok {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("flag --%s: %w", field.Tag.Get("flag"), err)
+		}
+		return nil
+	})
+}
+
+// Print writes the configuration as indented JSON with secrets redacted
+func Print(w io.Writer, cfg *Config) error {
+	// a deep copy, so that redacting the secrets in slices leaves cfg intact
+	var clone Config
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &clone); err != nil {
+		return err
+	}
+	_ = walk(reflect.ValueOf(&clone).Elem(), "", func(path string, field reflect.StructField, value reflect.Value) error {
+		if field.Tag.Get("secret") == "true" && value.Kind() == reflect.String && value.String() != "" {
+			value.SetString(redacted)
+		}
+		return nil
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(clone)
+}
+
+// walk visits every leaf field of a configuration struct, passing its dotted path
+func walk(v reflect.Value, prefix string, fn func(path string, field reflect.StructField, value reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && value.Type() != durationType {
+			if err := walk(value, path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		// the elements of a list of structs, such as Pricing.Clients, are
+		// visited as Pricing.Clients[0].ID
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct {
+			for j := 0; j < value.Len(); j++ {
+				if err := walk(value.Index(j), fmt.Sprintf("%s[%d]", path, j), fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := fn(path, field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		d, err := ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(d))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		value.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Service manages the bundles of the catalog
+type Service struct {
+	repo        repository.BundleRepository
+	productRepo repository.ProductRepository
+}
+
+func NewService(repo repository.BundleRepository, productRepo repository.ProductRepository) *Service {
+	return &Service{repo: repo, productRepo: productRepo}
+}
+
+var ErrBundleNotFound = errors.New("bundle not found")
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// Get returns the bundle with the given ID, nil when missing or on a nil
+// Service
+func (s *Service) Get(ctx context.Context, id string) (*models.Bundle, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetAll returns the bundles by ID
+func (s *Service) GetAll(ctx context.Context) ([]models.Bundle, error) {
+	bundles, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(bundles, func(a, b models.Bundle) int { return strings.Compare(a.ID, b.ID) })
+	return bundles, nil
+}
+
+// Save creates or replaces a bundle. The bundle needs a name, a positive
+// price, a known price mode and two units or more; each component a product
+// of the catalog, by SKU for the products with variants, listed once
+func (s *Service) Save(ctx context.Context, bundle *models.Bundle) error {
+	if bundle.ID == "" || bundle.Name == "" {
+		return fmt.Errorf("%w: ID and name are required", ErrInvalidBundle)
+	}
+	if bundle.Price <= 0 {
+		return fmt.Errorf("%w: price must be greater than zero", ErrInvalidBundle)
+	}
+	if bundle.PriceMode != "" && bundle.PriceMode != models.PriceModeNet && bundle.PriceMode != models.PriceModeGross {
+		return fmt.Errorf("%w: unknown price mode %q", ErrInvalidBundle, bundle.PriceMode)
+	}
+	units := 0
+	seen := make(map[string]bool)
+	for i, c := range bundle.Components {
+		if c.Quantity <= 0 {
+			return fmt.Errorf("%w: component %d quantity must be greater than zero", ErrInvalidBundle, i)
+		}
+		product, err := s.productRepo.GetProduct(ctx, c.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("%w: unknown product %q", ErrInvalidBundle, c.ProductID)
+		}
+		if c.ProductID == product.ID && len(product.Variants) > 0 {
+			return fmt.Errorf("%w: product %q has variants, a SKU is required", ErrInvalidBundle, c.ProductID)
+		}
+		if seen[c.ProductID] {
+			return fmt.Errorf("%w: product %q is listed twice", ErrInvalidBundle, c.ProductID)
+		}
+		seen[c.ProductID] = true
+		units += c.Quantity
+	}
+	if units < 2 {
+		return fmt.Errorf("%w: a bundle needs two units or more", ErrInvalidBundle)
+	}
+	return s.repo.Save(ctx, bundle)
+}
+
+// Apportion splits amount among the components in proportion to weights,
+// to the cent: the shares add up to amount exactly, the cents left by the
+// rounding going to the largest remainders. Without weights the amount is
+// split evenly
+func Apportion(amount float64, weights []float64) []float64 {
+	shares := make([]float64, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	cents := math.Round(amount * 100)
+	remainders := make([]float64, len(weights))
+	assigned := 0.0
+	for i, w := range weights {
+		exact := cents / float64(len(weights))
+		if total > 0 {
+			exact = cents * w / total
+		}
+		shares[i] = math.Floor(exact)
+		remainders[i] = exact - shares[i]
+		assigned += shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < cents; i++ {
+		shares[order[i%len(order)]]++
+		assigned++
+	}
+	for i := range shares {
+		shares[i] /= 100
+	}
+	return shares
+}
@@ -0,0 +1,9 @@
+package category
+
+import "purchase-cart-service/models"
+
+// Node is a category of the tree with its subcategories, in catalog order
+type Node struct {
+	models.Category
+	Children []*Node
+}
@@ -0,0 +1,119 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"sort"
+)
+
+type Service struct {
+	repo repository.CategoryRepository
+}
+
+func NewService(repo repository.CategoryRepository) *Service {
+	return &Service{repo: repo}
+}
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+// Tree returns the root categories with their descendants, siblings ordered
+// by position and name
+func (s *Service) Tree(ctx context.Context) ([]*Node, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*Node, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &Node{Category: c}
+	}
+	var roots []*Node
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortNodes(roots)
+	return roots, nil
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Position != nodes[j].Position {
+			return nodes[i].Position < nodes[j].Position
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+// Subtree returns the category with the given slug and the IDs of the
+// category and of all its descendants
+func (s *Service) Subtree(ctx context.Context, slug string) (*models.Category, []string, error) {
+	category, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	if category == nil {
+		return nil, nil, ErrCategoryNotFound
+	}
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	children := make(map[string][]string)
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c.ID)
+	}
+	ids := []string{category.ID}
+	seen := map[string]bool{category.ID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return category, ids, nil
+}
+
+// TaxClass returns the tax class of a product: its own, else the one of its
+// main category, the first one, or of the closest ancestor setting one. It
+// is the standard class when none is set, or on a nil Service
+func (s *Service) TaxClass(ctx context.Context, p models.Product) (string, error) {
+	if p.TaxClass != "" {
+		return p.TaxClass, nil
+	}
+	if s == nil || len(p.CategoryIDs) == 0 {
+		return models.TaxClassStandard, nil
+	}
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	byID := make(map[string]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	// the depth bound guards against a cycle in the parents
+	id := p.CategoryIDs[0]
+	for depth := 0; depth <= len(categories); depth++ {
+		c, ok := byID[id]
+		if !ok {
+			break
+		}
+		if c.TaxClass != "" {
+			return c.TaxClass, nil
+		}
+		id = c.ParentID
+	}
+	return models.TaxClassStandard, nil
+}
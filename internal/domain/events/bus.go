@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"purchase-cart-service/models"
+	"sync"
+)
+
+// Handler reacts to an event; a returned error makes the relay retry the delivery
+type Handler func(ctx context.Context, envelope Envelope) error
+
+// Bus dispatches events to the in-process handlers subscribed to their type.
+// It is fed by the outbox relay, so handlers only see committed changes and
+// receive every event at least once
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []*Subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler for the given event types. name identifies the
+// handler in the delivery tracking of the relay and must be unique on the bus
+func (b *Bus) Subscribe(name string, handler Handler, eventTypes ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	types := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		types[t] = true
+	}
+	b.subscriptions = append(b.subscriptions, &Subscription{name: name, handler: handler, types: types})
+}
+
+// Publish calls every handler subscribed to the event type, in subscription order
+func (b *Bus) Publish(ctx context.Context, envelope Envelope) error {
+	var errs []error
+	for _, sub := range b.Sinks() {
+		if !sub.types[envelope.Event.EventType()] {
+			continue
+		}
+		if err := sub.handler(ctx, envelope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sinks returns the subscriptions, in subscription order, to be relayed to
+// as sinks of their own
+func (b *Bus) Sinks() []*Subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]*Subscription(nil), b.subscriptions...)
+}
+
+// Subscription is a handler of the bus with the event types it receives
+type Subscription struct {
+	name    string
+	handler Handler
+	types   map[string]bool
+}
+
+// Name and Send make the subscription usable as a relay sink; the events of
+// other types are acknowledged without calling the handler
+func (s *Subscription) Name() string {
+	return "bus:" + s.name
+}
+
+func (s *Subscription) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	if !s.types[msg.EventType] {
+		return nil
+	}
+	envelope, err := Decode(msg)
+	if err != nil {
+		return fmt.Errorf("decode event %s: %w", msg.ID, err)
+	}
+	return s.handler(ctx, envelope)
+}
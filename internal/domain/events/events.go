@@ -0,0 +1,171 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"purchase-cart-service/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TypeOrderCreated        = "order.created"
+	TypeOrderPaid           = "order.paid"
+	TypeOrderCancelled      = "order.cancelled"
+	TypeOrderShipped        = "order.shipped"
+	TypeProductPriceChanged = "product.price_changed"
+)
+
+// ErrUnknownEvent is returned when decoding a message of an unknown type
+var ErrUnknownEvent = errors.New("unknown event type")
+
+// Event is a domain event raised by a service
+type Event interface {
+	// EventType is the stable name of the event, used to route and decode it
+	EventType() string
+	// AggregateID identifies the entity the event refers to
+	AggregateID() string
+}
+
+// Envelope carries an event together with its metadata
+type Envelope struct {
+	ID         string
+	OccurredAt time.Time
+	Event      Event
+}
+
+// Order is the snapshot of an order carried by the order events
+type Order struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	CountryCode string `json:"country_code"`
+	// BuyerVATID and ReverseCharge are set on business sales
+	BuyerVATID    string      `json:"buyer_vat_id,omitempty"`
+	ReverseCharge bool        `json:"reverse_charge,omitempty"`
+	TotalPrice    float64     `json:"total_price"`
+	TotalVAT      float64     `json:"total_vat"`
+	Items         []OrderItem `json:"items"`
+}
+
+type OrderItem struct {
+	ProductID string  `json:"product_id"`
+	SKU       string  `json:"sku,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	VATRate   float64 `json:"vat_rate"`
+	VAT       float64 `json:"vat"`
+}
+
+type OrderCreated struct {
+	Order Order `json:"order"`
+}
+
+type OrderPaid struct {
+	Order Order `json:"order"`
+}
+
+type OrderCancelled struct {
+	Order Order `json:"order"`
+}
+
+type OrderShipped struct {
+	Order Order `json:"order"`
+}
+
+type ProductPriceChanged struct {
+	ProductID string `json:"product_id"`
+	// SKU is the variant whose price changed, empty for the product price
+	SKU      string  `json:"sku,omitempty"`
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+	// PriceMode is the mode of the new price, empty for the catalog default
+	PriceMode string `json:"price_mode,omitempty"`
+}
+
+func (e OrderCreated) EventType() string          { return TypeOrderCreated }
+func (e OrderCreated) AggregateID() string        { return e.Order.ID }
+func (e OrderPaid) EventType() string             { return TypeOrderPaid }
+func (e OrderPaid) AggregateID() string           { return e.Order.ID }
+func (e OrderCancelled) EventType() string        { return TypeOrderCancelled }
+func (e OrderCancelled) AggregateID() string      { return e.Order.ID }
+func (e OrderShipped) EventType() string          { return TypeOrderShipped }
+func (e OrderShipped) AggregateID() string        { return e.Order.ID }
+func (e ProductPriceChanged) EventType() string   { return TypeProductPriceChanged }
+func (e ProductPriceChanged) AggregateID() string { return e.ProductID }
+
+// NewOrder builds the snapshot of an order carried by the order events
+func NewOrder(order *models.Order) Order {
+	snapshot := Order{
+		ID:            order.ID,
+		Status:        order.Status,
+		CountryCode:   order.CountryCode,
+		BuyerVATID:    order.BuyerVATID,
+		ReverseCharge: order.ReverseCharge,
+		TotalPrice:    order.TotalPrice,
+		TotalVAT:      order.TotalVAT,
+		Items:         make([]OrderItem, 0, len(order.Items)),
+	}
+	for _, it := range order.Items {
+		snapshot.Items = append(snapshot.Items, OrderItem{
+			ProductID: it.ProductID,
+			SKU:       it.SKU,
+			Name:      it.Name,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+			VATRate:   it.VATRate,
+			VAT:       it.VAT,
+		})
+	}
+	return snapshot
+}
+
+// NewOutboxMessage encodes an event as an outbox message, to be stored
+// together with the change that raised it
+func NewOutboxMessage(event Event) (*models.OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxMessage{
+		ID:          uuid.NewString(),
+		EventType:   event.EventType(),
+		AggregateID: event.AggregateID(),
+		Payload:     payload,
+		OccurredAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Decode rebuilds the typed event stored in an outbox message
+func Decode(msg *models.OutboxMessage) (Envelope, error) {
+	var event Event
+	var err error
+	switch msg.EventType {
+	case TypeOrderCreated:
+		event, err = decode[OrderCreated](msg.Payload)
+	case TypeOrderPaid:
+		event, err = decode[OrderPaid](msg.Payload)
+	case TypeOrderCancelled:
+		event, err = decode[OrderCancelled](msg.Payload)
+	case TypeOrderShipped:
+		event, err = decode[OrderShipped](msg.Payload)
+	case TypeProductPriceChanged:
+		event, err = decode[ProductPriceChanged](msg.Payload)
+	default:
+		return Envelope{}, fmt.Errorf("%w: %q", ErrUnknownEvent, msg.EventType)
+	}
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{ID: msg.ID, OccurredAt: msg.OccurredAt, Event: event}, nil
+}
+
+func decode[T Event](payload []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,109 @@
+package invoice
+
+import (
+	"purchase-cart-service/models"
+	"purchase-cart-service/utils"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Seller is the issuer printed on the invoices; the name heads its address
+type Seller struct {
+	Name    string
+	VATID   string
+	Email   string
+	Address models.Address
+}
+
+// Document is what the renderers print: an invoice and its issuer
+type Document struct {
+	models.Invoice
+	Seller Seller
+}
+
+// ReverseChargeNote is printed on reverse charge invoices, as required by
+// article 226 of the VAT directive
+const ReverseChargeNote = "Reverse charge: VAT to be accounted for by the recipient (article 196, Council Directive 2006/112/EC)"
+
+// VATBreakdown is the taxable amount and the VAT of a single rate
+type VATBreakdown struct {
+	Rate float64
+	Net  float64
+	VAT  float64
+}
+
+// VATSummary groups the lines by VAT rate, lowest rate first
+func (d Document) VATSummary() []VATBreakdown {
+	byRate := make(map[float64]*VATBreakdown)
+	var summary []*VATBreakdown
+	for _, line := range d.Lines {
+		b, ok := byRate[line.VATRate]
+		if !ok {
+			b = &VATBreakdown{Rate: line.VATRate}
+			byRate[line.VATRate] = b
+			summary = append(summary, b)
+		}
+		b.Net = utils.Round2(b.Net + line.Net)
+		b.VAT = utils.Round2(b.VAT + line.VAT)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Rate < summary[j].Rate })
+	result := make([]VATBreakdown, 0, len(summary))
+	for _, b := range summary {
+		result = append(result, *b)
+	}
+	return result
+}
+
+// formatMoney prints an amount with two decimals
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatRate prints a VAT rate as a percentage, e.g. 0.22 as 22%
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(utils.Round2(rate*100), 'f', -1, 64) + "%"
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// addressLines returns the non-empty lines of an address, as printed on an envelope
+func addressLines(a models.Address) []string {
+	var lines []string
+	for _, line := range []string{a.Name, a.Line1, a.Line2, joinNonEmpty(a.PostalCode, a.City, a.Region), a.CountryCode} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Lines returns the seller block of the invoice: name, address, VAT ID and email
+func (s Seller) Lines() []string {
+	address := s.Address
+	address.Name = s.Name
+	lines := addressLines(address)
+	if s.VATID != "" {
+		lines = append(lines, "VAT ID "+s.VATID)
+	}
+	if s.Email != "" {
+		lines = append(lines, s.Email)
+	}
+	return lines
+}
+
+func joinNonEmpty(parts ...string) string {
+	result := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if result != "" {
+			result += " "
+		}
+		result += part
+	}
+	return result
+}
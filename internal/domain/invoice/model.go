@@ -0,0 +1,58 @@
+package invoice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNumberFormat gives numbers such as IT-2026-000123
+const DefaultNumberFormat = "{country}-{year}-{seq}"
+
+// Numbering describes how invoice numbers are built. Every country has its
+// own sequence per fiscal year, restarting from 1
+type Numbering struct {
+	// Format is the number template, with the placeholders {country}, {year} and {seq}
+	Format string
+	// Digits is the minimum width of {seq}, padded with zeros
+	Digits int
+	// FiscalYearStart is the first month of the fiscal year; a fiscal year is
+	// named after the calendar year it starts in
+	FiscalYearStart time.Month
+	// Location is the time zone the issue date is read in
+	Location *time.Location
+}
+
+// FiscalYear returns the fiscal year an invoice issued at t belongs to
+func (n Numbering) FiscalYear(t time.Time) int {
+	t = t.In(n.location())
+	start := n.FiscalYearStart
+	if start < time.January || start > time.December {
+		start = time.January
+	}
+	if t.Month() < start {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// Number formats the sequence of a country and fiscal year
+func (n Numbering) Number(countryCode string, fiscalYear int, sequence int64) string {
+	format := n.Format
+	if format == "" {
+		format = DefaultNumberFormat
+	}
+	return strings.NewReplacer(
+		"{country}", countryCode,
+		"{year}", strconv.Itoa(fiscalYear),
+		"{seq}", fmt.Sprintf("%0*d", n.Digits, sequence),
+	).Replace(format)
+}
+
+func (n Numbering) location() *time.Location {
+	if n.Location == nil {
+		return time.UTC
+	}
+	return n.Location
+}
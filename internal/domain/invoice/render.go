@@ -0,0 +1,157 @@
+package invoice
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+//go:embed templates/invoice.html.tmpl
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.New("invoice.html.tmpl").Funcs(template.FuncMap{
+	"money":        formatMoney,
+	"rate":         formatRate,
+	"date":         formatDate,
+	"addressLines": addressLines,
+	"reverseChargeNote": func() string {
+		return ReverseChargeNote
+	},
+}).ParseFS(templates, "templates/invoice.html.tmpl"))
+
+// RenderHTML writes the invoice as a standalone HTML page
+func RenderHTML(w io.Writer, doc Document) error {
+	return htmlTemplate.Execute(w, doc)
+}
+
+// pdf layout, in millimetres on an A4 page
+const (
+	pdfMargin     = 15.0
+	pdfLineHeight = 5.0
+	pdfRowHeight  = 7.0
+)
+
+// lineColumns are the widths of the columns of the lines table, 180mm in all
+var lineColumns = []float64{62, 14, 22, 18, 20, 20, 24}
+
+// RenderPDF writes the invoice as a PDF document. The output only depends on
+// the document: the creation date is the issue date, the resource catalogs
+// are sorted and streams are not compressed, so that the same invoice always
+// gives the same bytes
+func RenderPDF(w io.Writer, doc Document) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetCompression(false)
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(doc.IssuedAt)
+	pdf.SetModificationDate(doc.IssuedAt)
+	pdf.SetTitle("Invoice "+doc.Number, true)
+	pdf.SetCreator(doc.Seller.Name, true)
+	// the core fonts are cp1252 encoded
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr("Invoice "+doc.Number), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, pdfLineHeight, tr(fmt.Sprintf("Issued on %s - Order %s - Amounts in %s", formatDate(doc.IssuedAt), doc.OrderID, doc.Currency)), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	seller := doc.Seller.Lines()
+	buyer := addressLines(doc.Buyer)
+	if len(buyer) == 0 {
+		buyer = []string{doc.CountryCode}
+	}
+	if doc.BuyerVATID != "" {
+		buyer = append(buyer, "VAT ID "+doc.BuyerVATID)
+	}
+	top := pdf.GetY()
+	pdfParty(pdf, tr, pdfMargin, top, "Seller", seller)
+	pdfParty(pdf, tr, 110, top, "Bill to", buyer)
+	pdf.SetXY(pdfMargin, top+pdfLineHeight*float64(max(len(seller), len(buyer))+1)+6)
+
+	pdfHeader(pdf, tr, lineColumns, []string{"Product", "Qty", "Unit price", "VAT rate", "Net", "VAT", "Total"})
+	for _, line := range doc.Lines {
+		pdfRow(pdf, tr, lineColumns, []string{
+			line.Name,
+			strconv.Itoa(line.Quantity),
+			formatMoney(line.UnitPrice),
+			formatRate(line.VATRate),
+			formatMoney(line.Net),
+			formatMoney(line.VAT),
+			formatMoney(line.Total),
+		})
+	}
+	pdf.Ln(6)
+
+	summaryColumns := []float64{40, 40, 40}
+	pdfHeader(pdf, tr, summaryColumns, []string{"VAT rate", "Taxable amount", "VAT"})
+	for _, b := range doc.VATSummary() {
+		pdfRow(pdf, tr, summaryColumns, []string{formatRate(b.Rate), formatMoney(b.Net), formatMoney(b.VAT)})
+	}
+	pdf.Ln(6)
+
+	totals := [][2]string{
+		{"Net", formatMoney(doc.TotalNet)},
+		{"VAT", formatMoney(doc.TotalVAT)},
+		{"Total " + doc.Currency, formatMoney(doc.Total)},
+	}
+	for i, total := range totals {
+		if i == len(totals)-1 {
+			pdf.SetFont("Helvetica", "B", 10)
+		}
+		pdf.SetX(120)
+		pdf.CellFormat(40, pdfRowHeight, tr(total[0]), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(35, pdfRowHeight, tr(total[1]), "B", 1, "R", false, 0, "")
+	}
+	if doc.ReverseCharge {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.MultiCell(0, pdfLineHeight, tr(ReverseChargeNote), "", "L", false)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func pdfParty(pdf *fpdf.Fpdf, tr func(string) string, x, y float64, title string, lines []string) {
+	pdf.SetXY(x, y)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(80, pdfLineHeight, tr(title), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range lines {
+		pdf.CellFormat(80, pdfLineHeight, tr(line), "", 2, "L", false, 0, "")
+	}
+}
+
+func pdfHeader(pdf *fpdf.Fpdf, tr func(string) string, widths []float64, titles []string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(244, 244, 244)
+	for i, title := range titles {
+		pdf.CellFormat(widths[i], pdfRowHeight, tr(title), "B", 0, pdfAlign(i), true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 9)
+}
+
+func pdfRow(pdf *fpdf.Fpdf, tr func(string) string, widths []float64, cells []string) {
+	for i, cell := range cells {
+		pdf.CellFormat(widths[i], pdfRowHeight, tr(cell), "B", 0, pdfAlign(i), false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// pdfAlign left-aligns the first column of a table and right-aligns the amounts
+func pdfAlign(column int) string {
+	if column == 0 {
+		return "L"
+	}
+	return "R"
+}
@@ -0,0 +1,132 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"purchase-cart-service/utils"
+	"sync"
+	"time"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+var ErrOrderNotPaid = errors.New("order has not been paid")
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+type Service struct {
+	invoices  repository.InvoiceRepository
+	orders    *order.Service
+	numbering Numbering
+	seller    Seller
+	currency  string
+
+	// mu serializes the issuance, so that an order paid event delivered twice
+	// cannot invoice the order twice
+	mu sync.Mutex
+}
+
+func NewService(invoices repository.InvoiceRepository, orders *order.Service, numbering Numbering, seller Seller, currency string) *Service {
+	return &Service{
+		invoices:  invoices,
+		orders:    orders,
+		numbering: numbering,
+		seller:    seller,
+		currency:  currency,
+	}
+}
+
+// Issue invoices a paid order, taking the next number of its country for the
+// fiscal year of at. An order already invoiced gets its invoice back
+func (s *Service) Issue(ctx context.Context, orderID string, at time.Time) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.invoices.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	ord, err := s.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	// a paid order can only move on to shipped
+	if ord.Status != models.OrderStatusPaid && ord.Status != models.OrderStatusShipped {
+		return nil, ErrOrderNotPaid
+	}
+
+	invoice := &models.Invoice{
+		OrderID:       ord.ID,
+		CountryCode:   ord.CountryCode,
+		FiscalYear:    s.numbering.FiscalYear(at),
+		Currency:      s.currency,
+		Buyer:         ord.BillingAddress,
+		BuyerVATID:    ord.BuyerVATID,
+		ReverseCharge: ord.ReverseCharge,
+		TotalNet:      utils.Round2(ord.TotalPrice - ord.TotalVAT),
+		TotalVAT:      ord.TotalVAT,
+		Total:         ord.TotalPrice,
+		IssuedAt:      at.UTC(),
+	}
+	for _, item := range ord.Items {
+		net := item.Net()
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			VATRate:   item.VATRate,
+			Net:       net,
+			// Item.VAT holds the line total including VAT
+			VAT:   utils.Round2(item.VAT - net),
+			Total: item.VAT,
+		})
+	}
+	err = s.invoices.Save(ctx, invoice, func(sequence int64) string {
+		return s.numbering.Number(invoice.CountryCode, invoice.FiscalYear, sequence)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetInvoice returns the invoice of an order
+func (s *Service) GetInvoice(ctx context.Context, orderID string) (*models.Invoice, error) {
+	invoice, err := s.invoices.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// GetDocument returns the invoice of an order together with the seller, ready
+// to be rendered
+func (s *Service) GetDocument(ctx context.Context, orderID string) (*Document, error) {
+	invoice, err := s.GetInvoice(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Invoice: *invoice, Seller: s.seller}, nil
+}
+
+// HandleOrderEvent is an events.Handler invoicing orders when they are paid,
+// dated when the payment was recorded
+func (s *Service) HandleOrderEvent(ctx context.Context, envelope events.Envelope) error {
+	paid, ok := envelope.Event.(events.OrderPaid)
+	if !ok {
+		return nil
+	}
+	_, err := s.Issue(ctx, paid.Order.ID, envelope.OccurredAt)
+	return err
+}
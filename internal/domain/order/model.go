@@ -1,21 +1,137 @@
 package order
 
-import "purchase-cart-service/models"
+import (
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/models"
+)
 
-// CreateItem is the input DTO for creating orders
+// CreateItem is a line of a new order. A product with variants is ordered by
+// SKU, either in SKU or in ProductID; a bundle by BundleID alone
 type CreateItem struct {
 	ProductID string
-	UnitPrice float64
+	SKU       string
+	BundleID  string
 	Quantity  int
 }
+
+// CreateOption sets optional data of a new order
+type CreateOption func(*models.Order)
+
+// WithBillingAddress records the buyer address shown on the invoice
+func WithBillingAddress(address models.Address) CreateOption {
+	return func(o *models.Order) {
+		o.BillingAddress = address
+	}
+}
+
+// WithShippingAddress sets the delivery address of the order
+func WithShippingAddress(address models.Address) CreateOption {
+	return func(o *models.Order) {
+		o.ShippingAddress = address
+	}
+}
+
+// WithBuyerVATID records the VAT number of a business buyer; when the sale
+// is intra-community the order is placed under reverse charge
+func WithBuyerVATID(vatID string) CreateOption {
+	return func(o *models.Order) {
+		o.BuyerVATID = vatID
+	}
+}
+
+// ServiceOption customizes a Service
+type ServiceOption func(*Service)
+
+// WithTax replaces the default tax lookup, by country VAT rate only
+func WithTax(taxes *tax.Service) ServiceOption {
+	return func(s *Service) {
+		s.taxes = taxes
+	}
+}
+
+// WithPriceMode sets the catalog price mode, models.PriceModeNet (the
+// default) or models.PriceModeGross, used for the products without their own
+func WithPriceMode(mode string) ServiceOption {
+	return func(s *Service) {
+		s.priceMode = mode
+	}
+}
+
+// WithCategories resolves the tax class of the products through their
+// categories
+func WithCategories(categories *category.Service) ServiceOption {
+	return func(s *Service) {
+		s.categories = categories
+	}
+}
+
+// WithPricing prices the lines for the buyer of the request, from the price
+// lists assigned to it
+func WithPricing(pricing *pricing.Service) ServiceOption {
+	return func(s *Service) {
+		s.pricing = pricing
+	}
+}
+
+// WithBundles enables the lines ordering a bundle, expanded into its
+// components
+func WithBundles(bundles *bundle.Service) ServiceOption {
+	return func(s *Service) {
+		s.bundles = bundles
+	}
+}
+
+// WithRounding sets how the VAT is rounded, by country of the order; the
+// default is rounding.Default everywhere
+func WithRounding(policies rounding.Policies) ServiceOption {
+	return func(s *Service) {
+		s.rounding = policies
+	}
+}
+
+// WithReverseCharge enables the EU reverse charge for business buyers in a
+// member state other than sellerCountry, once verifier confirms their VAT ID
+func WithReverseCharge(verifier vatid.Verifier, sellerCountry string) ServiceOption {
+	return func(s *Service) {
+		s.verifier = verifier
+		s.sellerCountry = sellerCountry
+	}
+}
+
 type Detail struct {
-	Id         string
-	TotalPrice float64
-	TotalVAT   float64
-	Items      []ProductDetail
+	Id            string
+	Status        string
+	TotalPrice    float64
+	TotalVAT      float64
+	Items         []ProductDetail
+	BuyerVATID    string
+	ReverseCharge bool
+	TaxTerritory  string
 }
 type ProductDetail struct {
 	models.Product
+	// Line is the position of the line in the order, counted from 1
+	Line     int
 	Quantity int
+	// SKU is the variant ordered, empty for the products without variants
+	SKU string
+	// VATRate is the rate of the line, for the goods of TaxClass
+	VATRate  float64
+	TaxClass string
 	VAT      float64
+	Taxes    []models.LineTax
+	// PriceMode and PriceWithVAT are the mode and the unit price including
+	// VAT of the line; Product.Price is the net unit price
+	PriceMode    string
+	PriceWithVAT float64
+	// PriceList is the price list the line was priced from, empty for the
+	// catalog price
+	PriceList string
+	// Bundle is the bundle the line is a component of
+	Bundle *models.ItemBundle
 }
@@ -3,74 +3,507 @@ package order
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/internal/domain/vatid"
 	"purchase-cart-service/models"
 	"purchase-cart-service/repository"
 	"purchase-cart-service/utils"
+	"sync"
+
+	"github.com/google/uuid"
 )
 
 type Service struct {
 	orderRepo   repository.OrderRepository
 	productRepo repository.ProductRepository
-	vatRepo     repository.VatRateRepository
+	taxes       *tax.Service
+	// priceMode is the catalog default of the products without their own
+	priceMode string
+	// rounding is how the VAT is rounded, by country of the order
+	rounding rounding.Policies
+	// categories resolve the tax class of the products; without them every
+	// product without its own class is standard
+	categories *category.Service
+	// pricing resolves the prices of the price lists of the buyer; without
+	// it every line is at the catalog price
+	pricing *pricing.Service
+	// bundles resolve the lines ordering a bundle; without them no bundle
+	// is found
+	bundles *bundle.Service
+
+	// verifier and sellerCountry enable the reverse charge, see WithReverseCharge
+	verifier      vatid.Verifier
+	sellerCountry string
+
+	// transitionMu serializes status changes so that concurrent requests
+	// cannot both move the same order out of the created status
+	transitionMu sync.Mutex
 }
 
-func NewService(orderRepo repository.OrderRepository, vatRepo repository.VatRateRepository, productRepo repository.ProductRepository) *Service {
-	return &Service{
+func NewService(orderRepo repository.OrderRepository, vatRepo repository.VatRateRepository, productRepo repository.ProductRepository, opts ...ServiceOption) *Service {
+	s := &Service{
 		orderRepo:   orderRepo,
-		vatRepo:     vatRepo,
 		productRepo: productRepo,
+		taxes:       tax.NewService(vatRepo),
+		priceMode:   models.PriceModeNet,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 var ErrInvalidItem = errors.New("invalid order item")
 var ErrInvalidVATRate = errors.New("invalid VAT rate")
 var ErrProductNotFound = errors.New("product not found")
+var ErrOrderNotFound = errors.New("order not found")
+var ErrInvalidStatusTransition = errors.New("invalid order status transition")
+var ErrHistoryUnavailable = errors.New("order history not kept by the repository")
+var ErrInvalidDestination = errors.New("invalid delivery destination")
+var ErrInvalidVATID = errors.New("invalid buyer VAT ID")
+var ErrVATIDNotRegistered = errors.New("buyer VAT ID is not registered for intra-community trade")
+var ErrVATIDVerificationUnavailable = errors.New("buyer VAT ID could not be verified")
+var ErrVariantRequired = errors.New("product has variants, a SKU is required")
+var ErrOutOfStock = errors.New("SKU out of stock")
 
-func (s *Service) CreateOrder(ctx context.Context, countryCode string, items []CreateItem) (*models.Order, error) {
+func (s *Service) CreateOrder(ctx context.Context, countryCode string, items []CreateItem, opts ...CreateOption) (*models.Order, error) {
+	order, err := s.QuoteOrder(ctx, countryCode, items, opts...)
+	if err != nil {
+		return nil, err
+	}
+	order.ID = uuid.NewString()
+	order.Status = models.OrderStatusCreated
+	msg, err := events.NewOutboxMessage(events.OrderCreated{Order: events.NewOrder(order)})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reserveStock(ctx, order.Items); err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.Save(ctx, order, msg); err != nil {
+		s.releaseStock(ctx, order.Items)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// QuoteOrder prices the lines of an order like CreateOrder, quantity breaks
+// included, without reserving the stock nor saving it; the quote has no ID
+// nor status
+func (s *Service) QuoteOrder(ctx context.Context, countryCode string, items []CreateItem, opts ...CreateOption) (*models.Order, error) {
 	if len(items) == 0 {
 		return nil, ErrInvalidItem
 	}
-	vatRate, err := s.vatRepo.GetVATRate(countryCode)
+	order := &models.Order{CountryCode: countryCode}
+	for _, opt := range opts {
+		opt(order)
+	}
+	destination := models.Destination{
+		CountryCode: countryCode,
+		Region:      order.ShippingAddress.Region,
+		PostalCode:  order.ShippingAddress.PostalCode,
+	}
+	taxRate, err := s.lookupRate(ctx, destination, models.TaxClassStandard)
 	if err != nil {
-		return nil, ErrInvalidVATRate
+		return nil, err
+	}
+	destinationCountry := countryCode
+	if taxRate.Territory != nil {
+		order.TaxTerritory = taxRate.Territory.Name
+		if taxRate.Territory.Code != "" {
+			destinationCountry = taxRate.Territory.Code
+		}
 	}
-	order := &models.Order{}
-	for _, it := range items {
-		product, err := s.productRepo.GetProduct(ctx, it.ProductID)
+	if order.BuyerVATID != "" {
+		if err := s.applyReverseCharge(ctx, order, destinationCountry); err != nil {
+			return nil, err
+		}
+		if order.ReverseCharge {
+			taxRate = &models.TaxRate{}
+		}
+	}
+	rates := &classRates{service: s, destination: destination, reverseCharge: order.ReverseCharge,
+		rates: map[string]*models.TaxRate{models.TaxClassStandard: taxRate}}
+	var priced []pricedLine
+	for i, it := range items {
+		if it.BundleID != "" {
+			components, err := s.expandBundle(ctx, it, i+1, rates)
+			if err != nil {
+				return nil, err
+			}
+			priced = append(priced, components...)
+			continue
+		}
+		product, variant, err := s.lookupItem(ctx, it)
 		if err != nil {
 			return nil, err
 		}
-		if product == nil {
-			return nil, ErrProductNotFound
+		resolved, err := s.pricing.Resolve(ctx, *product, variant)
+		if err != nil {
+			return nil, err
 		}
-		if it.Quantity <= 0 || product.Price <= 0 {
+		// the tier reached by the quantity of the line sets its unit price
+		price := resolved.At(it.Quantity)
+		if it.Quantity <= 0 || price <= 0 {
 			return nil, ErrInvalidItem
 		}
+		if variant.SKU != "" && variant.Stock < it.Quantity {
+			return nil, ErrOutOfStock
+		}
+		if product.PriceMode == "" {
+			product.PriceMode = s.priceMode
+		}
+		class, rate, err := rates.of(ctx, *product)
+		if err != nil {
+			return nil, err
+		}
+		priced = append(priced, pricedLine{
+			product:   product,
+			variant:   variant,
+			class:     class,
+			priceList: resolved.List,
+			line: tax.Line{
+				Quantity:  it.Quantity,
+				UnitPrice: price,
+				// the customer pays exactly the catalog price, the VAT is extracted from the line
+				Gross: product.PriceMode == models.PriceModeGross,
+				Rate:  *rate,
+			},
+		})
+	}
+	lines := make([]tax.Line, len(priced))
+	for i, p := range priced {
+		lines[i] = p.line
+	}
 
-		linePrice := float64(it.Quantity) * product.Price
-
-		vat := utils.Round2(linePrice * vatRate)
-		total := utils.Round2(linePrice + vat)
-
+	for i, amounts := range tax.Compute(lines, s.rounding.For(countryCode)) {
+		product, variant := priced[i].product, priced[i].variant
+		rate := lines[i].Rate.Rate
+		unitNet, unitGross := tax.UnitPrices(lines[i].UnitPrice, product.PriceMode, rate)
+		name := product.Name
+		if label := variant.Label(); label != "" {
+			name += " (" + label + ")"
+		}
 		order.Items = append(order.Items, models.Item{
-			ProductID: it.ProductID,
-			Name:      product.Name,
-			Quantity:  it.Quantity,
-			UnitPrice: product.Price,
-			VAT:       total,
+			ProductID:        product.ID,
+			SKU:              variant.SKU,
+			Name:             name,
+			Quantity:         lines[i].Quantity,
+			UnitPrice:        unitNet,
+			VATRate:          rate,
+			TaxClass:         priced[i].class,
+			VAT:              amounts.Total,
+			Taxes:            amounts.Shares,
+			PriceMode:        product.PriceMode,
+			UnitPriceWithVAT: unitGross,
+			PriceList:        priced[i].priceList,
+			Bundle:           priced[i].bundle,
 		})
 
-		order.TotalVAT += vat
-		order.TotalPrice += total
+		order.TotalVAT += amounts.Tax
+		order.TotalPrice += amounts.Total
 	}
 
 	order.TotalVAT = utils.Round2(order.TotalVAT)
 	order.TotalPrice = utils.Round2(order.TotalPrice)
-	if err := s.orderRepo.Save(ctx, order); err != nil {
+	return order, nil
+}
+
+// lookupRate returns the tax rate of a class of goods at destination
+func (s *Service) lookupRate(ctx context.Context, destination models.Destination, class string) (*models.TaxRate, error) {
+	rate, err := s.taxes.LookupClass(ctx, destination, class)
+	if err != nil {
+		if err == tax.ErrRateNotFound {
+			return nil, ErrInvalidVATRate
+		}
+		if errors.Is(err, tax.ErrIncompleteDestination) || errors.Is(err, tax.ErrPostalCodeNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+		}
 		return nil, err
 	}
+	return rate, nil
+}
 
-	return order, nil
+// lookupItem finds the product of an order line and, for a product with
+// variants, the variant of the SKU
+func (s *Service) lookupItem(ctx context.Context, it CreateItem) (*models.Product, models.Variant, error) {
+	id := it.SKU
+	if id == "" {
+		id = it.ProductID
+	}
+	product, err := s.productRepo.GetProduct(ctx, id)
+	if err != nil {
+		return nil, models.Variant{}, err
+	}
+	if product == nil || it.ProductID != "" && it.SKU != "" && it.ProductID != product.ID {
+		return nil, models.Variant{}, ErrProductNotFound
+	}
+	if id == product.ID {
+		if len(product.Variants) > 0 {
+			return nil, models.Variant{}, ErrVariantRequired
+		}
+		return product, models.Variant{}, nil
+	}
+	variant, ok := product.Variant(id)
+	if !ok {
+		return nil, models.Variant{}, ErrProductNotFound
+	}
+	return product, variant, nil
+}
+
+// pricedLine is a line of an order being priced, before its tax is computed
+type pricedLine struct {
+	product   *models.Product
+	variant   models.Variant
+	class     string
+	priceList string
+	bundle    *models.ItemBundle
+	line      tax.Line
+}
+
+// classRates looks up the rates of the tax classes of the lines of an order
+// once per class
+type classRates struct {
+	service       *Service
+	destination   models.Destination
+	reverseCharge bool
+	rates         map[string]*models.TaxRate
+}
+
+// of returns the tax class of a product and its rate at the destination
+func (r *classRates) of(ctx context.Context, product models.Product) (string, *models.TaxRate, error) {
+	class, err := r.service.categories.TaxClass(ctx, product)
+	if err != nil {
+		return "", nil, err
+	}
+	rate, ok := r.rates[class]
+	if !ok {
+		if rate, err = r.service.lookupRate(ctx, r.destination, class); err != nil {
+			return "", nil, err
+		}
+		if r.reverseCharge {
+			rate = &models.TaxRate{}
+		}
+		r.rates[class] = rate
+	}
+	return class, rate, nil
+}
+
+// expandBundle prices the components of the bundle ordered on line of the
+// request. The bundle price is apportioned among the components by their
+// catalog value net of VAT, so each share is taxed at the rate of its own
+// class; the price lists and the quantity tiers do not apply to bundles
+func (s *Service) expandBundle(ctx context.Context, it CreateItem, line int, rates *classRates) ([]pricedLine, error) {
+	if it.ProductID != "" || it.SKU != "" || it.Quantity <= 0 {
+		return nil, ErrInvalidItem
+	}
+	b, err := s.bundles.Get(ctx, it.BundleID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, bundle.ErrBundleNotFound
+	}
+	mode := b.PriceMode
+	if mode == "" {
+		mode = s.priceMode
+	}
+	ref := &models.ItemBundle{Line: line, ID: b.ID, Name: b.Name, Quantity: it.Quantity}
+	components := make([]pricedLine, 0, len(b.Components))
+	weights := make([]float64, 0, len(b.Components))
+	for _, c := range b.Components {
+		product, variant, err := s.lookupItem(ctx, CreateItem{ProductID: c.ProductID})
+		if err != nil {
+			return nil, err
+		}
+		quantity := c.Quantity * it.Quantity
+		if variant.SKU != "" && variant.Stock < quantity {
+			return nil, ErrOutOfStock
+		}
+		class, rate, err := rates.of(ctx, *product)
+		if err != nil {
+			return nil, err
+		}
+		productMode := product.PriceMode
+		if productMode == "" {
+			productMode = s.priceMode
+		}
+		net, _ := tax.UnitPrices(product.PriceOf(variant), productMode, rate.Rate)
+		weights = append(weights, net*float64(c.Quantity))
+		// the components take the price mode of the bundle, their price is a
+		// share of its own
+		product.PriceMode = mode
+		components = append(components, pricedLine{
+			product: product,
+			variant: variant,
+			class:   class,
+			bundle:  ref,
+			line:    tax.Line{Quantity: quantity, Gross: mode == models.PriceModeGross, Rate: *rate},
+		})
+	}
+	for i, share := range bundle.Apportion(b.Price, weights) {
+		components[i].line.UnitPrice = share / float64(b.Components[i].Quantity)
+	}
+	return components, nil
+}
+
+// reserveStock takes the units of the variant lines from the stock, all or
+// none of them
+func (s *Service) reserveStock(ctx context.Context, items []models.Item) error {
+	for i, item := range items {
+		if item.SKU == "" {
+			continue
+		}
+		ok, err := s.productRepo.ReserveStock(ctx, item.SKU, item.Quantity)
+		if err == nil && !ok {
+			err = ErrOutOfStock
+		}
+		if err != nil {
+			s.releaseStock(ctx, items[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStock gives back the units of the variant lines; the failures are
+// only logged, the order outcome does not depend on them
+func (s *Service) releaseStock(ctx context.Context, items []models.Item) {
+	for _, item := range items {
+		if item.SKU == "" {
+			continue
+		}
+		if err := s.productRepo.ReleaseStock(ctx, item.SKU, item.Quantity); err != nil {
+			log.Printf("order: releasing %d units of %s: %v", item.Quantity, item.SKU, err)
+		}
+	}
+}
+
+// applyReverseCharge normalizes the buyer VAT ID and sets the reverse charge
+// when the sale is intra-community: buyer registered in the destination
+// country, a member state other than the seller's, or Northern Ireland
+// (destinationCountry XI). Other business sales are charged VAT as usual
+func (s *Service) applyReverseCharge(ctx context.Context, order *models.Order, destinationCountry string) error {
+	number, err := vatid.Parse(order.BuyerVATID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVATID, err)
+	}
+	order.BuyerVATID = number.String()
+	if s.verifier == nil || number.CountryCode() != destinationCountry ||
+		!vatid.IsEU(s.sellerCountry) || s.sellerCountry == destinationCountry {
+		return nil
+	}
+	verification, err := s.verifier.Verify(ctx, number)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVATIDVerificationUnavailable, err)
+	}
+	if !verification.Valid {
+		return ErrVATIDNotRegistered
+	}
+	order.ReverseCharge = true
+	return nil
+}
+
+// CancelOrder cancels an order that has not been shipped yet, and gives its
+// units back to the stock
+func (s *Service) CancelOrder(ctx context.Context, id string) (*Detail, error) {
+	detail, err := s.transition(ctx, id, models.OrderStatusCancelled, func(o events.Order) events.Event {
+		return events.OrderCancelled{Order: o}
+	})
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.releaseStock(ctx, order.Items)
+	return detail, nil
+}
+
+// ShipOrder marks a paid order as shipped
+func (s *Service) ShipOrder(ctx context.Context, id string) (*Detail, error) {
+	return s.transition(ctx, id, models.OrderStatusShipped, func(o events.Order) events.Event {
+		return events.OrderShipped{Order: o}
+	})
+}
+
+// MarkPaid records that the payment of an order has been captured
+func (s *Service) MarkPaid(ctx context.Context, id string) (*Detail, error) {
+	return s.PayOrder(ctx, id, nil)
+}
+
+// PayOrder runs collect, which charges the customer, and marks the order as
+// paid once it succeeds. The status lock is held throughout, so that the
+// order cannot be cancelled between the check and the charge; nothing is
+// collected from an order that can no longer be paid
+func (s *Service) PayOrder(ctx context.Context, id string, collect func(ctx context.Context) error) (*Detail, error) {
+	return s.transitionAfter(ctx, id, models.OrderStatusPaid, func(o events.Order) events.Event {
+		return events.OrderPaid{Order: o}
+	}, collect)
+}
+
+// transitions lists the statuses reachable from each status; cancelled and
+// shipped are final, a paid order can no longer be cancelled and only a paid
+// order can be shipped
+var transitions = map[string][]string{
+	models.OrderStatusCreated: {models.OrderStatusPaid, models.OrderStatusCancelled},
+	models.OrderStatusPaid:    {models.OrderStatusShipped},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves an order to status, if allowed from its current one
+func (s *Service) transition(ctx context.Context, id string, status string, event func(events.Order) events.Event) (*Detail, error) {
+	return s.transitionAfter(ctx, id, status, event, nil)
+}
+
+// transitionAfter is transition running before, when set, once the change is
+// known to be allowed and before it is stored; an error of before aborts it
+func (s *Service) transitionAfter(ctx context.Context, id string, status string, event func(events.Order) events.Event, before func(ctx context.Context) error) (*Detail, error) {
+	s.transitionMu.Lock()
+	defer s.transitionMu.Unlock()
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if !canTransition(order.Status, status) {
+		return nil, ErrInvalidStatusTransition
+	}
+	if before != nil {
+		if err := before(ctx); err != nil {
+			return nil, err
+		}
+	}
+	changed := *order
+	changed.Status = status
+	msg, err := events.NewOutboxMessage(event(events.NewOrder(&changed)))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.Update(ctx, &changed, msg); err != nil {
+		return nil, err
+	}
+	order = &changed
+	return s.GetOrderDetail(ctx, order)
 }
 
 func (s *Service) GetOrderByID(ctx context.Context, id string) (*Detail, error) {
@@ -84,42 +517,102 @@ func (s *Service) GetOrderByID(ctx context.Context, id string) (*Detail, error)
 	return s.GetOrderDetail(ctx, order)
 
 }
+
+// GetOrder returns the stored order, with the prices and rates of the sale;
+// it is nil when the order does not exist
+func (s *Service) GetOrder(ctx context.Context, id string) (*models.Order, error) {
+	return s.orderRepo.GetByID(ctx, id)
+}
+
+// GetOrderHistory returns the events of an order, oldest first; it requires an
+// event-sourced repository
+func (s *Service) GetOrderHistory(ctx context.Context, id string) ([]models.OrderEvent, error) {
+	history, ok := s.orderRepo.(repository.OrderHistoryRepository)
+	if !ok {
+		return nil, ErrHistoryUnavailable
+	}
+	stream, err := history.GetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, ErrOrderNotFound
+	}
+	return stream, nil
+}
+
 func (s *Service) GetAllOrders(ctx context.Context) ([]*Detail, error) {
 	orders, err := s.orderRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
+	// a single catalog lookup for the products of every order
+	products, err := s.productRepo.GetProducts(ctx, productIDs(orders...))
+	if err != nil {
+		return nil, err
+	}
 	var details []*Detail
 	for _, order := range orders {
-		detail, err := s.GetOrderDetail(ctx, order)
-		if err != nil {
-			return nil, err
-		}
-		details = append(details, detail)
+		details = append(details, buildDetail(order, products))
 	}
 	return details, nil
 }
 
 func (s *Service) GetOrderDetail(ctx context.Context, order *models.Order) (*Detail, error) {
-	var products []ProductDetail
-	for _, item := range order.Items {
-		product, err := s.productRepo.GetProduct(ctx, item.ProductID)
-		if err != nil {
-			return nil, err
+	products, err := s.productRepo.GetProducts(ctx, productIDs(order))
+	if err != nil {
+		return nil, err
+	}
+	return buildDetail(order, products), nil
+}
+
+func productIDs(orders ...*models.Order) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if !seen[item.ProductID] {
+				seen[item.ProductID] = true
+				ids = append(ids, item.ProductID)
+			}
 		}
-		if product != nil {
-			product.Price = item.UnitPrice
-			products = append(products, ProductDetail{
-				Product:  *product,
-				VAT:      item.VAT,
-				Quantity: item.Quantity,
-			})
+	}
+	return ids
+}
+
+func buildDetail(order *models.Order, products map[string]models.Product) *Detail {
+	var items []ProductDetail
+	for i, item := range order.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
 		}
+		// the line keeps the name and price it was sold with, variant label included
+		product.Name = item.Name
+		product.Price = item.UnitPrice
+		items = append(items, ProductDetail{
+			Product:      product,
+			Line:         i + 1,
+			SKU:          item.SKU,
+			VATRate:      item.VATRate,
+			TaxClass:     item.TaxClass,
+			VAT:          item.VAT,
+			Quantity:     item.Quantity,
+			Taxes:        item.Taxes,
+			PriceMode:    item.PriceMode,
+			PriceWithVAT: item.UnitPriceWithVAT,
+			PriceList:    item.PriceList,
+			Bundle:       item.Bundle,
+		})
 	}
 	return &Detail{
-		Id:         order.ID,
-		TotalPrice: order.TotalPrice,
-		TotalVAT:   order.TotalVAT,
-		Items:      products,
-	}, nil
+		Id:            order.ID,
+		Status:        order.Status,
+		TotalPrice:    order.TotalPrice,
+		TotalVAT:      order.TotalVAT,
+		Items:         items,
+		BuyerVATID:    order.BuyerVATID,
+		ReverseCharge: order.ReverseCharge,
+		TaxTerritory:  order.TaxTerritory,
+	}
 }
@@ -0,0 +1,191 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"purchase-cart-service/utils"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Operations of the gateway, used to script the outcomes of the fake
+type Operation string
+
+const (
+	OpAuthorize Operation = "authorize"
+	OpCapture   Operation = "capture"
+	OpVoid      Operation = "void"
+	OpRefund    Operation = "refund"
+)
+
+// TokenDeclined is a payment method the fake gateway always declines, for
+// manual testing against a running service
+const TokenDeclined = "tok_declined"
+
+// Outcome is the scripted result of a fake gateway operation: a decline
+// with the given reason, an error (e.g. a timeout) or, when both are
+// empty, a success
+type Outcome struct {
+	DeclineReason string
+	Err           error
+}
+
+// Approve is the outcome of a successful operation
+var Approve = Outcome{}
+
+// Decline scripts a refusal with the given reason
+func Decline(reason string) Outcome {
+	return Outcome{DeclineReason: reason}
+}
+
+// Fail scripts a technical failure, such as a timeout
+func Fail(err error) Outcome {
+	return Outcome{Err: err}
+}
+
+type authorization struct {
+	amount   float64
+	captured float64
+	refunded float64
+	voided   bool
+	// captureID is set by the first capture; later captures are rejected
+	captureID string
+}
+
+// FakeGateway is an in-process Gateway keeping the authorizations in memory.
+// Every operation succeeds unless an outcome has been scripted for it; the
+// scripted outcomes of an operation are consumed in order. The amounts are
+// checked like a real provider would: captures up to the authorized amount,
+// refunds up to the captured one
+type FakeGateway struct {
+	mu             sync.Mutex
+	scripts        map[Operation][]Outcome
+	authorizations map[string]*authorization
+	captures       map[string]string
+	idempotency    map[string]string
+	calls          map[Operation]int
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		scripts:        make(map[Operation][]Outcome),
+		authorizations: make(map[string]*authorization),
+		captures:       make(map[string]string),
+		idempotency:    make(map[string]string),
+		calls:          make(map[Operation]int),
+	}
+}
+
+// Script queues the outcomes of the next calls of op
+func (g *FakeGateway) Script(op Operation, outcomes ...Outcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scripts[op] = append(g.scripts[op], outcomes...)
+}
+
+// Calls returns how many times op has been invoked
+func (g *FakeGateway) Calls(op Operation) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.calls[op]
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.next(OpAuthorize); err != nil {
+		return "", err
+	}
+	if req.PaymentMethod == TokenDeclined {
+		return "", &DeclineError{Reason: "card_declined"}
+	}
+	if req.Amount <= 0 {
+		return "", errors.New("amount must be positive")
+	}
+	if id, ok := g.idempotency[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return id, nil
+	}
+	id := "auth_" + uuid.NewString()
+	g.authorizations[id] = &authorization{amount: req.Amount}
+	if req.IdempotencyKey != "" {
+		g.idempotency[req.IdempotencyKey] = id
+	}
+	return id, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, authorizationID string, amount float64) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.next(OpCapture); err != nil {
+		return "", err
+	}
+	auth, ok := g.authorizations[authorizationID]
+	if !ok {
+		return "", fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if auth.voided || auth.captureID != "" {
+		return "", &DeclineError{Reason: "authorization_not_capturable"}
+	}
+	if amount <= 0 || utils.Round2(amount) > auth.amount {
+		return "", &DeclineError{Reason: "amount_exceeds_authorization"}
+	}
+	auth.captured = utils.Round2(amount)
+	auth.captureID = "cap_" + uuid.NewString()
+	g.captures[auth.captureID] = authorizationID
+	return auth.captureID, nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, authorizationID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.next(OpVoid); err != nil {
+		return err
+	}
+	auth, ok := g.authorizations[authorizationID]
+	if !ok {
+		return fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if auth.captureID != "" {
+		return &DeclineError{Reason: "authorization_already_captured"}
+	}
+	auth.voided = true
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, captureID string, amount float64) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.next(OpRefund); err != nil {
+		return "", err
+	}
+	authID, ok := g.captures[captureID]
+	if !ok {
+		return "", fmt.Errorf("unknown capture %s", captureID)
+	}
+	auth := g.authorizations[authID]
+	if amount <= 0 || utils.Round2(auth.refunded+amount) > auth.captured {
+		return "", &DeclineError{Reason: "amount_exceeds_capture"}
+	}
+	auth.refunded = utils.Round2(auth.refunded + amount)
+	return "ref_" + uuid.NewString(), nil
+}
+
+// next counts the call and consumes the next scripted outcome of op
+func (g *FakeGateway) next(op Operation) error {
+	g.calls[op]++
+	script := g.scripts[op]
+	if len(script) == 0 {
+		return nil
+	}
+	outcome := script[0]
+	g.scripts[op] = script[1:]
+	if outcome.Err != nil {
+		return outcome.Err
+	}
+	if outcome.DeclineReason != "" {
+		return &DeclineError{Reason: outcome.DeclineReason}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDeclined is returned by a gateway refusing an operation, e.g. for
+// insufficient funds; the reason is in the DeclineError
+var ErrDeclined = errors.New("payment declined")
+
+// DeclineError carries the reason given by the gateway
+type DeclineError struct {
+	Reason string
+}
+
+func (e *DeclineError) Error() string {
+	return "payment declined: " + e.Reason
+}
+
+func (e *DeclineError) Is(target error) bool {
+	return target == ErrDeclined
+}
+
+// AuthorizeRequest asks the gateway to reserve an amount on a payment method
+type AuthorizeRequest struct {
+	// IdempotencyKey makes retries of the same authorization safe
+	IdempotencyKey string
+	Amount         float64
+	Currency       string
+	// PaymentMethod is the token of the customer's payment method
+	PaymentMethod string
+	// Reference identifies the order on the gateway side
+	Reference string
+}
+
+// Gateway is a payment provider. Amounts are in the currency of the
+// authorization; a capture or refund can be partial. Operations return an
+// error wrapping ErrDeclined when the provider refuses them
+type Gateway interface {
+	// Authorize reserves the amount and returns the authorization ID
+	Authorize(ctx context.Context, req AuthorizeRequest) (string, error)
+	// Capture collects up to the authorized amount and returns the capture ID
+	Capture(ctx context.Context, authorizationID string, amount float64) (string, error)
+	// Void releases an authorization that has not been captured
+	Void(ctx context.Context, authorizationID string) error
+	// Refund gives back up to the captured amount and returns the refund ID
+	Refund(ctx context.Context, captureID string, amount float64) (string, error)
+}
@@ -0,0 +1,22 @@
+package payment
+
+// StartInput is the input DTO for starting the payment of an order
+type StartInput struct {
+	PaymentMethod string
+	// Capture collects the payment right after the authorization
+	Capture bool
+}
+
+// RefundInput is the input DTO for refunding part of a paid order
+type RefundInput struct {
+	Lines  []RefundLine
+	Reason string
+}
+
+// RefundLine asks to refund Quantity units of the order line Line, counted
+// from 1 as the items of the order, so that a variant or a bundle component
+// is refunded on the line it was sold on
+type RefundLine struct {
+	Line     int
+	Quantity int
+}
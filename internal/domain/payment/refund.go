@@ -0,0 +1,255 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"purchase-cart-service/models"
+	"purchase-cart-service/utils"
+)
+
+var ErrInvalidRefund = errors.New("invalid refund lines")
+var ErrNotRefundable = errors.New("order has no captured payment to refund")
+var ErrRefundExceedsQuantity = errors.New("refund exceeds the quantity sold")
+var ErrRefundExceedsCaptured = errors.New("refund exceeds the captured amount")
+var ErrCreditNoteNotFound = errors.New("credit note not found")
+
+// Refund gives back the given quantities of the order lines and issues a
+// credit note for them. The VAT is reversed in proportion to the quantity,
+// from the VAT of the sale; refunding the last units of a line returns
+// exactly what is left of it, so that rounding never leaves cents behind.
+// The note is recorded as pending before the gateway is called, so that a
+// refund whose outcome cannot be stored is still accounted for
+func (s *Service) Refund(ctx context.Context, orderID string, in RefundInput) (*models.CreditNote, error) {
+	if len(in.Lines) == 0 {
+		return nil, ErrInvalidRefund
+	}
+	for _, line := range in.Lines {
+		if line.Line <= 0 || line.Quantity <= 0 {
+			return nil, ErrInvalidRefund
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ord, err := s.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	intent, err := s.capturedIntent(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.creditNotes.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &models.CreditNote{
+		OrderID:   orderID,
+		PaymentID: intent.ID,
+		Reason:    in.Reason,
+		Currency:  intent.Currency,
+	}
+	ledger := newRefundLedger(ord, previous)
+	for _, line := range in.Lines {
+		refunded, err := ledger.refund(line.Line, line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		note.Lines = append(note.Lines, refunded)
+	}
+	for _, line := range note.Lines {
+		note.TotalNet += line.Net
+		note.TotalVAT += line.VAT
+		note.Total += line.Total
+	}
+	note.TotalNet = utils.Round2(note.TotalNet)
+	note.TotalVAT = utils.Round2(note.TotalVAT)
+	note.Total = utils.Round2(note.Total)
+	refunded := refundedAmount(intent.ID, previous)
+	if utils.Round2(refunded+note.Total) > intent.CapturedAmount {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	note.Status = models.CreditNoteStatusPending
+	if err := s.creditNotes.Save(ctx, note); err != nil {
+		return nil, err
+	}
+	refundID, err := s.gateway.Refund(ctx, intent.CaptureID, note.Total)
+	if err != nil {
+		note.Status = models.CreditNoteStatusFailed
+		if updateErr := s.creditNotes.Update(ctx, note); updateErr != nil {
+			log.Printf("payments: credit note %s of order %s left pending after a failed refund: %v", note.ID, orderID, updateErr)
+		}
+		if errors.Is(err, ErrDeclined) {
+			return nil, err
+		}
+		return nil, &GatewayError{Op: OpRefund, Err: err}
+	}
+	note.RefundID = refundID
+	note.Status = models.CreditNoteStatusIssued
+	if err := s.creditNotes.Update(ctx, note); err != nil {
+		// the money has been given back: the pending note keeps the refund
+		// in the ledger and can be reconciled from the gateway reference
+		log.Printf("payments: order %s refunded by %s but credit note %s left pending: %v", orderID, refundID, note.ID, err)
+		return nil, err
+	}
+	intent.RefundedAmount = utils.Round2(refunded + note.Total)
+	if intent.RefundedAmount == intent.CapturedAmount {
+		intent.Status = models.PaymentStatusRefunded
+	}
+	if err := s.payments.Update(ctx, intent); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// refundedAmount is what the credit notes of a payment gave back, or may
+// have given back when still pending
+func refundedAmount(paymentID string, notes []*models.CreditNote) float64 {
+	var total float64
+	for _, note := range notes {
+		if note.PaymentID == paymentID && note.Status != models.CreditNoteStatusFailed {
+			total += note.Total
+		}
+	}
+	return utils.Round2(total)
+}
+
+// GetCreditNotes returns the issued credit notes of an order, oldest first
+func (s *Service) GetCreditNotes(ctx context.Context, orderID string) ([]*models.CreditNote, error) {
+	ord, err := s.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	notes, err := s.creditNotes.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var issued []*models.CreditNote
+	for _, note := range notes {
+		if note.Status == models.CreditNoteStatusIssued {
+			issued = append(issued, note)
+		}
+	}
+	return issued, nil
+}
+
+func (s *Service) GetCreditNote(ctx context.Context, orderID, number string) (*models.CreditNote, error) {
+	note, err := s.creditNotes.GetByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil || note.OrderID != orderID {
+		return nil, ErrCreditNoteNotFound
+	}
+	return note, nil
+}
+
+// capturedIntent returns the captured payment of an order, which is at most one
+func (s *Service) capturedIntent(ctx context.Context, orderID string) (*models.PaymentIntent, error) {
+	intents, err := s.payments.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, intent := range intents {
+		if intent.Status == models.PaymentStatusCaptured {
+			return intent, nil
+		}
+	}
+	return nil, ErrNotRefundable
+}
+
+// refundLedger tracks what is still refundable on each order line
+type refundLedger struct {
+	lines []ledgerLine
+}
+
+type ledgerLine struct {
+	item models.Item
+	// net and vat are the amounts of the sale; the refunded fields what
+	// earlier credit notes, and the current one, already gave back
+	net, vat                 float64
+	refundedQty              int
+	refundedNet, refundedVAT float64
+}
+
+func newRefundLedger(ord *models.Order, previous []*models.CreditNote) *refundLedger {
+	ledger := &refundLedger{}
+	for _, item := range ord.Items {
+		net := item.Net()
+		ledger.lines = append(ledger.lines, ledgerLine{
+			item: item,
+			net:  net,
+			// Item.VAT holds the line total including VAT
+			vat: utils.Round2(item.VAT - net),
+		})
+	}
+	for _, note := range previous {
+		if note.Status == models.CreditNoteStatusFailed {
+			continue
+		}
+		for _, refunded := range note.Lines {
+			ledger.record(refunded)
+		}
+	}
+	return ledger
+}
+
+// record books a credit note line on the order line it refunded
+func (l *refundLedger) record(refunded models.CreditNoteLine) {
+	if refunded.Line < 1 || refunded.Line > len(l.lines) {
+		return
+	}
+	line := &l.lines[refunded.Line-1]
+	line.refundedQty += refunded.Quantity
+	line.refundedNet = utils.Round2(line.refundedNet + refunded.Net)
+	line.refundedVAT = utils.Round2(line.refundedVAT + refunded.VAT)
+}
+
+// refund takes quantity units of the order line n, counted from 1
+func (l *refundLedger) refund(n int, quantity int) (models.CreditNoteLine, error) {
+	if n > len(l.lines) {
+		return models.CreditNoteLine{}, ErrInvalidRefund
+	}
+	line := &l.lines[n-1]
+	available := line.item.Quantity - line.refundedQty
+	if quantity > available {
+		return models.CreditNoteLine{}, ErrRefundExceedsQuantity
+	}
+
+	var net, vat float64
+	if quantity == available {
+		net = utils.Round2(line.net - line.refundedNet)
+		vat = utils.Round2(line.vat - line.refundedVAT)
+	} else {
+		vat = utils.Round2(line.vat * float64(quantity) / float64(line.item.Quantity))
+		if line.item.PriceMode == models.PriceModeGross {
+			// the customer gets back the catalog price of the units
+			net = utils.Round2(float64(quantity)*line.item.UnitPriceWithVAT - vat)
+		} else {
+			net = utils.Round2(float64(quantity) * line.item.UnitPrice)
+		}
+	}
+	line.refundedQty += quantity
+	line.refundedNet = utils.Round2(line.refundedNet + net)
+	line.refundedVAT = utils.Round2(line.refundedVAT + vat)
+	return models.CreditNoteLine{
+		Line:      n,
+		ProductID: line.item.ProductID,
+		SKU:       line.item.SKU,
+		Name:      line.item.Name,
+		Quantity:  quantity,
+		UnitPrice: line.item.UnitPrice,
+		VATRate:   line.item.VATRate,
+		Net:       net,
+		VAT:       vat,
+		Total:     utils.Round2(net + vat),
+	}, nil
+}
@@ -0,0 +1,297 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+var ErrOrderNotPayable = errors.New("order cannot be paid")
+var ErrPaymentNotFound = errors.New("payment not found")
+var ErrInvalidPaymentState = errors.New("payment operation not allowed in the current state")
+var ErrInvalidPaymentMethod = errors.New("invalid payment method")
+
+// GatewayError wraps a technical failure of the gateway, as opposed to a decline
+type GatewayError struct {
+	Op  Operation
+	Err error
+}
+
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("payment gateway %s: %v", e.Op, e.Err)
+}
+
+func (e *GatewayError) Unwrap() error {
+	return e.Err
+}
+
+type Service struct {
+	payments    repository.PaymentRepository
+	creditNotes repository.CreditNoteRepository
+	orders      *order.Service
+	gateway     Gateway
+	currency    string
+
+	// mu serializes the payment operations, so that an order cannot be
+	// authorized, or refunded, twice by concurrent requests
+	mu sync.Mutex
+}
+
+func NewService(payments repository.PaymentRepository, creditNotes repository.CreditNoteRepository, orders *order.Service, gateway Gateway, currency string) *Service {
+	return &Service{
+		payments:    payments,
+		creditNotes: creditNotes,
+		orders:      orders,
+		gateway:     gateway,
+		currency:    currency,
+	}
+}
+
+// StartPayment authorizes the total of an order on the payment method and,
+// when capture is set, captures it at once, advancing the order to paid.
+// A declined authorization is recorded as a failed intent and returned
+// together with an error wrapping ErrDeclined
+func (s *Service) StartPayment(ctx context.Context, orderID string, in StartInput) (*models.PaymentIntent, error) {
+	if in.PaymentMethod == "" {
+		return nil, ErrInvalidPaymentMethod
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ord, err := s.payableOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.payments.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, intent := range existing {
+		if intent.Status == models.PaymentStatusAuthorized || intent.Status == models.PaymentStatusCaptured {
+			return nil, ErrOrderNotPayable
+		}
+	}
+
+	intent := &models.PaymentIntent{
+		ID:            uuid.NewString(),
+		OrderID:       orderID,
+		Amount:        ord.TotalPrice,
+		Currency:      s.currency,
+		PaymentMethod: in.PaymentMethod,
+	}
+	authID, err := s.gateway.Authorize(ctx, AuthorizeRequest{
+		IdempotencyKey: intent.ID,
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		PaymentMethod:  in.PaymentMethod,
+		Reference:      orderID,
+	})
+	if err != nil {
+		if !errors.Is(err, ErrDeclined) {
+			return nil, &GatewayError{Op: OpAuthorize, Err: err}
+		}
+		intent.Status = models.PaymentStatusFailed
+		intent.FailureReason = declineReason(err)
+		if saveErr := s.payments.Save(ctx, intent); saveErr != nil {
+			return nil, saveErr
+		}
+		return intent, err
+	}
+	intent.Status = models.PaymentStatusAuthorized
+	intent.AuthorizationID = authID
+	if err := s.payments.Save(ctx, intent); err != nil {
+		return nil, err
+	}
+	if !in.Capture {
+		return intent, nil
+	}
+	return s.capture(ctx, intent)
+}
+
+// Capture collects an authorized payment and advances the order to paid.
+// The order must still be awaiting payment: the authorizations of a
+// cancelled order are voided asynchronously, and must not be captured meanwhile
+func (s *Service) Capture(ctx context.Context, orderID, paymentID string) (*models.PaymentIntent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent, err := s.get(ctx, orderID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != models.PaymentStatusAuthorized {
+		return nil, ErrInvalidPaymentState
+	}
+	if _, err := s.payableOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.capture(ctx, intent)
+}
+
+// Void releases an authorized payment that has not been captured
+func (s *Service) Void(ctx context.Context, orderID, paymentID string) (*models.PaymentIntent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent, err := s.get(ctx, orderID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.void(ctx, intent)
+}
+
+func (s *Service) GetPayment(ctx context.Context, orderID, paymentID string) (*models.PaymentIntent, error) {
+	return s.get(ctx, orderID, paymentID)
+}
+
+// GetPayments returns the payment intents of an order, oldest first
+func (s *Service) GetPayments(ctx context.Context, orderID string) ([]*models.PaymentIntent, error) {
+	ord, err := s.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.payments.GetByOrderID(ctx, orderID)
+}
+
+// HandleOrderEvent is an events.Handler voiding the open authorizations of
+// cancelled orders
+func (s *Service) HandleOrderEvent(ctx context.Context, envelope events.Envelope) error {
+	cancelled, ok := envelope.Event.(events.OrderCancelled)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intents, err := s.payments.GetByOrderID(ctx, cancelled.Order.ID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, intent := range intents {
+		if intent.Status != models.PaymentStatusAuthorized {
+			continue
+		}
+		if _, err := s.void(ctx, intent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// capture collects the intent and advances the order to paid while holding
+// the order status lock, so that an order cancelled meanwhile is not charged
+func (s *Service) capture(ctx context.Context, intent *models.PaymentIntent) (*models.PaymentIntent, error) {
+	var captureID string
+	_, err := s.orders.PayOrder(ctx, intent.OrderID, func(ctx context.Context) error {
+		var err error
+		captureID, err = s.gateway.Capture(ctx, intent.AuthorizationID, intent.Amount)
+		return err
+	})
+	switch {
+	case err == nil:
+	case captureID != "":
+		// the money has been collected but the order could not be marked
+		// paid: it is given back rather than left on no order
+		return nil, s.compensate(ctx, intent, captureID, err)
+	case err == order.ErrOrderNotFound:
+		return nil, ErrOrderNotFound
+	case err == order.ErrInvalidStatusTransition:
+		return nil, ErrOrderNotPayable
+	case errors.Is(err, ErrDeclined):
+		intent.Status = models.PaymentStatusFailed
+		intent.FailureReason = declineReason(err)
+		if updateErr := s.payments.Update(ctx, intent); updateErr != nil {
+			return nil, updateErr
+		}
+		return intent, err
+	default:
+		return nil, &GatewayError{Op: OpCapture, Err: err}
+	}
+	intent.Status = models.PaymentStatusCaptured
+	intent.CaptureID = captureID
+	intent.CapturedAmount = intent.Amount
+	if err := s.payments.Update(ctx, intent); err != nil {
+		// the order is paid: it can be reconciled from the gateway
+		log.Printf("payments: order %s paid by capture %s but intent %s not updated: %v", intent.OrderID, captureID, intent.ID, err)
+		return nil, err
+	}
+	return intent, nil
+}
+
+// compensate refunds a capture whose order could not be marked paid, and
+// returns the error that prevented it
+func (s *Service) compensate(ctx context.Context, intent *models.PaymentIntent, captureID string, cause error) error {
+	intent.Status = models.PaymentStatusCaptured
+	intent.CaptureID = captureID
+	intent.CapturedAmount = intent.Amount
+	if _, err := s.gateway.Refund(ctx, captureID, intent.Amount); err != nil {
+		log.Printf("payments: order %s captured by %s but neither marked paid nor refunded: %v", intent.OrderID, intent.ID, err)
+	} else {
+		intent.Status = models.PaymentStatusRefunded
+		intent.RefundedAmount = intent.Amount
+	}
+	if err := s.payments.Update(ctx, intent); err != nil {
+		log.Printf("payments: intent %s of order %s not updated after capture %s: %v", intent.ID, intent.OrderID, captureID, err)
+	}
+	return cause
+}
+
+func (s *Service) void(ctx context.Context, intent *models.PaymentIntent) (*models.PaymentIntent, error) {
+	if intent.Status != models.PaymentStatusAuthorized {
+		return nil, ErrInvalidPaymentState
+	}
+	if err := s.gateway.Void(ctx, intent.AuthorizationID); err != nil {
+		if errors.Is(err, ErrDeclined) {
+			return nil, err
+		}
+		return nil, &GatewayError{Op: OpVoid, Err: err}
+	}
+	intent.Status = models.PaymentStatusVoided
+	if err := s.payments.Update(ctx, intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// payableOrder returns the order when it is still awaiting payment
+func (s *Service) payableOrder(ctx context.Context, orderID string) (*order.Detail, error) {
+	ord, err := s.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	if ord.Status != models.OrderStatusCreated {
+		return nil, ErrOrderNotPayable
+	}
+	return ord, nil
+}
+
+func (s *Service) get(ctx context.Context, orderID, paymentID string) (*models.PaymentIntent, error) {
+	intent, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil || intent.OrderID != orderID {
+		return nil, ErrPaymentNotFound
+	}
+	return intent, nil
+}
+
+func declineReason(err error) string {
+	var decline *DeclineError
+	if errors.As(err, &decline) {
+		return decline.Reason
+	}
+	return err.Error()
+}
@@ -0,0 +1,52 @@
+package pricing
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"time"
+)
+
+// Buyer is who a price is for: an API client, and the customer group it
+// belongs to. Either can be empty
+type Buyer struct {
+	Client string
+	Group  string
+}
+
+// Price is the unit price a buyer pays for a product: Amount, lowered by the
+// quantity breaks of Tiers. List is the price list it comes from, empty for
+// the catalog price
+type Price struct {
+	Amount float64
+	List   string
+	Tiers  []models.PriceTier
+}
+
+// At returns the unit price of a line of quantity units
+func (p Price) At(quantity int) float64 {
+	return models.TierPrice(p.Amount, p.Tiers, quantity)
+}
+
+type buyerKey struct{}
+
+// NewContext returns a context carrying the buyer of a request
+func NewContext(ctx context.Context, buyer Buyer) context.Context {
+	return context.WithValue(ctx, buyerKey{}, buyer)
+}
+
+// FromContext returns the buyer of a request, if known
+func FromContext(ctx context.Context) (Buyer, bool) {
+	buyer, ok := ctx.Value(buyerKey{}).(Buyer)
+	return buyer, ok
+}
+
+// ServiceOption customizes a Service
+type ServiceOption func(*Service)
+
+// WithClock sets the source of the time the validity of the prices is
+// checked at, time.Now by default
+func WithClock(now func() time.Time) ServiceOption {
+	return func(s *Service) {
+		s.now = now
+	}
+}
@@ -0,0 +1,148 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Service resolves the price a buyer pays for a product, from the price
+// lists assigned to it or from the catalog
+type Service struct {
+	repo        repository.PriceListRepository
+	productRepo repository.ProductRepository
+	now         func() time.Time
+}
+
+func NewService(repo repository.PriceListRepository, productRepo repository.ProductRepository, opts ...ServiceOption) *Service {
+	s := &Service{repo: repo, productRepo: productRepo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var ErrInvalidPriceList = errors.New("invalid price list")
+
+// Resolve returns the unit price of a product, or of its variant v, for the
+// buyer of ctx, with the price list it comes from and its quantity breaks.
+// The catalog price applies on a nil Service, without a buyer or without a
+// valid list price.
+//
+// The lists assigned to the client take precedence over the ones of its
+// group. At each of the two levels a price for the SKU beats one for the
+// product, and among several lists the lowest price wins
+func (s *Service) Resolve(ctx context.Context, p models.Product, v models.Variant) (Price, error) {
+	buyer, ok := FromContext(ctx)
+	if s == nil || !ok || buyer == (Buyer{}) {
+		return catalogPrice(p, v), nil
+	}
+	lists, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return Price{}, err
+	}
+	var forClient, forGroup []models.PriceList
+	for _, l := range lists {
+		if buyer.Client != "" && slices.Contains(l.Clients, buyer.Client) {
+			forClient = append(forClient, l)
+		}
+		if buyer.Group != "" && slices.Contains(l.CustomerGroups, buyer.Group) {
+			forGroup = append(forGroup, l)
+		}
+	}
+	now := s.now()
+	for _, assigned := range [][]models.PriceList{forClient, forGroup} {
+		for _, id := range []string{v.SKU, p.ID} {
+			if id == "" {
+				continue
+			}
+			if price, ok := lowest(assigned, id, now); ok {
+				return price, nil
+			}
+		}
+	}
+	return catalogPrice(p, v), nil
+}
+
+// catalogPrice is the price of the catalog; the tiers of the product do not
+// apply to a variant with a price of its own
+func catalogPrice(p models.Product, v models.Variant) Price {
+	price := Price{Amount: p.PriceOf(v)}
+	if v.Price <= 0 {
+		price.Tiers = p.Tiers
+	}
+	return price
+}
+
+// lowest finds the lowest price of id valid at now among lists
+func lowest(lists []models.PriceList, id string, now time.Time) (Price, bool) {
+	var price Price
+	for _, l := range lists {
+		for _, p := range l.Prices {
+			if p.ProductID == id && p.ValidAt(now) && (price.List == "" || p.Price < price.Amount) {
+				price = Price{Amount: p.Price, List: l.ID, Tiers: p.Tiers}
+			}
+		}
+	}
+	return price, price.List != ""
+}
+
+// GetAll returns the price lists by ID
+func (s *Service) GetAll(ctx context.Context) ([]models.PriceList, error) {
+	lists, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(lists, func(a, b models.PriceList) int { return strings.Compare(a.ID, b.ID) })
+	return lists, nil
+}
+
+// Save creates or replaces a price list. The list needs a name, and its
+// prices a product or SKU of the catalog, a positive price, valid tiers and
+// a period ending after it starts
+func (s *Service) Save(ctx context.Context, list *models.PriceList) error {
+	if list.ID == "" || list.Name == "" {
+		return fmt.Errorf("%w: ID and name are required", ErrInvalidPriceList)
+	}
+	for i, p := range list.Prices {
+		if p.Price <= 0 {
+			return fmt.Errorf("%w: price %d must be greater than zero", ErrInvalidPriceList, i)
+		}
+		if err := ValidateTiers(p.Tiers); err != nil {
+			return fmt.Errorf("%w: price %d: %v", ErrInvalidPriceList, i, err)
+		}
+		if !p.ValidTo.IsZero() && !p.ValidTo.After(p.ValidFrom) {
+			return fmt.Errorf("%w: price %d must end after it starts", ErrInvalidPriceList, i)
+		}
+		product, err := s.productRepo.GetProduct(ctx, p.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("%w: unknown product %q", ErrInvalidPriceList, p.ProductID)
+		}
+	}
+	return s.repo.Save(ctx, list)
+}
+
+// ValidateTiers checks that the quantity breaks start above one unit, grow
+// with the quantity and have positive prices
+func ValidateTiers(tiers []models.PriceTier) error {
+	for i, t := range tiers {
+		if t.MinQuantity < 2 {
+			return fmt.Errorf("tier %d must start from 2 units or more", i)
+		}
+		if i > 0 && t.MinQuantity <= tiers[i-1].MinQuantity {
+			return fmt.Errorf("tier %d must start above tier %d", i, i-1)
+		}
+		if t.Price <= 0 {
+			return fmt.Errorf("tier %d price must be greater than zero", i)
+		}
+	}
+	return nil
+}
@@ -1,5 +1,11 @@
 package product
 
+import (
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/models"
+)
+
 type Detail struct {
 	ID           string
 	Name         string
@@ -7,4 +13,79 @@ type Detail struct {
 	VAT          float64
 	PriceWithVAT float64
 	Price        float64
+	// PriceMode is the mode the catalog price is set in: Price is the net
+	// price in both modes, PriceWithVAT the one including VAT
+	PriceMode string
+	// TaxClass is the class VAT is the rate of, and CategoryIDs the
+	// categories the product is listed in
+	TaxClass    string
+	CategoryIDs []string
+	// PriceList is the price list Price comes from, empty for the catalog
+	// price
+	PriceList string
+	// Tiers are the quantity breaks of Price for the buyer, lowest first
+	Tiers []Tier
+	// Options and Variants are the variant matrix of a product sold in
+	// several versions: the values of each option, and the SKUs
+	Options  []VariantOption
+	Variants []VariantDetail
+}
+
+// VariantOption is an attribute the variants of a product differ in, such
+// as the size, with its values in catalog order
+type VariantOption struct {
+	Name   string
+	Values []string
+}
+
+// VariantDetail is a SKU of a product, with its own prices and stock
+type VariantDetail struct {
+	SKU          string
+	Attributes   map[string]string
+	Price        float64
+	PriceWithVAT float64
+	PriceList    string
+	Tiers        []Tier
+	Stock        int
+}
+
+// Tier is a quantity break: from MinQuantity units on, each unit of the
+// line costs Price, PriceWithVAT including VAT
+type Tier struct {
+	MinQuantity  int
+	Price        float64
+	PriceWithVAT float64
+}
+
+// ServiceOption customizes a Service
+type ServiceOption func(*Service)
+
+// WithPriceMode sets the catalog price mode, models.PriceModeNet (the
+// default) or models.PriceModeGross, used for the products without their own
+func WithPriceMode(mode string) ServiceOption {
+	return func(s *Service) {
+		s.priceMode = mode
+	}
+}
+
+// WithCategories resolves the tax class of the products through their
+// categories, and enables the category listings
+func WithCategories(categories *category.Service) ServiceOption {
+	return func(s *Service) {
+		s.categories = categories
+	}
+}
+
+// WithPricing prices the products for the buyer of the request, from the
+// price lists assigned to it
+func WithPricing(pricing *pricing.Service) ServiceOption {
+	return func(s *Service) {
+		s.pricing = pricing
+	}
+}
+
+// validMode reports whether mode is a price mode; empty stands for the
+// catalog default
+func validMode(mode string) bool {
+	return mode == "" || mode == models.PriceModeNet || mode == models.PriceModeGross
 }
@@ -2,46 +2,100 @@ package product
 
 import (
 	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
 	"purchase-cart-service/repository"
 	"purchase-cart-service/utils"
+	"slices"
+	"sort"
+	"sync"
 )
 
 type Service struct {
 	productRepo repository.ProductRepository
 	vatRepo     repository.VatRateRepository
+	// priceMode is the catalog default of the products without their own
+	priceMode string
+	// categories resolve the tax class of the products, nil when the
+	// catalog has no taxonomy
+	categories *category.Service
+	// pricing resolves the prices of the price lists, nil to always use the
+	// catalog prices
+	pricing *pricing.Service
+
+	// priceMu serializes price changes so that every event carries the price it replaced
+	priceMu sync.Mutex
 }
 
-func NewService(productRepo repository.ProductRepository, vatRepo repository.VatRateRepository) *Service {
-	return &Service{
+func NewService(productRepo repository.ProductRepository, vatRepo repository.VatRateRepository, opts ...ServiceOption) *Service {
+	s := &Service{
 		productRepo: productRepo,
 		vatRepo:     vatRepo,
+		priceMode:   models.PriceModeNet,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
+var ErrInvalidVATRate = errors.New("invalid VAT rate")
+var ErrProductNotFound = errors.New("product not found")
+var ErrInvalidPrice = errors.New("invalid product price")
+var ErrInvalidPriceMode = errors.New("invalid product price mode")
+
 func (s *Service) GetAllProducts(ctx context.Context, countryCode string) ([]Detail, error) {
 	products, err := s.productRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 	var productsDetail []Detail
-	vatRate, err := s.vatRepo.GetVATRate(countryCode)
+	if _, err := s.vatRepo.GetVATRate(countryCode); err != nil {
+		return nil, ErrInvalidVATRate
+	}
+	for _, p := range products {
+		detail, err := s.detail(ctx, p, countryCode)
+		if err != nil {
+			return nil, err
+		}
+		productsDetail = append(productsDetail, detail)
+	}
+	return productsDetail, nil
+}
+
+// GetProductsByCategory returns the products of a category and of its
+// descendants, by product ID
+func (s *Service) GetProductsByCategory(ctx context.Context, slug string, countryCode string) ([]Detail, error) {
+	if s.categories == nil {
+		return nil, category.ErrCategoryNotFound
+	}
+	_, ids, err := s.categories.Subtree(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.productRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	details := []Detail{}
 	for _, p := range products {
-
-		vat := utils.Round2(p.Price * vatRate)
-		productsDetail = append(productsDetail, Detail{
-			ID:           p.ID,
-			Name:         p.Name,
-			Description:  p.Description,
-			PriceWithVAT: utils.Round2(p.Price + vat),
-			Price:        p.Price,
-			VAT:          vatRate,
-		})
+		if !slices.ContainsFunc(p.CategoryIDs, func(id string) bool { return slices.Contains(ids, id) }) {
+			continue
+		}
+		detail, err := s.detail(ctx, p, countryCode)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
 	}
-	return productsDetail, nil
+	return details, nil
 }
+
 func (s *Service) GetProductByID(ctx context.Context, productID string, countryCode string) (*Detail, error) {
 	product, err := s.productRepo.GetProduct(ctx, productID)
 	if err != nil {
@@ -50,17 +104,201 @@ func (s *Service) GetProductByID(ctx context.Context, productID string, countryC
 	if product == nil {
 		return nil, nil
 	}
-	vatRate, err := s.vatRepo.GetVATRate(countryCode)
+	detail, err := s.detail(ctx, *product, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// GetProductsByIDs looks up several products with a single repository call;
+// unknown IDs are absent from the result
+func (s *Service) GetProductsByIDs(ctx context.Context, productIDs []string, countryCode string) (map[string]Detail, error) {
+	if _, err := s.vatRepo.GetVATRate(countryCode); err != nil {
+		return nil, ErrInvalidVATRate
+	}
+	products, err := s.productRepo.GetProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	details := make(map[string]Detail, len(products))
+	for id, p := range products {
+		detail, err := s.detail(ctx, p, countryCode)
+		if err != nil {
+			return nil, err
+		}
+		details[id] = detail
+	}
+	return details, nil
+}
+
+// UpdatePrice changes the price of a product, net or including VAT as mode
+// says, and raises ProductPriceChanged. An empty mode keeps the current one;
+// setting the current price and mode is a no-op. Given a SKU, it changes the
+// price of the variant, in the mode of its product
+func (s *Service) UpdatePrice(ctx context.Context, productID string, price float64, mode string) (*models.Product, error) {
+	if price <= 0 {
+		return nil, ErrInvalidPrice
+	}
+	if !validMode(mode) {
+		return nil, ErrInvalidPriceMode
+	}
+	s.priceMu.Lock()
+	defer s.priceMu.Unlock()
+	product, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	price = utils.Round2(price)
+	if product.ID != productID {
+		return s.updateVariantPrice(ctx, product, productID, price, mode)
+	}
+	if mode == "" {
+		mode = product.PriceMode
+	}
+	if product.Price == price && product.PriceMode == mode {
+		return product, nil
+	}
+	msg, err := events.NewOutboxMessage(events.ProductPriceChanged{
+		ProductID: product.ID,
+		OldPrice:  product.Price,
+		NewPrice:  price,
+		PriceMode: mode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	product.Price = price
+	product.PriceMode = mode
+	if err := s.productRepo.Update(ctx, product, msg); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// updateVariantPrice overrides the price of the variant sku of product
+func (s *Service) updateVariantPrice(ctx context.Context, product *models.Product, sku string, price float64, mode string) (*models.Product, error) {
+	if mode != "" && mode != s.mode(*product) {
+		return nil, ErrInvalidPriceMode
+	}
+	variants := slices.Clone(product.Variants)
+	i := slices.IndexFunc(variants, func(v models.Variant) bool { return v.SKU == sku })
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	old := product.PriceOf(variants[i])
+	if variants[i].Price == price {
+		return product, nil
+	}
+	msg, err := events.NewOutboxMessage(events.ProductPriceChanged{
+		ProductID: product.ID,
+		SKU:       sku,
+		OldPrice:  old,
+		NewPrice:  price,
+		PriceMode: product.PriceMode,
+	})
 	if err != nil {
 		return nil, err
 	}
-	vat := utils.Round2(product.Price * vatRate)
-	return &Detail{
-		ID:           product.ID,
-		Name:         product.Name,
-		Description:  product.Description,
-		PriceWithVAT: utils.Round2(product.Price + vat),
-		Price:        product.Price,
+	variants[i].Price = price
+	product.Variants = variants
+	if err := s.productRepo.Update(ctx, product, msg); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// mode returns the price mode of a product, the catalog default when the
+// product has none
+func (s *Service) mode(p models.Product) string {
+	if p.PriceMode != "" {
+		return p.PriceMode
+	}
+	return s.priceMode
+}
+
+// detail describes a product with the VAT rate of its tax class in a country,
+// at the prices of the buyer of ctx
+func (s *Service) detail(ctx context.Context, p models.Product, countryCode string) (Detail, error) {
+	class, err := s.categories.TaxClass(ctx, p)
+	if err != nil {
+		return Detail{}, err
+	}
+	vatRate, err := s.vatRepo.GetVATRateForClass(countryCode, class)
+	if err != nil {
+		return Detail{}, ErrInvalidVATRate
+	}
+	// the variants are priced first, as they fall back on the catalog price
+	// of the product
+	variants := make([]models.Variant, len(p.Variants))
+	prices := make([]pricing.Price, len(p.Variants))
+	for i, v := range p.Variants {
+		if prices[i], err = s.pricing.Resolve(ctx, p, v); err != nil {
+			return Detail{}, err
+		}
+		v.Price = prices[i].Amount
+		variants[i] = v
+	}
+	price, err := s.pricing.Resolve(ctx, p, models.Variant{})
+	if err != nil {
+		return Detail{}, err
+	}
+	p.Price, p.Variants = price.Amount, variants
+	detail := s.toDetail(p, vatRate)
+	detail.TaxClass = class
+	detail.PriceList = price.List
+	detail.Tiers = tiers(price.Tiers, detail.PriceMode, vatRate)
+	for i := range detail.Variants {
+		detail.Variants[i].PriceList = prices[i].List
+		detail.Variants[i].Tiers = tiers(prices[i].Tiers, detail.PriceMode, vatRate)
+	}
+	return detail, nil
+}
+
+// tiers describes the quantity breaks of a price with and without VAT
+func tiers(breaks []models.PriceTier, mode string, vatRate float64) []Tier {
+	var out []Tier
+	for _, t := range breaks {
+		tier := Tier{MinQuantity: t.MinQuantity}
+		tier.Price, tier.PriceWithVAT = tax.UnitPrices(t.Price, mode, vatRate)
+		out = append(out, tier)
+	}
+	return out
+}
+
+func (s *Service) toDetail(p models.Product, vatRate float64) Detail {
+	mode := s.mode(p)
+	net, gross := tax.UnitPrices(p.Price, mode, vatRate)
+	detail := Detail{
+		ID:           p.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		PriceWithVAT: gross,
+		Price:        net,
 		VAT:          vatRate,
-	}, nil
+		PriceMode:    mode,
+		CategoryIDs:  p.CategoryIDs,
+	}
+	options := make(map[string]int)
+	for _, v := range p.Variants {
+		variant := VariantDetail{SKU: v.SKU, Attributes: make(map[string]string, len(v.Attributes)), Stock: v.Stock}
+		variant.Price, variant.PriceWithVAT = tax.UnitPrices(p.PriceOf(v), mode, vatRate)
+		for _, a := range v.Attributes {
+			variant.Attributes[a.Name] = a.Value
+			i, ok := options[a.Name]
+			if !ok {
+				i = len(detail.Options)
+				options[a.Name] = i
+				detail.Options = append(detail.Options, VariantOption{Name: a.Name})
+			}
+			if !slices.Contains(detail.Options[i].Values, a.Value) {
+				detail.Options[i].Values = append(detail.Options[i].Values, a.Value)
+			}
+		}
+		detail.Variants = append(detail.Variants, variant)
+	}
+	return detail
 }
@@ -0,0 +1,103 @@
+package rounding
+
+import (
+	"math"
+	"strings"
+)
+
+// Levels at which the tax of an order is rounded to the cent
+const (
+	// PerUnit rounds the tax of a single unit, multiplied by the quantity
+	PerUnit = "unit"
+	// PerLine rounds the tax of every order line
+	PerLine = "line"
+	// PerTotal rounds once the tax of all the lines at the same rate, and
+	// spreads the rounded amount over the lines
+	PerTotal = "total"
+)
+
+// Methods to round an amount to the cent
+const (
+	// HalfUp rounds ties away from zero: 0.125 -> 0.13
+	HalfUp = "half-up"
+	// HalfEven rounds ties to the even cent: 0.125 -> 0.12, 0.135 -> 0.14
+	HalfEven = "half-even"
+	// Bankers is the usual name of HalfEven in accounting
+	Bankers = "bankers"
+)
+
+// Policy is how the tax of a sale is rounded: at which Level and with
+// which Method
+type Policy struct {
+	Level  string
+	Method string
+}
+
+// Default is the policy of the countries without their own: every line
+// rounded half-up
+var Default = Policy{Level: PerLine, Method: HalfUp}
+
+// Round rounds an amount to the cent with the method of the policy
+func (p Policy) Round(amount float64) float64 {
+	cents := amount * 100
+	// drop the float noise, so that 1.005 is a tie and not 100.49999...
+	cents = math.Round(cents*1e6) / 1e6
+	if p.Method == HalfEven || p.Method == Bankers {
+		return math.RoundToEven(cents) / 100
+	}
+	return math.Round(cents) / 100
+}
+
+// Spread splits a rounded total among amounts in proportion to their
+// unrounded values, with the largest remainder method: every share is
+// within a cent of its amount and the shares add up to total exactly
+func Spread(total float64, amounts []float64) []float64 {
+	shares := make([]float64, len(amounts))
+	type remainder struct {
+		index int
+		value float64
+	}
+	remainders := make([]remainder, len(amounts))
+	var assigned int64
+	for i, a := range amounts {
+		cents := math.Floor(math.Round(a*100*1e6) / 1e6)
+		shares[i] = cents
+		assigned += int64(cents)
+		remainders[i] = remainder{i, a*100 - cents}
+	}
+	left := int64(math.Round(total*100)) - assigned
+	// the largest remainders take the cents left; ties go to the first lines
+	for left > 0 && len(remainders) > 0 {
+		best := 0
+		for i, r := range remainders {
+			if r.value > remainders[best].value {
+				best = i
+			}
+		}
+		shares[remainders[best].index]++
+		remainders = append(remainders[:best], remainders[best+1:]...)
+		left--
+	}
+	for i := range shares {
+		shares[i] /= 100
+	}
+	return shares
+}
+
+// Policies are the rounding policies by country, with a default for the
+// others
+type Policies struct {
+	Default   Policy
+	Countries map[string]Policy
+}
+
+// For returns the policy of a country, by ISO 3166 code
+func (p Policies) For(countryCode string) Policy {
+	if policy, ok := p.Countries[strings.ToUpper(countryCode)]; ok {
+		return policy
+	}
+	if p.Default == (Policy{}) {
+		return Default
+	}
+	return p.Default
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// folding lowers the accented letters of Italian, and of the loanwords of
+// both languages, to their plain form
+var folding = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "’", "'",
+)
+
+// stopwords of English and Italian, never indexed nor searched
+var stopwords = toSet(
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in", "is", "it", "of", "on", "or", "the", "to", "with",
+	"ai", "agli", "al", "alla", "alle", "con", "da", "dal", "dalla", "dei", "degli", "del", "della", "delle", "di", "e",
+	"fra", "gli", "i", "il", "la", "le", "lo", "nel", "nella", "o", "per", "su", "sul", "sulla", "tra", "un", "una", "uno",
+)
+
+// elisions are the Italian words losing their vowel before an apostrophe,
+// as in l'acqua or dell'olio
+var elisions = toSet("l", "un", "d", "dell", "all", "dall", "nell", "sull", "quest", "quell", "c")
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// words splits text into lowercase words without accents, dropping the
+// stopwords, the elided Italian articles and the English possessive
+func words(text string) []string {
+	var out []string
+	isSeparator := func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}
+	for _, field := range strings.FieldsFunc(folding.Replace(strings.ToLower(text)), isSeparator) {
+		parts := strings.Split(field, "'")
+		for i, part := range parts {
+			last := i == len(parts)-1
+			if part == "" || stopwords[part] || (!last && elisions[part]) || (i > 0 && part == "s") {
+				continue
+			}
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// forms returns a word with its English and Italian stems, so that singular
+// and plural find each other in both languages
+func forms(word string) []string {
+	out := []string{word}
+	if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+		return out
+	}
+	for _, stem := range []string{stemEnglish(word), stemItalian(word)} {
+		if stem != word && (len(out) == 1 || out[1] != stem) {
+			out = append(out, stem)
+		}
+	}
+	return out
+}
+
+// stemEnglish drops the plural endings: batteries, boxes, shirts
+func stemEnglish(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") ||
+		strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes") || strings.HasSuffix(w, "zes")):
+		return w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") &&
+		!strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// stemItalian drops the final vowel marking gender and number, so that
+// libro and libri, maglietta and magliette share a stem; the h keeping the
+// hard sound of tasche and laghi goes with it
+func stemItalian(w string) string {
+	if len(w) <= 3 || !strings.ContainsRune("aeio", rune(w[len(w)-1])) {
+		return w
+	}
+	w = w[:len(w)-1]
+	if strings.HasSuffix(w, "ch") || strings.HasSuffix(w, "gh") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// prefixWeight scales the score of a word matching only the beginning of a
+// term, so that exact matches rank first
+const prefixWeight = 0.5
+
+// minPrefix is the length a word needs to match as a prefix: a single
+// letter would match most of the catalog
+const minPrefix = 2
+
+// Document is what the index knows of a product: its ID and the texts to
+// search, each with its weight in the relevance
+type Document struct {
+	ID     string
+	Fields []Field
+}
+
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Index is an inverted index of documents: every term points to the
+// documents containing it, with the weight of the heaviest field it is in
+type Index struct {
+	ids []string
+	// terms are the indexed terms in order, for the prefix lookups
+	terms    []string
+	postings map[string]map[int]float64
+}
+
+// Match is a document matching a query, with its relevance
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// NewIndex indexes documents with their words and the stems of the words
+func NewIndex(documents []Document) *Index {
+	x := &Index{postings: make(map[string]map[int]float64)}
+	for i, d := range documents {
+		x.ids = append(x.ids, d.ID)
+		for _, f := range d.Fields {
+			for _, w := range words(f.Text) {
+				for _, term := range forms(w) {
+					docs, ok := x.postings[term]
+					if !ok {
+						docs = make(map[int]float64)
+						x.postings[term] = docs
+						x.terms = append(x.terms, term)
+					}
+					docs[i] = math.Max(docs[i], f.Weight)
+				}
+			}
+		}
+	}
+	sort.Strings(x.terms)
+	return x
+}
+
+// Len is the number of documents in the index
+func (x *Index) Len() int {
+	return len(x.ids)
+}
+
+// Match returns the documents containing every word of the query, as a
+// whole or as the beginning of a longer term, the most relevant first. A
+// word scores the weight of the field it is in times its inverse document
+// frequency, so rarer terms count more; the scores of the words add up
+func (x *Index) Match(query string) []Match {
+	queryWords := words(query)
+	if len(queryWords) == 0 {
+		return nil
+	}
+	var scores map[int]float64
+	for _, w := range queryWords {
+		found := x.matchWord(w)
+		if scores == nil {
+			scores = found
+			continue
+		}
+		for doc, score := range scores {
+			if s, ok := found[doc]; ok {
+				scores[doc] = score + s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+	matches := make([]Match, 0, len(scores))
+	for doc, score := range scores {
+		matches = append(matches, Match{ID: x.ids[doc], Score: score})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// matchWord scores the documents containing a form of word, or a term it
+// is the prefix of, keeping the best score of every document
+func (x *Index) matchWord(word string) map[int]float64 {
+	scores := make(map[int]float64)
+	add := func(term string, factor float64) {
+		docs := x.postings[term]
+		idf := math.Log(1 + float64(len(x.ids))/float64(len(docs)))
+		for doc, weight := range docs {
+			scores[doc] = math.Max(scores[doc], weight*idf*factor)
+		}
+	}
+	for _, term := range forms(word) {
+		if _, ok := x.postings[term]; ok {
+			add(term, 1)
+		}
+	}
+	if len(word) < minPrefix {
+		return scores
+	}
+	for i := sort.SearchStrings(x.terms, word); i < len(x.terms) && strings.HasPrefix(x.terms[i], word); i++ {
+		if x.terms[i] != word {
+			add(x.terms[i], prefixWeight)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,69 @@
+package search
+
+import "purchase-cart-service/internal/domain/product"
+
+// Field weights of the product texts in the relevance
+const (
+	weightName        = 3
+	weightCategory    = 2
+	weightDescription = 1
+	weightVariant     = 1
+)
+
+// DefaultLimit and MaxLimit bound the hits of a search
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// PriceBounds split the price facet into ranges: below 10, from 10 to 25,
+// and so on up to 100 and over
+var PriceBounds = []float64{10, 25, 50, 100}
+
+// Query is a full-text search of the catalog, optionally restricted to a
+// category and its descendants, by slug, and to a price range, including
+// VAT in CountryCode; a zero MaxPrice leaves the range open
+type Query struct {
+	Text        string
+	CountryCode string
+	Category    string
+	MinPrice    float64
+	MaxPrice    float64
+	// Limit caps the hits, DefaultLimit when zero
+	Limit int
+}
+
+// Result lists the products matching a query, the most relevant first.
+// Total counts all the matches, beyond the limit
+type Result struct {
+	Total  int
+	Hits   []Hit
+	Facets Facets
+}
+
+type Hit struct {
+	product.Detail
+	Score float64
+}
+
+// Facets count the matches by category and by price range. Each facet
+// ignores its own filter, so it shows the alternatives to the current one
+type Facets struct {
+	Categories []CategoryFacet
+	Prices     []PriceFacet
+}
+
+// CategoryFacet counts the matches in a category or in its descendants
+type CategoryFacet struct {
+	Slug  string
+	Name  string
+	Count int
+}
+
+// PriceFacet counts the matches priced from From up to To excluded; the
+// last range has no To
+type PriceFacet struct {
+	From  float64
+	To    float64
+	Count int
+}
@@ -0,0 +1,244 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Service searches the catalog through an in-process index, built from the
+// product repository on the first search and rebuilt after the catalog
+// changes
+type Service struct {
+	productRepo repository.ProductRepository
+	products    *product.Service
+	// categories index the category names and enable the category facet,
+	// nil when the catalog has no taxonomy
+	categories *category.Service
+
+	mu      sync.Mutex
+	catalog *catalog
+}
+
+// catalog is the index of the products with their categories, ancestors
+// included, by product ID
+type catalog struct {
+	index      *Index
+	categories map[string][]string
+	// bySlug and byID are the categories of the tree
+	bySlug map[string]models.Category
+	byID   map[string]models.Category
+}
+
+func NewService(productRepo repository.ProductRepository, products *product.Service, categories *category.Service) *Service {
+	return &Service{productRepo: productRepo, products: products, categories: categories}
+}
+
+var ErrEmptyQuery = errors.New("empty search query")
+
+// ProductEventTypes are the events changing the catalog, after which the
+// index is rebuilt
+var ProductEventTypes = []string{events.TypeProductPriceChanged}
+
+// HandleProductEvent is an events.Handler dropping the index when the
+// catalog changes; the next search rebuilds it
+func (s *Service) HandleProductEvent(ctx context.Context, envelope events.Envelope) error {
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate drops the index, rebuilt by the next search
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catalog = nil
+}
+
+// current returns the index, building it when missing
+func (s *Service) current(ctx context.Context) (*catalog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.catalog != nil {
+		return s.catalog, nil
+	}
+	c, err := s.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.catalog = c
+	return c, nil
+}
+
+func (s *Service) build(ctx context.Context) (*catalog, error) {
+	c := &catalog{
+		categories: make(map[string][]string),
+		bySlug:     make(map[string]models.Category),
+		byID:       make(map[string]models.Category),
+	}
+	if s.categories != nil {
+		tree, err := s.categories.Tree(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.addCategories(tree)
+	}
+	products, err := s.productRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]Document, 0, len(products))
+	for _, p := range products {
+		d := Document{ID: p.ID, Fields: []Field{
+			{Text: p.Name, Weight: weightName},
+			{Text: p.Description, Weight: weightDescription},
+		}}
+		for _, v := range p.Variants {
+			d.Fields = append(d.Fields, Field{Text: v.Label(), Weight: weightVariant})
+		}
+		for _, id := range c.lineage(p.CategoryIDs) {
+			c.categories[p.ID] = append(c.categories[p.ID], id)
+			d.Fields = append(d.Fields, Field{Text: c.byID[id].Name, Weight: weightCategory})
+		}
+		documents = append(documents, d)
+	}
+	c.index = NewIndex(documents)
+	return c, nil
+}
+
+func (c *catalog) addCategories(nodes []*category.Node) {
+	for _, n := range nodes {
+		c.bySlug[n.Slug] = n.Category
+		c.byID[n.ID] = n.Category
+		c.addCategories(n.Children)
+	}
+}
+
+// lineage returns the known categories among ids with their ancestors,
+// each once
+func (c *catalog) lineage(ids []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		for {
+			cat, ok := c.byID[id]
+			if !ok || seen[id] {
+				break
+			}
+			seen[id] = true
+			out = append(out, id)
+			id = cat.ParentID
+		}
+	}
+	return out
+}
+
+// Search returns the products matching the words of the query, by
+// relevance, with the facets of the matches. It fails with
+// product.ErrInvalidVATRate for an unknown country and with
+// category.ErrCategoryNotFound for an unknown category
+func (s *Service) Search(ctx context.Context, q Query) (*Result, error) {
+	if strings.TrimSpace(q.Text) == "" {
+		return nil, ErrEmptyQuery
+	}
+	c, err := s.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var filter models.Category
+	if q.Category != "" {
+		var ok bool
+		if filter, ok = c.bySlug[q.Category]; !ok {
+			return nil, category.ErrCategoryNotFound
+		}
+	}
+	matches := c.index.Match(q.Text)
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	details, err := s.products.GetProductsByIDs(ctx, ids, q.CountryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Hits: []Hit{}}
+	byCategory := make(map[string]int)
+	byPrice := make([]int, len(PriceBounds)+1)
+	for _, m := range matches {
+		// a product removed since the index was built is skipped
+		detail, ok := details[m.ID]
+		if !ok {
+			continue
+		}
+		inCategory := filter.ID == "" || slices.Contains(c.categories[m.ID], filter.ID)
+		inPrice := detail.PriceWithVAT >= q.MinPrice && (q.MaxPrice == 0 || detail.PriceWithVAT <= q.MaxPrice)
+		if inPrice {
+			for _, id := range c.categories[m.ID] {
+				byCategory[id]++
+			}
+		}
+		if inCategory {
+			byPrice[priceRange(detail.PriceWithVAT)]++
+		}
+		if inCategory && inPrice {
+			result.Hits = append(result.Hits, Hit{Detail: detail, Score: m.Score})
+		}
+	}
+	result.Total = len(result.Hits)
+	limit := min(q.Limit, MaxLimit)
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(result.Hits) > limit {
+		result.Hits = result.Hits[:limit]
+	}
+	result.Facets = Facets{Categories: c.categoryFacets(byCategory), Prices: priceFacets(byPrice)}
+	return result, nil
+}
+
+// categoryFacets orders the categories by matches, then by name
+func (c *catalog) categoryFacets(counts map[string]int) []CategoryFacet {
+	facets := []CategoryFacet{}
+	for id, count := range counts {
+		facets = append(facets, CategoryFacet{Slug: c.byID[id].Slug, Name: c.byID[id].Name, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Name < facets[j].Name
+	})
+	return facets
+}
+
+// priceRange returns the index of the range of PriceBounds a price is in
+func priceRange(price float64) int {
+	return sort.Search(len(PriceBounds), func(i int) bool { return price < PriceBounds[i] })
+}
+
+// priceFacets lists the ranges with matches, cheapest first
+func priceFacets(counts []int) []PriceFacet {
+	facets := []PriceFacet{}
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		f := PriceFacet{Count: count}
+		if i > 0 {
+			f.From = PriceBounds[i-1]
+		}
+		if i < len(PriceBounds) {
+			f.To = PriceBounds[i]
+		}
+		facets = append(facets, f)
+	}
+	return facets
+}
@@ -0,0 +1,90 @@
+package tax
+
+import (
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/models"
+)
+
+// Line is an order line to tax: Quantity units at UnitPrice, which includes
+// the tax when Gross is set
+type Line struct {
+	Quantity  int
+	UnitPrice float64
+	Gross     bool
+	Rate      models.TaxRate
+}
+
+// LineAmounts are the rounded amounts of a Line: Net plus Tax is Total, and
+// Shares split Tax among the jurisdictions of the rate
+type LineAmounts struct {
+	Net    float64
+	Tax    float64
+	Total  float64
+	Shares []models.LineTax
+}
+
+// Compute taxes the lines of an order with policy. The price paid for a
+// gross line is always its catalog price: rounding only moves cents
+// between its net and its tax. With rounding.PerTotal the tax of the lines
+// at the same rate is rounded once and spread over them, so the order tax
+// is the rounded tax of each rate
+func Compute(lines []Line, policy rounding.Policy) []LineAmounts {
+	amounts := make([]LineAmounts, len(lines))
+	prices := make([]float64, len(lines))
+	exact := make([]float64, len(lines))
+	for i, l := range lines {
+		prices[i] = policy.Round(float64(l.Quantity) * l.UnitPrice)
+		factor := l.Rate.Rate
+		if l.Gross {
+			factor = l.Rate.Rate / (1 + l.Rate.Rate)
+		}
+		switch policy.Level {
+		case rounding.PerUnit:
+			amounts[i].Tax = policy.Round(float64(l.Quantity) * policy.Round(l.UnitPrice*factor))
+		case rounding.PerTotal:
+			exact[i] = prices[i] * factor
+		default:
+			amounts[i].Tax = policy.Round(prices[i] * factor)
+		}
+	}
+	if policy.Level == rounding.PerTotal {
+		spreadByRate(lines, exact, amounts, policy)
+	}
+	for i, l := range lines {
+		a := &amounts[i]
+		if l.Gross {
+			a.Total = prices[i]
+			a.Net = policy.Round(a.Total - a.Tax)
+		} else {
+			a.Net = prices[i]
+			a.Total = policy.Round(a.Net + a.Tax)
+		}
+		a.Shares = share(a.Net, a.Tax, l.Rate)
+	}
+	return amounts
+}
+
+// spreadByRate rounds the sum of the exact tax of the lines at each rate and
+// spreads it over them
+func spreadByRate(lines []Line, exact []float64, amounts []LineAmounts, policy rounding.Policy) {
+	groups := make(map[float64][]int)
+	var rates []float64
+	for i, l := range lines {
+		if _, ok := groups[l.Rate.Rate]; !ok {
+			rates = append(rates, l.Rate.Rate)
+		}
+		groups[l.Rate.Rate] = append(groups[l.Rate.Rate], i)
+	}
+	for _, rate := range rates {
+		indexes := groups[rate]
+		values := make([]float64, len(indexes))
+		var sum float64
+		for j, i := range indexes {
+			values[j] = exact[i]
+			sum += exact[i]
+		}
+		for j, tax := range rounding.Spread(policy.Round(sum), values) {
+			amounts[indexes[j]].Tax = tax
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package tax
+
+// Sourcing tells whose local rates apply to a sale shipped within a state
+type Sourcing int
+
+const (
+	// DestinationBased states tax a sale at the rates of the delivery address
+	DestinationBased Sourcing = iota
+	// OriginBased states tax a sale at the rates of the seller location
+	OriginBased
+	// ModifiedOrigin is the California rule: state, county and city taxes
+	// of the seller location, district taxes of the delivery address
+	ModifiedOrigin
+)
+
+// stateSourcing lists the states that do not source intrastate sales at
+// destination. Sales shipped to another state are always destination-based
+var stateSourcing = map[string]Sourcing{
+	"AZ": OriginBased,
+	"CA": ModifiedOrigin,
+	"IL": OriginBased,
+	"MO": OriginBased,
+	"MS": OriginBased,
+	"NM": OriginBased,
+	"OH": OriginBased,
+	"PA": OriginBased,
+	"TN": OriginBased,
+	"TX": OriginBased,
+	"UT": OriginBased,
+	"VA": OriginBased,
+}
+
+// StateSourcing returns the sourcing rule of intrastate sales in a state
+func StateSourcing(state string) Sourcing {
+	return stateSourcing[state]
+}
@@ -0,0 +1,119 @@
+package tax
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"purchase-cart-service/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRateTable = errors.New("invalid sales tax table")
+
+// rateColumns are the columns of the sales tax table, in any order
+var rateColumns = []string{"state", "zip", "state_rate", "county", "county_rate", "city", "city_rate", "special", "special_rate"}
+
+var (
+	statePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	zipPattern   = regexp.MustCompile(`^[0-9]{5}$`)
+)
+
+// LoadRatesFile reads the sales tax table from a CSV file, see ReadRates
+func LoadRatesFile(path string) ([]models.SalesTaxRegion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRates(f)
+}
+
+// ReadRates parses a sales tax table: a CSV with a header naming the
+// columns in rateColumns and one row per ZIP code, rates as fractions
+// (0.0625 for 6.25%). Lines starting with # are comments
+func ReadRates(r io.Reader) ([]models.SalesTaxRegion, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidRateTable, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range rateColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidRateTable, column)
+		}
+	}
+
+	var regions []models.SalesTaxRegion
+	seen := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRateTable, err)
+		}
+		line, _ := reader.FieldPos(0)
+		region, err := parseRegion(func(column string) string { return strings.TrimSpace(record[index[column]]) })
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRateTable, line, err)
+		}
+		key := region.State + region.PostalCode
+		if seen[key] {
+			return nil, fmt.Errorf("%w: line %d: duplicate ZIP code %s %s", ErrInvalidRateTable, line, region.State, region.PostalCode)
+		}
+		seen[key] = true
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+func parseRegion(field func(column string) string) (models.SalesTaxRegion, error) {
+	region := models.SalesTaxRegion{
+		State:      strings.ToUpper(field("state")),
+		PostalCode: field("zip"),
+		County:     field("county"),
+		City:       field("city"),
+		Special:    field("special"),
+	}
+	if !statePattern.MatchString(region.State) {
+		return region, fmt.Errorf("state must be a two letter code, got %q", region.State)
+	}
+	if !zipPattern.MatchString(region.PostalCode) {
+		return region, fmt.Errorf("zip must have five digits, got %q", region.PostalCode)
+	}
+	for _, rate := range []struct {
+		column string
+		name   string
+		value  *float64
+	}{
+		{"state_rate", region.State, &region.StateRate},
+		{"county_rate", region.County, &region.CountyRate},
+		{"city_rate", region.City, &region.CityRate},
+		{"special_rate", region.Special, &region.SpecialRate},
+	} {
+		raw := field(rate.column)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v >= 1 {
+			return region, fmt.Errorf("%s must be a fraction between 0 and 1, got %q", rate.column, raw)
+		}
+		if v > 0 && rate.name == "" {
+			return region, fmt.Errorf("%s needs the name of its jurisdiction", rate.column)
+		}
+		*rate.value = v
+	}
+	return region, nil
+}
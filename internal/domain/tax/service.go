@@ -0,0 +1,183 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"purchase-cart-service/utils"
+	"strings"
+)
+
+// Service finds the tax due on a sale from its destination: the VAT rate of
+// the special territory or of the country, or the US sales tax of the state
+// and ZIP code when a sales tax table is configured
+type Service struct {
+	vatRepo  repository.VatRateRepository
+	salesTax repository.SalesTaxRepository
+	// origin is the location the US sales are shipped from
+	origin models.Destination
+}
+
+// Option customizes a Service
+type Option func(*Service)
+
+// WithSalesTax taxes US sales with the sales tax table of repo, for goods
+// shipped from origin (state and ZIP code)
+func WithSalesTax(repo repository.SalesTaxRepository, origin models.Destination) Option {
+	return func(s *Service) {
+		s.salesTax = repo
+		s.origin = origin
+	}
+}
+
+func NewService(vatRepo repository.VatRateRepository, opts ...Option) *Service {
+	s := &Service{vatRepo: vatRepo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var ErrRateNotFound = errors.New("tax rate not found")
+var ErrIncompleteDestination = errors.New("state and ZIP code are required for US deliveries")
+var ErrPostalCodeNotFound = errors.New("ZIP code not found in the sales tax table")
+
+// Lookup returns the rate due on a sale delivered to destination, for goods
+// of the standard tax class
+func (s *Service) Lookup(ctx context.Context, destination models.Destination) (*models.TaxRate, error) {
+	return s.LookupClass(ctx, destination, models.TaxClassStandard)
+}
+
+// LookupClass returns the rate due on a sale of goods of a tax class
+// delivered to destination. The US sales tax has no reduced classes: only
+// the zero class is exempt
+func (s *Service) LookupClass(ctx context.Context, destination models.Destination, class string) (*models.TaxRate, error) {
+	if destination.CountryCode == "US" && s.salesTax != nil {
+		rate, err := s.salesTaxRate(ctx, destination)
+		if err != nil || class != models.TaxClassZero {
+			return rate, err
+		}
+		return &models.TaxRate{}, nil
+	}
+	territory, err := s.vatRepo.GetTerritory(destination)
+	if err != nil {
+		return nil, err
+	}
+	if territory != nil {
+		rate := territory.Rate
+		if rate > 0 && class != models.TaxClassStandard {
+			// a territory inside the VAT area has the reduced rates of its country
+			if rate, err = s.vatRepo.GetVATRateForClass(territory.CountryCode, class); err != nil {
+				return nil, ErrRateNotFound
+			}
+		}
+		result := &models.TaxRate{Rate: rate, Territory: territory}
+		if rate > 0 {
+			result.Jurisdictions = []models.TaxJurisdiction{{Type: models.JurisdictionTerritory, Name: territory.Name, Rate: rate}}
+		}
+		return result, nil
+	}
+	rate, err := s.vatRepo.GetVATRateForClass(destination.CountryCode, class)
+	if err != nil {
+		return nil, ErrRateNotFound
+	}
+	result := &models.TaxRate{Rate: rate}
+	if rate > 0 {
+		result.Jurisdictions = []models.TaxJurisdiction{{Type: models.JurisdictionCountry, Name: destination.CountryCode, Rate: rate}}
+	}
+	return result, nil
+}
+
+// salesTaxRate applies the rates of the destination ZIP code, or the ones of
+// the origin for sales within an origin-based state. States missing from the
+// table, where the seller does not collect, are not taxed
+func (s *Service) salesTaxRate(ctx context.Context, destination models.Destination) (*models.TaxRate, error) {
+	state := strings.ToUpper(strings.TrimSpace(destination.Region))
+	zip := normalizeZIP(destination.PostalCode)
+	if state == "" || zip == "" {
+		return nil, ErrIncompleteDestination
+	}
+	covered, err := s.salesTax.HasState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if !covered {
+		return &models.TaxRate{}, nil
+	}
+	region, err := s.salesTax.GetRegion(ctx, state, zip)
+	if err != nil {
+		return nil, err
+	}
+	if region == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrPostalCodeNotFound, state, zip)
+	}
+
+	sourcing := StateSourcing(state)
+	if strings.EqualFold(s.origin.Region, state) && sourcing != DestinationBased {
+		origin, err := s.salesTax.GetRegion(ctx, state, normalizeZIP(s.origin.PostalCode))
+		if err != nil {
+			return nil, err
+		}
+		if origin == nil {
+			return nil, fmt.Errorf("%w: origin %s %s", ErrPostalCodeNotFound, state, s.origin.PostalCode)
+		}
+		if sourcing == ModifiedOrigin {
+			origin.Special, origin.SpecialRate = region.Special, region.SpecialRate
+		}
+		region = origin
+	}
+	return regionRate(region), nil
+}
+
+func regionRate(region *models.SalesTaxRegion) *models.TaxRate {
+	result := &models.TaxRate{}
+	for _, j := range []models.TaxJurisdiction{
+		{Type: models.JurisdictionState, Name: region.State, Rate: region.StateRate},
+		{Type: models.JurisdictionCounty, Name: region.County, Rate: region.CountyRate},
+		{Type: models.JurisdictionCity, Name: region.City, Rate: region.CityRate},
+		{Type: models.JurisdictionSpecial, Name: region.Special, Rate: region.SpecialRate},
+	} {
+		if j.Rate > 0 {
+			result.Jurisdictions = append(result.Jurisdictions, j)
+			result.Rate += j.Rate
+		}
+	}
+	// drop the float noise of the sum, rates have at most a few decimals
+	result.Rate = math.Round(result.Rate*1e6) / 1e6
+	return result
+}
+
+// normalizeZIP keeps the five digit ZIP code of a ZIP+4
+func normalizeZIP(postalCode string) string {
+	zip, _, _ := strings.Cut(strings.TrimSpace(postalCode), "-")
+	return zip
+}
+
+// UnitPrices returns the net price and the price including the tax of a
+// catalog price in mode, at rate. The tax is rounded to the cent
+func UnitPrices(price float64, mode string, rate float64) (net, gross float64) {
+	if mode == models.PriceModeGross {
+		return utils.Round2(price - utils.Round2(price*rate/(1+rate))), price
+	}
+	return price, utils.Round2(price + utils.Round2(price*rate))
+}
+
+func share(net, tax float64, rate models.TaxRate) []models.LineTax {
+	if len(rate.Jurisdictions) == 0 {
+		return nil
+	}
+	shares := make([]models.LineTax, 0, len(rate.Jurisdictions))
+	var assigned float64
+	for i, j := range rate.Jurisdictions {
+		amount := utils.Round2(net * j.Rate)
+		if i == len(rate.Jurisdictions)-1 {
+			amount = utils.Round2(tax - assigned)
+		}
+		assigned += amount
+		shares = append(shares, models.LineTax{TaxJurisdiction: j, Amount: amount})
+	}
+	return shares
+}
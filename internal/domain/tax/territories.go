@@ -0,0 +1,95 @@
+package tax
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"purchase-cart-service/models"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidTerritoryTable = errors.New("invalid VAT territory table")
+
+// territoryColumns are the columns of the territory table, in any order
+var territoryColumns = []string{"country", "postal_code", "region", "territory", "code", "rate"}
+
+// LoadTerritoriesFile reads the VAT territory table from a CSV file, see ReadTerritories
+func LoadTerritoriesFile(path string) ([]models.VATTerritory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTerritories(f)
+}
+
+// ReadTerritories parses the table of the special VAT territories: a CSV
+// with a header naming the columns in territoryColumns and one row per
+// postal code prefix or region of a territory. Lines starting with # are comments
+func ReadTerritories(r io.Reader) ([]models.VATTerritory, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidTerritoryTable, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range territoryColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidTerritoryTable, column)
+		}
+	}
+
+	var territories []models.VATTerritory
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTerritoryTable, err)
+		}
+		line, _ := reader.FieldPos(0)
+		territory, err := parseTerritory(func(column string) string { return strings.TrimSpace(record[index[column]]) })
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidTerritoryTable, line, err)
+		}
+		territories = append(territories, territory)
+	}
+	return territories, nil
+}
+
+func parseTerritory(field func(column string) string) (models.VATTerritory, error) {
+	territory := models.VATTerritory{
+		CountryCode:      strings.ToUpper(field("country")),
+		PostalCodePrefix: strings.ToUpper(strings.ReplaceAll(field("postal_code"), " ", "")),
+		Region:           strings.ToUpper(field("region")),
+		Name:             field("territory"),
+		Code:             strings.ToUpper(field("code")),
+	}
+	if !statePattern.MatchString(territory.CountryCode) {
+		return territory, fmt.Errorf("country must be a two letter code, got %q", territory.CountryCode)
+	}
+	if (territory.PostalCodePrefix == "") == (territory.Region == "") {
+		return territory, errors.New("exactly one of postal_code and region must be set")
+	}
+	if territory.Name == "" {
+		return territory, errors.New("territory must not be empty")
+	}
+	if territory.Code != "" && !statePattern.MatchString(territory.Code) {
+		return territory, fmt.Errorf("code must be a two letter code, got %q", territory.Code)
+	}
+	rate, err := strconv.ParseFloat(field("rate"), 64)
+	if err != nil || rate < 0 || rate >= 1 {
+		return territory, fmt.Errorf("rate must be a fraction between 0 and 1, got %q", field("rate"))
+	}
+	territory.Rate = rate
+	return territory, nil
+}
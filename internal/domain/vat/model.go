@@ -0,0 +1,7 @@
+package vat
+
+// Rate is the VAT rate applied in a country
+type Rate struct {
+	CountryCode string
+	Rate        float64
+}
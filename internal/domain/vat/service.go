@@ -0,0 +1,39 @@
+package vat
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/repository"
+	"sort"
+)
+
+type Service struct {
+	vatRepo repository.VatRateRepository
+}
+
+func NewService(vatRepo repository.VatRateRepository) *Service {
+	return &Service{vatRepo: vatRepo}
+}
+
+var ErrRateNotFound = errors.New("VAT rate not found")
+
+func (s *Service) GetRate(ctx context.Context, countryCode string) (*Rate, error) {
+	rate, err := s.vatRepo.GetVATRate(countryCode)
+	if err != nil {
+		return nil, ErrRateNotFound
+	}
+	return &Rate{CountryCode: countryCode, Rate: rate}, nil
+}
+
+func (s *Service) GetAllRates(ctx context.Context) ([]Rate, error) {
+	rates, err := s.vatRepo.GetAllVATRates()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Rate, 0, len(rates))
+	for country, rate := range rates {
+		result = append(result, Rate{CountryCode: country, Rate: rate})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CountryCode < result[j].CountryCode })
+	return result, nil
+}
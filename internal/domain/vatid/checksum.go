@@ -0,0 +1,193 @@
+package vatid
+
+import "strconv"
+
+// digitsOf converts a string of ASCII digits; callers have matched the format
+func digitsOf(s string) []int {
+	d := make([]int, len(s))
+	for i := range s {
+		d[i] = int(s[i] - '0')
+	}
+	return d
+}
+
+// weighted returns the sum of the digits multiplied by the weights
+func weighted(d []int, weights ...int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+	return sum
+}
+
+// luhn reports whether the digits pass the Luhn check
+func luhn(d []int) bool {
+	sum := 0
+	for i := range d {
+		v := d[len(d)-1-i]
+		if i%2 == 1 {
+			v *= 2
+			if v > 9 {
+				v -= 9
+			}
+		}
+		sum += v
+	}
+	return sum%10 == 0
+}
+
+// checkMod1110 is ISO 7064 MOD 11,10 over all the digits (DE, HR)
+func checkMod1110(s string) bool {
+	d := digitsOf(s)
+	product := 10
+	for _, v := range d[:len(d)-1] {
+		sum := (v + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	check := 11 - product
+	if check == 10 {
+		check = 0
+	}
+	return check == d[len(d)-1]
+}
+
+func checkAT(s string) bool {
+	d := digitsOf(s[1:])
+	sum := 0
+	for i, v := range d[:7] {
+		if i%2 == 1 {
+			v = v*2/10 + v*2%10
+		}
+		sum += v
+	}
+	return (10-(sum+4)%10)%10 == d[7]
+}
+
+func checkBE(s string) bool {
+	base, _ := strconv.Atoi(s[:8])
+	check, _ := strconv.Atoi(s[8:])
+	return 97-base%97 == check
+}
+
+func checkDK(s string) bool {
+	return weighted(digitsOf(s), 2, 7, 6, 5, 4, 3, 2, 1)%11 == 0
+}
+
+func checkEE(s string) bool {
+	d := digitsOf(s)
+	return (10-weighted(d, 3, 7, 1, 3, 7, 1, 3, 7)%10)%10 == d[8]
+}
+
+func checkEL(s string) bool {
+	d := digitsOf(s)
+	return weighted(d, 256, 128, 64, 32, 16, 8, 4, 2)%11%10 == d[8]
+}
+
+// checkES covers the personal numbers (DNI and NIE), ending with a control
+// letter, and the company ones (CIF), starting with a letter
+func checkES(s string) bool {
+	const letters = "TRWAGMYFPDXBNJZSQVHLCKE"
+	first, last := s[0], s[8]
+	switch {
+	case first >= '0' && first <= '9', first == 'X', first == 'Y', first == 'Z':
+		body := s[:8]
+		switch first {
+		case 'X':
+			body = "0" + body[1:]
+		case 'Y':
+			body = "1" + body[1:]
+		case 'Z':
+			body = "2" + body[1:]
+		}
+		n, err := strconv.Atoi(body)
+		return err == nil && letters[n%23] == last
+	case first == 'K', first == 'L', first == 'M':
+		n, _ := strconv.Atoi(s[1:8])
+		return letters[n%23] == last
+	default:
+		d := digitsOf(s[1:8])
+		sum := 0
+		for i, v := range d {
+			if i%2 == 0 {
+				v = v*2/10 + v*2%10
+			}
+			sum += v
+		}
+		check := (10 - sum%10) % 10
+		return last == byte('0'+check) || last == "JABCDEFGHI"[check]
+	}
+}
+
+func checkFI(s string) bool {
+	d := digitsOf(s)
+	check := 11 - weighted(d, 7, 9, 10, 5, 8, 4, 2)%11
+	if check == 11 {
+		check = 0
+	}
+	return check == d[7]
+}
+
+// checkFR verifies the numeric keys; alphabetic keys have no public algorithm
+func checkFR(s string) bool {
+	key, err := strconv.Atoi(s[:2])
+	if err != nil {
+		return true
+	}
+	siren, _ := strconv.Atoi(s[2:])
+	return (12+3*(siren%97))%97 == key
+}
+
+func checkIT(s string) bool {
+	return luhn(digitsOf(s))
+}
+
+func checkLU(s string) bool {
+	base, _ := strconv.Atoi(s[:6])
+	check, _ := strconv.Atoi(s[6:])
+	return base%89 == check
+}
+
+// checkNL accepts both the company numbers, weighted modulo 11, and the
+// numbers of sole traders issued since 2020, checked with ISO 7064 MOD 97-10
+func checkNL(s string) bool {
+	d := digitsOf(s[:9])
+	if weighted(d, 9, 8, 7, 6, 5, 4, 3, 2)%11 == d[8] {
+		return true
+	}
+	// NL is 2321 in the letter-to-number conversion of ISO 7064, B is 11
+	rest := 0
+	for _, c := range "2321" + s[:9] + "11" + s[10:] {
+		rest = (rest*10 + int(c-'0')) % 97
+	}
+	return rest == 1
+}
+
+func checkPL(s string) bool {
+	d := digitsOf(s)
+	return weighted(d, 6, 5, 7, 2, 3, 4, 5, 6, 7)%11 == d[9]
+}
+
+func checkPT(s string) bool {
+	d := digitsOf(s)
+	check := 11 - weighted(d, 9, 8, 7, 6, 5, 4, 3, 2)%11
+	if check >= 10 {
+		check = 0
+	}
+	return check == d[8]
+}
+
+func checkSE(s string) bool {
+	return luhn(digitsOf(s[:10]))
+}
+
+func checkSI(s string) bool {
+	d := digitsOf(s)
+	check := 11 - weighted(d, 8, 7, 6, 5, 4, 3, 2)%11
+	if check == 10 {
+		check = 0
+	}
+	return check != 11 && check == d[7]
+}
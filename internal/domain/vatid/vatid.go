@@ -0,0 +1,109 @@
+package vatid
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidFormat = errors.New("invalid VAT ID format")
+var ErrInvalidChecksum = errors.New("invalid VAT ID check digits")
+var ErrUnsupportedCountry = errors.New("VAT ID country is not in the EU VAT area")
+
+// Number is a syntactically valid VAT identification number
+type Number struct {
+	// Prefix is the VAT prefix of the number, EL for Greece
+	Prefix string
+	// Digits is the national part of the number, without separators
+	Digits string
+}
+
+// String returns the number in its normalized form, e.g. IT00743110157
+func (n Number) String() string {
+	return n.Prefix + n.Digits
+}
+
+// CountryCode is the ISO 3166 code of the issuing country, which differs
+// from the prefix for Greece
+func (n Number) CountryCode() string {
+	if n.Prefix == "EL" {
+		return "GR"
+	}
+	return n.Prefix
+}
+
+// format is the national part of the numbers of a country, with an optional
+// check of the control digits
+type format struct {
+	pattern  *regexp.Regexp
+	checksum func(digits string) bool
+}
+
+// formats lists the VAT areas of the EU member states, plus XI for Northern
+// Ireland, which VIES covers for the trade in goods
+var formats = map[string]format{
+	"AT": {regexp.MustCompile(`^U\d{8}$`), checkAT},
+	"BE": {regexp.MustCompile(`^[01]\d{9}$`), checkBE},
+	"BG": {regexp.MustCompile(`^\d{9,10}$`), nil},
+	"CY": {regexp.MustCompile(`^\d{8}[A-Z]$`), nil},
+	"CZ": {regexp.MustCompile(`^\d{8,10}$`), nil},
+	"DE": {regexp.MustCompile(`^\d{9}$`), checkMod1110},
+	"DK": {regexp.MustCompile(`^\d{8}$`), checkDK},
+	"EE": {regexp.MustCompile(`^10\d{7}$`), checkEE},
+	"EL": {regexp.MustCompile(`^\d{9}$`), checkEL},
+	"ES": {regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`), checkES},
+	"FI": {regexp.MustCompile(`^\d{8}$`), checkFI},
+	"FR": {regexp.MustCompile(`^[0-9A-HJ-NP-Z]{2}\d{9}$`), checkFR},
+	"HR": {regexp.MustCompile(`^\d{11}$`), checkMod1110},
+	"HU": {regexp.MustCompile(`^\d{8}$`), nil},
+	"IE": {regexp.MustCompile(`^(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`), nil},
+	"IT": {regexp.MustCompile(`^\d{11}$`), checkIT},
+	"LT": {regexp.MustCompile(`^(\d{9}|\d{12})$`), nil},
+	"LU": {regexp.MustCompile(`^\d{8}$`), checkLU},
+	"LV": {regexp.MustCompile(`^\d{11}$`), nil},
+	"MT": {regexp.MustCompile(`^\d{8}$`), nil},
+	"NL": {regexp.MustCompile(`^\d{9}B\d{2}$`), checkNL},
+	"PL": {regexp.MustCompile(`^\d{10}$`), checkPL},
+	"PT": {regexp.MustCompile(`^\d{9}$`), checkPT},
+	"RO": {regexp.MustCompile(`^[1-9]\d{1,9}$`), nil},
+	"SE": {regexp.MustCompile(`^\d{10}01$`), checkSE},
+	"SI": {regexp.MustCompile(`^[1-9]\d{7}$`), checkSI},
+	"SK": {regexp.MustCompile(`^[1-9]\d{9}$`), nil},
+	"XI": {regexp.MustCompile(`^(\d{9}|\d{12}|GD\d{3}|HA\d{3})$`), nil},
+}
+
+// Parse normalizes a VAT ID, dropping spaces, dots and dashes, and checks
+// its format and, where the country publishes the algorithm, its check digits
+func Parse(id string) (Number, error) {
+	id = strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(id))
+	if len(id) < 4 {
+		return Number{}, ErrInvalidFormat
+	}
+	prefix, digits := id[:2], id[2:]
+	if prefix[0] < 'A' || prefix[0] > 'Z' || prefix[1] < 'A' || prefix[1] > 'Z' {
+		return Number{}, ErrInvalidFormat
+	}
+	if prefix == "GR" {
+		prefix = "EL"
+	}
+	f, ok := formats[prefix]
+	if !ok {
+		return Number{}, ErrUnsupportedCountry
+	}
+	if !f.pattern.MatchString(digits) {
+		return Number{}, ErrInvalidFormat
+	}
+	if f.checksum != nil && !f.checksum(digits) {
+		return Number{}, ErrInvalidChecksum
+	}
+	return Number{Prefix: prefix, Digits: digits}, nil
+}
+
+// IsEU reports whether a country, by ISO 3166 code, is in the EU VAT area
+func IsEU(countryCode string) bool {
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	_, ok := formats[countryCode]
+	return ok && countryCode != "XI"
+}
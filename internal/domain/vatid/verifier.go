@@ -0,0 +1,55 @@
+package vatid
+
+import (
+	"context"
+	"sync"
+)
+
+// Verification is the answer of the VAT registry about a number
+type Verification struct {
+	Valid   bool
+	Name    string
+	Address string
+	// RequestID is the consultation reference, to be kept as evidence
+	RequestID string
+}
+
+// Verifier checks that a number is registered for intra-community trade, as
+// the VIES service of the European Commission does. An error means that
+// the registry could not answer, not that the number is invalid
+type Verifier interface {
+	Verify(ctx context.Context, number Number) (Verification, error)
+}
+
+// StubVerifier answers locally: no number is registered except the ones
+// marked as registered, so that it never grants a reverse charge by itself
+type StubVerifier struct {
+	mu         sync.RWMutex
+	registered map[string]bool
+}
+
+func NewStubVerifier(registered ...string) *StubVerifier {
+	v := &StubVerifier{registered: make(map[string]bool)}
+	v.Register(registered...)
+	return v
+}
+
+// Register marks numbers, in any format Parse accepts, as registered
+func (v *StubVerifier) Register(numbers ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, number := range numbers {
+		if n, err := Parse(number); err == nil {
+			v.registered[n.String()] = true
+		}
+	}
+}
+
+func (v *StubVerifier) Verify(ctx context.Context, number Number) (Verification, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if !v.registered[number.String()] {
+		return Verification{Valid: false}, nil
+	}
+	return Verification{Valid: true, RequestID: "stub-" + number.String()}, nil
+}
@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrDispatcherClosed is returned when enqueuing after Close
+var ErrDispatcherClosed = errors.New("webhook dispatcher closed")
+
+// DispatcherConfig tunes the asynchronous delivery
+type DispatcherConfig struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failure; it doubles at every
+	// further failure up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds a single HTTP attempt
+	Timeout time.Duration
+}
+
+// Dispatcher delivers webhooks asynchronously with a pool of workers,
+// retrying failed attempts with exponential backoff. Deliveries that fail
+// every attempt are stored in the dead-letter repository
+type Dispatcher struct {
+	cfg         DispatcherConfig
+	client      *http.Client
+	deadLetters repository.WebhookDeadLetterRepository
+
+	queue   chan Delivery
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	stopped sync.Once
+}
+
+// NewDispatcher starts the workers; client may be nil to use a default one
+func NewDispatcher(cfg DispatcherConfig, deadLetters repository.WebhookDeadLetterRepository, client *http.Client) *Dispatcher {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	d := &Dispatcher{
+		cfg:         cfg,
+		client:      client,
+		deadLetters: deadLetters,
+		queue:       make(chan Delivery, cfg.QueueSize),
+		stop:        make(chan struct{}),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Enqueue schedules a delivery without blocking. When the queue is full the
+// delivery goes straight to the dead-letter store, from where it can be redelivered
+func (d *Dispatcher) Enqueue(ctx context.Context, delivery Delivery) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	select {
+	case d.queue <- delivery:
+		return nil
+	default:
+		d.deadLetter(ctx, delivery, 0, errors.New("delivery queue full"))
+		return nil
+	}
+}
+
+// Close stops accepting deliveries and waits for the queued ones. Workers
+// waiting for a retry give up immediately and dead-letter the delivery; if ctx
+// expires before the queue is drained the remaining deliveries are dead-lettered
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.stopped.Do(func() { close(d.stop) })
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for delivery := range d.queue {
+		select {
+		case <-d.stop:
+			d.deadLetter(context.Background(), delivery, 0, errors.New("dispatcher stopped before delivery"))
+			continue
+		default:
+		}
+		d.deliver(delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(delivery Delivery) {
+	var err error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		if err = d.attempt(delivery); err == nil {
+			return
+		}
+		if attempt == d.cfg.MaxAttempts {
+			break
+		}
+		select {
+		case <-time.After(d.backoff(attempt)):
+		case <-d.stop:
+			d.deadLetter(context.Background(), delivery, attempt, err)
+			return
+		}
+	}
+	d.deadLetter(context.Background(), delivery, d.cfg.MaxAttempts, err)
+}
+
+func (d *Dispatcher) attempt(delivery Delivery) error {
+	ctx := context.Background()
+	if d.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "purchase-cart-service-webhooks/1.0")
+	req.Header.Set(HeaderDeliveryID, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the wait after the given failed attempt, with up to 20% jitter
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.cfg.InitialBackoff << (attempt - 1)
+	if wait <= 0 || (d.cfg.MaxBackoff > 0 && wait > d.cfg.MaxBackoff) {
+		wait = d.cfg.MaxBackoff
+	}
+	if wait > 0 {
+		wait += time.Duration(rand.Int63n(int64(wait)/5 + 1))
+	}
+	return wait
+}
+
+func (d *Dispatcher) deadLetter(ctx context.Context, delivery Delivery, attempts int, cause error) {
+	deadLetter := &models.WebhookDeadLetter{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Attempts:       attempts,
+		LastError:      cause.Error(),
+		FailedAt:       time.Now().UTC(),
+	}
+	if err := d.deadLetters.Save(ctx, deadLetter); err != nil {
+		log.Printf("webhook delivery %s lost: dead-letter store failed: %v", delivery.ID, err)
+		return
+	}
+	log.Printf("webhook delivery %s to %s dead-lettered after %d attempts: %v", delivery.ID, delivery.URL, attempts, cause)
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import "time"
+
+// SubscriptionInput is the input DTO for creating and updating subscriptions
+type SubscriptionInput struct {
+	URL    string
+	Events []string
+	// Secret signs the deliveries; when empty on creation a random one is generated
+	Secret string
+	Active bool
+}
+
+// EventPayload is the JSON body POSTed to the subscribers
+type EventPayload struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       OrderData `json:"data"`
+}
+
+type OrderData struct {
+	OrderID    string      `json:"order_id"`
+	Status     string      `json:"status"`
+	TotalPrice float64     `json:"total_price"`
+	TotalVAT   float64     `json:"total_vat"`
+	Items      []OrderItem `json:"items"`
+}
+
+type OrderItem struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	VAT       float64 `json:"vat"`
+}
+
+// Delivery is a payload to POST to a single subscriber. ID is stable across
+// retries and redeliveries, so that receivers can deduplicate
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	URL            string
+	Secret         string
+	EventID        string
+	EventType      string
+	Payload        []byte
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+
+	"github.com/google/uuid"
+)
+
+// EventTypes lists the events a subscription can receive
+var EventTypes = []string{
+	events.TypeOrderCreated,
+	events.TypeOrderPaid,
+	events.TypeOrderCancelled,
+	events.TypeOrderShipped,
+}
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+var ErrInvalidURL = errors.New("invalid webhook URL")
+var ErrInvalidEvents = errors.New("invalid webhook events")
+
+type Service struct {
+	subscriptions repository.WebhookRepository
+	deadLetters   repository.WebhookDeadLetterRepository
+	dispatcher    *Dispatcher
+}
+
+func NewService(subscriptions repository.WebhookRepository, deadLetters repository.WebhookDeadLetterRepository, dispatcher *Dispatcher) *Service {
+	return &Service{
+		subscriptions: subscriptions,
+		deadLetters:   deadLetters,
+		dispatcher:    dispatcher,
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, in SubscriptionInput) (*models.WebhookSubscription, error) {
+	if err := validate(in); err != nil {
+		return nil, err
+	}
+	secret := in.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+	subscription := &models.WebhookSubscription{
+		URL:    in.URL,
+		Events: in.Events,
+		Secret: secret,
+		Active: in.Active,
+	}
+	if err := s.subscriptions.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription replaces URL, events and status; an empty secret keeps the current one
+func (s *Service) UpdateSubscription(ctx context.Context, id string, in SubscriptionInput) (*models.WebhookSubscription, error) {
+	if err := validate(in); err != nil {
+		return nil, err
+	}
+	subscription, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.URL = in.URL
+	subscription.Events = in.Events
+	subscription.Active = in.Active
+	if in.Secret != "" {
+		subscription.Secret = in.Secret
+	}
+	if err := s.subscriptions.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := s.GetSubscription(ctx, id); err != nil {
+		return err
+	}
+	return s.subscriptions.Delete(ctx, id)
+}
+
+func (s *Service) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	subscription, err := s.subscriptions.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (s *Service) GetAllSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	return s.subscriptions.GetAll(ctx)
+}
+
+func (s *Service) GetAllDeadLetters(ctx context.Context) ([]*models.WebhookDeadLetter, error) {
+	return s.deadLetters.GetAll(ctx)
+}
+
+// Redeliver enqueues a dead-lettered delivery again, with the current URL and
+// secret of its subscription, and removes it from the dead-letter store once
+// enqueued: a delivery that cannot be enqueued stays dead-lettered
+func (s *Service) Redeliver(ctx context.Context, deadLetterID string) error {
+	deadLetter, err := s.deadLetters.GetByID(ctx, deadLetterID)
+	if err != nil {
+		return err
+	}
+	if deadLetter == nil {
+		return ErrDeadLetterNotFound
+	}
+	subscription, err := s.GetSubscription(ctx, deadLetter.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	err = s.dispatcher.Enqueue(ctx, Delivery{
+		ID:             deadLetter.DeliveryID,
+		SubscriptionID: subscription.ID,
+		URL:            subscription.URL,
+		Secret:         subscription.Secret,
+		EventID:        deadLetter.EventID,
+		EventType:      deadLetter.EventType,
+		Payload:        deadLetter.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	return s.deadLetters.Delete(ctx, deadLetterID)
+}
+
+// HandleOrderEvent is an events.Handler enqueuing a delivery for every
+// active subscription interested in the event. Only a failure to read the
+// subscriptions is returned, so that the relay retries the event
+func (s *Service) HandleOrderEvent(ctx context.Context, envelope events.Envelope) error {
+	order, ok := orderOf(envelope.Event)
+	if !ok {
+		return nil
+	}
+	subscriptions, err := s.subscriptions.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	eventType := envelope.Event.EventType()
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !contains(subscription.Events, eventType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(toPayload(envelope, eventType, order)); err != nil {
+				log.Printf("webhooks: cannot encode event %s: %v", envelope.ID, err)
+				return nil
+			}
+		}
+		err := s.dispatcher.Enqueue(ctx, Delivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Secret:         subscription.Secret,
+			EventID:        envelope.ID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			log.Printf("webhooks: cannot enqueue event %s for %s: %v", envelope.ID, subscription.ID, err)
+		}
+	}
+	return nil
+}
+
+func orderOf(event events.Event) (events.Order, bool) {
+	switch e := event.(type) {
+	case events.OrderCreated:
+		return e.Order, true
+	case events.OrderPaid:
+		return e.Order, true
+	case events.OrderCancelled:
+		return e.Order, true
+	case events.OrderShipped:
+		return e.Order, true
+	}
+	return events.Order{}, false
+}
+
+func toPayload(envelope events.Envelope, eventType string, order events.Order) EventPayload {
+	data := OrderData{
+		OrderID:    order.ID,
+		Status:     order.Status,
+		TotalPrice: order.TotalPrice,
+		TotalVAT:   order.TotalVAT,
+		Items:      []OrderItem{},
+	}
+	for _, it := range order.Items {
+		data.Items = append(data.Items, OrderItem{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+			VAT:       it.VAT,
+		})
+	}
+	return EventPayload{
+		ID:         envelope.ID,
+		Type:       eventType,
+		OccurredAt: envelope.OccurredAt,
+		Data:       data,
+	}
+}
+
+func validate(in SubscriptionInput) error {
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if len(in.Events) == 0 {
+		return ErrInvalidEvents
+	}
+	for _, e := range in.Events {
+		if !contains(EventTypes, e) {
+			return ErrInvalidEvents
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
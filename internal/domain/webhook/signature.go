@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderDeliveryID = "X-Webhook-ID"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value of a delivery: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+// Including the timestamp lets receivers reject replayed deliveries
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
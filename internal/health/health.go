@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// DefaultCheckTimeout bounds the execution of a single readiness check
+const DefaultCheckTimeout = 2 * time.Second
+
+// ErrShuttingDown is reported by the readiness probe once shutdown started
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// Check verifies a dependency of the service; a nil error means healthy
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report aggregates the outcome of every registered check
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// Ready reports whether every check passed
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker holds the readiness checks of the service
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []namedCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: DefaultCheckTimeout}
+}
+
+// Register adds a named readiness check
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown flips the service to not-ready, so that load balancers
+// stop routing new traffic while in-flight requests are drained
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs every registered check concurrently and aggregates the results
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.shuttingDown.Load() {
+		return Report{Status: StatusUnavailable, Reason: ErrShuttingDown.Error()}
+	}
+	c.mu.RLock()
+	checks := make([]namedCheck, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx, nc.check)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, nc := range checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- errors.New("check panicked")
+			}
+		}()
+		errCh <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
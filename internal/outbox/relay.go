@@ -0,0 +1,193 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"sync"
+	"time"
+)
+
+// Sink receives the relayed outbox messages. Send must be idempotent with
+// respect to the message ID: a message is sent again when the relay stops
+// between the delivery and its acknowledgement
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, msg *models.OutboxMessage) error
+}
+
+// RelayConfig tunes the polling of the outbox. A message failed by a sink
+// in MaxAttempts passes is dead-lettered
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// Relay forwards the outbox messages to the sinks. Every sink receives the
+// messages in outbox order; a message is retried on the next poll for the
+// sinks that failed it, and later messages wait for it, so a failing sink
+// does not reorder its stream nor hold back the other sinks. After
+// MaxAttempts failed passes the message is dead-lettered and logged, so that
+// a sink failing it for good does not block its stream forever
+type Relay struct {
+	outbox repository.OutboxRepository
+	sinks  []Sink
+	cfg    RelayConfig
+
+	// mu serializes the relay passes
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(outbox repository.OutboxRepository, cfg RelayConfig, sinks ...Sink) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	return &Relay{
+		outbox: outbox,
+		sinks:  sinks,
+		cfg:    cfg,
+	}
+}
+
+// Start polls the outbox in the background until Stop
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx)
+}
+
+// Stop ends the polling and makes a last pass, so that the messages
+// committed before shutdown are relayed while the sinks are still available
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+	_, err := r.RelayPending(ctx)
+	return err
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending forwards the pending messages, batch after batch, until the
+// outbox is empty or a pass makes no progress. It returns the number of
+// messages fully dispatched and the errors of the failed deliveries
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dispatched := 0
+	for {
+		n, more, err := r.relayBatch(ctx)
+		dispatched += n
+		if err != nil || !more || n == 0 {
+			return dispatched, err
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, bool, error) {
+	pending, err := r.outbox.GetPending(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, false, err
+	}
+	var errs []error
+	blocked := make(map[string]bool)
+	dispatched := 0
+	for _, msg := range pending {
+		if ctx.Err() != nil {
+			return dispatched, false, errors.Join(append(errs, ctx.Err())...)
+		}
+		complete := true
+		var failures []error
+		for _, sink := range r.sinks {
+			name := sink.Name()
+			if delivered(msg, name) {
+				continue
+			}
+			if blocked[name] {
+				complete = false
+				continue
+			}
+			if err := sink.Send(ctx, msg); err != nil {
+				blocked[name] = true
+				complete = false
+				failures = append(failures, &SinkError{Sink: name, MessageID: msg.ID, Err: err})
+				continue
+			}
+			if err := r.outbox.MarkDelivered(ctx, msg.ID, name); err != nil {
+				return dispatched, false, errors.Join(append(errs, err)...)
+			}
+		}
+		if len(failures) > 0 {
+			cause := errors.Join(failures...)
+			errs = append(errs, cause)
+			if err := r.outbox.MarkFailed(ctx, msg.ID, cause.Error()); err != nil {
+				return dispatched, false, errors.Join(append(errs, err)...)
+			}
+			if msg.Attempts+1 >= r.cfg.MaxAttempts {
+				if err := r.outbox.MarkDeadLettered(ctx, msg.ID); err != nil {
+					return dispatched, false, errors.Join(append(errs, err)...)
+				}
+				log.Printf("outbox relay: message %s (%s) dead-lettered after %d attempts: %v", msg.ID, msg.EventType, msg.Attempts+1, cause)
+			}
+		}
+		if !complete {
+			continue
+		}
+		if err := r.outbox.MarkDispatched(ctx, msg.ID); err != nil {
+			return dispatched, false, errors.Join(append(errs, err)...)
+		}
+		dispatched++
+	}
+	return dispatched, len(pending) == r.cfg.BatchSize && len(errs) == 0, errors.Join(errs...)
+}
+
+func delivered(msg *models.OutboxMessage, sink string) bool {
+	for _, s := range msg.Delivered {
+		if s == sink {
+			return true
+		}
+	}
+	return false
+}
+
+// SinkError reports a failed delivery to a sink
+type SinkError struct {
+	Sink      string
+	MessageID string
+	Err       error
+}
+
+func (e *SinkError) Error() string {
+	return "sink " + e.Sink + ": message " + e.MessageID + ": " + e.Err.Error()
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
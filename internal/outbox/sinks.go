@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/models"
+	"sync"
+	"time"
+)
+
+// record is the JSON form of a message written by the file and HTTP sinks
+type record struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func toRecord(msg *models.OutboxMessage) record {
+	return record{
+		ID:          msg.ID,
+		Type:        msg.EventType,
+		AggregateID: msg.AggregateID,
+		OccurredAt:  msg.OccurredAt,
+		Data:        msg.Payload,
+	}
+}
+
+// BusSinks returns a sink per handler of the bus, in subscription order, so
+// that the relay tracks the delivery to each handler: a failing handler is
+// retried alone and the others do not receive the event twice
+func BusSinks(bus *events.Bus) []Sink {
+	var sinks []Sink
+	for _, sub := range bus.Sinks() {
+		sinks = append(sinks, sub)
+	}
+	return sinks
+}
+
+// LogSink writes a line per message to a logger
+type LogSink struct {
+	logger *log.Logger
+}
+
+// NewLogSink returns a sink writing to logger, or to the standard logger when nil
+func NewLogSink(logger *log.Logger) *LogSink {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	s.logger.Printf("event %s %s aggregate=%s %s", msg.ID, msg.EventType, msg.AggregateID, msg.Payload)
+	return nil
+}
+
+// FileSink appends the messages to a file as JSON lines
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Send appends the message and syncs the file, so that an acknowledged
+// message survives a crash
+func (s *FileSink) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	line, err := json.Marshal(toRecord(msg))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HTTPSink POSTs every message as JSON to an endpoint. The message ID is sent
+// in the Idempotency-Key header so that the receiver can drop duplicates
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink returns a sink posting to url; client may be nil to use a
+// client with the given timeout
+func NewHTTPSink(url string, timeout time.Duration, client *http.Client) *HTTPSink {
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	return &HTTPSink{url: url, client: client}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPSink) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	body, err := json.Marshal(toRecord(msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", msg.ID)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
 	"purchase-cart-service/cmd/server"
 	"purchase-cart-service/internal/config"
+
+	// the runtime image has no zoneinfo; Invoicing.TimeZone is resolved from the embedded copy
+	_ "time/tzdata"
 )
 
 func main() {
@@ -14,11 +20,28 @@ func main() {
 	}()
 
 	// Load configuration
-	cfg := config.Load()
+	flags, err := config.ParseFlags(os.Args[0], os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+	cfg, err := config.Load(flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flags.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	srv := server.New(cfg)
 
-	log.Println("Purchase Cart Service started on :8080")
+	log.Printf("Purchase Cart Service started on %s", srv.Addr())
 	if err := srv.Start(); err != nil {
 		log.Fatal(err)
 	}
+	log.Println("Purchase Cart Service stopped")
 }
@@ -0,0 +1,18 @@
+package models
+
+// Address is a postal address; Region is the state, province or territory
+// where the country has them
+type Address struct {
+	Name        string
+	Line1       string
+	Line2       string
+	City        string
+	PostalCode  string
+	Region      string
+	CountryCode string
+}
+
+// IsZero reports whether no field is set
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
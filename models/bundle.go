@@ -0,0 +1,22 @@
+package models
+
+// Bundle is a kit of products sold as one line at its own price, such as a
+// starter kit. Ordered, it expands into a line per component, which takes
+// its stock and is taxed at its own rate
+type Bundle struct {
+	ID          string
+	Name        string
+	Description string
+	// Price is the price of a kit, in PriceMode, the catalog default when
+	// empty
+	Price      float64
+	PriceMode  string
+	Components []BundleComponent
+}
+
+// BundleComponent is a product in a bundle; ProductID is a product ID, or
+// the SKU of a variant for the products with variants
+type BundleComponent struct {
+	ProductID string
+	Quantity  int
+}
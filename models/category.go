@@ -0,0 +1,14 @@
+package models
+
+// Category is a node of the catalog taxonomy. Root categories have no
+// ParentID; siblings are ordered by Position
+type Category struct {
+	ID       string
+	Slug     string
+	Name     string
+	ParentID string
+	Position int
+	// TaxClass is the default tax class of the products of the category,
+	// empty to inherit the one of the parent
+	TaxClass string
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	// CreditNoteStatusPending is a refund sent to the gateway whose outcome
+	// has not been recorded yet
+	CreditNoteStatusPending = "pending"
+	CreditNoteStatusIssued  = "issued"
+	// CreditNoteStatusFailed is a refund the gateway did not carry out
+	CreditNoteStatusFailed = "failed"
+)
+
+// CreditNote documents a refund of an order; Number and IssuedAt are
+// assigned by the repository, from a gap-free sequence, once it is issued
+type CreditNote struct {
+	ID        string
+	Number    string
+	Status    string
+	OrderID   string
+	PaymentID string
+	// RefundID is the gateway reference of the refund
+	RefundID string
+	Reason   string
+	Currency string
+	Lines    []CreditNoteLine
+	TotalNet float64
+	TotalVAT float64
+	Total    float64
+	IssuedAt time.Time
+}
+
+// CreditNoteLine is the refunded part of the order line Line, counted from 1;
+// VATRate is the rate of the sale
+type CreditNoteLine struct {
+	Line      int
+	ProductID string
+	SKU       string
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	VATRate   float64
+	Net       float64
+	VAT       float64
+	Total     float64
+}
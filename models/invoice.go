@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Invoice is the fiscal document of a paid order. Number is unique within
+// the country and fiscal year, from a gap-free Sequence assigned by the repository
+type Invoice struct {
+	ID          string
+	Number      string
+	OrderID     string
+	CountryCode string
+	FiscalYear  int
+	Sequence    int64
+	Currency    string
+	// Buyer is the billing address of the order at issue time
+	Buyer      Address
+	BuyerVATID string
+	// ReverseCharge invoices carry no VAT, the buyer accounts for it
+	ReverseCharge bool
+	Lines         []InvoiceLine
+	TotalNet      float64
+	TotalVAT      float64
+	Total         float64
+	IssuedAt      time.Time
+}
+
+// InvoiceLine is an order line as sold
+type InvoiceLine struct {
+	ProductID string
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	VATRate   float64
+	Net       float64
+	VAT       float64
+	Total     float64
+}
@@ -1,19 +1,87 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+const (
+	OrderStatusCreated   = "created"
+	OrderStatusPaid      = "paid"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusShipped   = "shipped"
+)
 
 type Order struct {
-	ID         string
-	Items      []Item
-	TotalPrice float64
-	TotalVAT   float64
-	CreatedAt  time.Time
+	ID     string
+	Status string
+	// CountryCode is the destination the VAT was computed for
+	CountryCode string
+	// BillingAddress is the buyer address printed on the invoice, if given
+	BillingAddress Address
+	// ShippingAddress is the delivery address; its region and postal code
+	// select the US sales tax
+	ShippingAddress Address
+	// TaxTerritory is the special VAT territory of the delivery address, such
+	// as the Canary Islands, empty when the rules of the country apply
+	TaxTerritory string
+	// BuyerVATID is the normalized VAT number of a business buyer
+	BuyerVATID string
+	// ReverseCharge is set on intra-community B2B sales: no VAT is charged
+	// and the buyer accounts for it
+	ReverseCharge bool
+	Items         []Item
+	TotalPrice    float64
+	TotalVAT      float64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 type Item struct {
 	ProductID string
+	// SKU is the variant ordered, empty for the products without variants
+	SKU       string
 	Name      string
 	Quantity  int
 	UnitPrice float64
-	VAT       float64
+	// VATRate is the rate applied when the order was placed, for TaxClass
+	VATRate  float64
+	TaxClass string
+	VAT      float64
+	// Taxes is the breakdown of the line tax by jurisdiction
+	Taxes []LineTax
+	// PriceMode is the mode of the product price at order time, empty for net
+	PriceMode string
+	// UnitPriceWithVAT is the unit price including VAT: the catalog price in
+	// gross mode, UnitPrice plus its rounded VAT in net mode
+	UnitPriceWithVAT float64
+	// PriceList is the price list UnitPrice comes from, empty for the
+	// catalog price
+	PriceList string
+	// Bundle is the bundle the line is a component of, nil for the products
+	// ordered on their own
+	Bundle *ItemBundle
+}
+
+// ItemBundle links the component lines of a bundle ordered Quantity times
+// on line Line, counted from 1, of the order request. UnitPrice of the
+// components is their share of the bundle price
+type ItemBundle struct {
+	Line     int
+	ID       string
+	Name     string
+	Quantity int
+}
+
+// Net is the net amount of the line. In gross mode UnitPrice is rounded and
+// the net is what is left of the line total once the tax is extracted
+func (i Item) Net() float64 {
+	if i.PriceMode != PriceModeGross {
+		return math.Round(float64(i.Quantity)*i.UnitPrice*100) / 100
+	}
+	net := i.VAT
+	for _, t := range i.Taxes {
+		net -= t.Amount
+	}
+	return math.Round(net*100) / 100
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Types of the events stored by the event-sourced order repository
+const (
+	OrderEventCreated       = "order.created"
+	OrderEventStatusChanged = "order.status_changed"
+	OrderEventUpdated       = "order.updated"
+)
+
+// OrderEvent is an immutable entry of the event stream of an order.
+// Version starts at 1 and increases by one with every event of the order
+type OrderEvent struct {
+	OrderID    string
+	Version    int
+	Type       string
+	OccurredAt time.Time
+	// Data is the JSON payload of the event
+	Data []byte
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// OutboxMessage is a domain event stored in the same transaction as the
+// change that raised it, waiting to be relayed to the sinks
+type OutboxMessage struct {
+	ID string
+	// Sequence orders the messages by insertion
+	Sequence    int64
+	EventType   string
+	AggregateID string
+	Payload     []byte
+	OccurredAt  time.Time
+	// Delivered lists the sinks that already received the message
+	Delivered []string
+	// Attempts counts the relay passes in which a sink failed the message
+	Attempts     int
+	LastError    string
+	DispatchedAt *time.Time
+	// DeadLetteredAt is set when the relay gave up on the message after too
+	// many attempts; the sinks that had not received it never will
+	DeadLetteredAt *time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusVoided     = "voided"
+	PaymentStatusFailed     = "failed"
+	// PaymentStatusRefunded is a captured payment given back in full
+	PaymentStatusRefunded = "refunded"
+)
+
+// PaymentIntent tracks the payment of an order through the gateway
+type PaymentIntent struct {
+	ID      string
+	OrderID string
+	Amount  float64
+	// Currency is an ISO 4217 code
+	Currency      string
+	PaymentMethod string
+	Status        string
+	// AuthorizationID and CaptureID are the gateway references of the operations
+	AuthorizationID string
+	CaptureID       string
+	CapturedAmount  float64
+	RefundedAmount  float64
+	FailureReason   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
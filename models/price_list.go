@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// PriceList is a named set of contract prices, assigned to customer groups
+// and to API clients
+type PriceList struct {
+	ID             string
+	Name           string
+	CustomerGroups []string
+	Clients        []string
+	Prices         []ListPrice
+}
+
+// ListPrice is the price of a product in a price list; ProductID is a
+// product ID or the SKU of a variant. The price is in the price mode of the
+// product, like the catalog one, and is valid from ValidFrom until ValidTo
+// excluded; a zero time leaves that end open. Tiers replace the quantity
+// breaks of the catalog while the price applies
+type ListPrice struct {
+	ProductID string
+	Price     float64
+	Tiers     []PriceTier
+	ValidFrom time.Time
+	ValidTo   time.Time
+}
+
+// ValidAt reports whether the price applies at t
+func (p ListPrice) ValidAt(t time.Time) bool {
+	return !t.Before(p.ValidFrom) && (p.ValidTo.IsZero() || t.Before(p.ValidTo))
+}
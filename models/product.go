@@ -1,12 +1,100 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// Price modes: a net price has the VAT added on top, a gross price
+// includes it and the VAT is extracted from it
+const (
+	PriceModeNet   = "net"
+	PriceModeGross = "gross"
+)
 
 type Product struct {
 	ID          string
 	Name        string
 	Description string
 	Price       float64
-	VAT         float64
-	CreatedAt   time.Time
+	// PriceMode tells whether Price is net or gross; empty for the catalog default
+	PriceMode string
+	VAT       float64
+	CreatedAt time.Time
+	// CategoryIDs are the categories the product is listed in, the first
+	// one being the main category
+	CategoryIDs []string
+	// TaxClass overrides the tax class of the categories when set
+	TaxClass string
+	// Variants are the SKUs of a product sold in several versions, such as
+	// sizes and colors; a product with variants is ordered by SKU
+	Variants []Variant
+	// Tiers are the quantity breaks of Price, also applied to the variants
+	// without a price of their own
+	Tiers []PriceTier
+}
+
+// PriceTier is a quantity break: from MinQuantity units on, every unit of
+// the line costs Price, in the price mode of the product
+type PriceTier struct {
+	MinQuantity int
+	Price       float64
+}
+
+// TierPrice returns the unit price of quantity units: the price of the
+// highest tier the quantity reaches, base below the first one
+func TierPrice(base float64, tiers []PriceTier, quantity int) float64 {
+	price, reached := base, 0
+	for _, t := range tiers {
+		if quantity >= t.MinQuantity && t.MinQuantity > reached {
+			price, reached = t.Price, t.MinQuantity
+		}
+	}
+	return price
+}
+
+// Variant is a version of a product, identified by its SKU, with its own
+// attribute values, price and stock
+type Variant struct {
+	SKU        string
+	Attributes []Attribute
+	// Price overrides the price of the product when positive, in the price
+	// mode of the product
+	Price float64
+	Stock int
+}
+
+// Attribute is the value of a variant for an option of the product, such as
+// size M or color red
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Variant returns the variant of the product with the given SKU
+func (p Product) Variant(sku string) (Variant, bool) {
+	for _, v := range p.Variants {
+		if v.SKU == sku {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// PriceOf returns the price of a variant, the product price unless the
+// variant overrides it
+func (p Product) PriceOf(v Variant) float64 {
+	if v.Price > 0 {
+		return v.Price
+	}
+	return p.Price
+}
+
+// Label describes a variant by its attribute values, e.g. "M, red"
+func (v Variant) Label() string {
+	values := make([]string, 0, len(v.Attributes))
+	for _, a := range v.Attributes {
+		values = append(values, a.Value)
+	}
+	return strings.Join(values, ", ")
 }
@@ -0,0 +1,81 @@
+package models
+
+// Destination is where an order is delivered, as far as taxes are concerned.
+// Region is the state for the US
+type Destination struct {
+	CountryCode string
+	Region      string
+	PostalCode  string
+}
+
+// Jurisdiction types of a TaxJurisdiction
+const (
+	JurisdictionCountry   = "country"
+	JurisdictionTerritory = "territory"
+	JurisdictionState     = "state"
+	JurisdictionCounty    = "county"
+	JurisdictionCity      = "city"
+	JurisdictionSpecial   = "special"
+)
+
+// Tax classes of the goods: the VAT rate of a sale depends on the class of
+// the product as well as on the destination
+const (
+	TaxClassStandard     = "standard"
+	TaxClassReduced      = "reduced"
+	TaxClassSuperReduced = "super-reduced"
+	TaxClassZero         = "zero"
+)
+
+// TaxJurisdiction is an authority levying a tax on a sale, at Rate
+type TaxJurisdiction struct {
+	Type string
+	Name string
+	Rate float64
+}
+
+// TaxRate is the combined rate due on a sale and its breakdown by
+// jurisdiction; Rate is the sum of the jurisdiction rates
+type TaxRate struct {
+	Rate          float64
+	Jurisdictions []TaxJurisdiction
+	// Territory is the special territory of the destination, if any
+	Territory *VATTerritory
+}
+
+// VATTerritory is a part of a country with its own VAT rules, such as the
+// Canary Islands or Northern Ireland. A destination is in the territory when
+// its postal code starts with PostalCodePrefix or its Region is Region
+type VATTerritory struct {
+	CountryCode      string
+	PostalCodePrefix string
+	Region           string
+	Name             string
+	// Code is the country code of the territory for VAT purposes, such as
+	// XI for Northern Ireland, empty if it has none
+	Code string
+	// Rate is the VAT rate charged on deliveries to the territory, zero for
+	// the territories outside the VAT area
+	Rate float64
+}
+
+// LineTax is the tax of an order line owed to a single jurisdiction
+type LineTax struct {
+	TaxJurisdiction
+	Amount float64
+}
+
+// SalesTaxRegion is a row of the US sales tax table: the state and local
+// rates due on deliveries to a ZIP code. Local jurisdictions without a tax
+// have an empty name and a zero rate
+type SalesTaxRegion struct {
+	State       string
+	PostalCode  string
+	StateRate   float64
+	County      string
+	CountyRate  float64
+	City        string
+	CityRate    float64
+	Special     string
+	SpecialRate float64
+}
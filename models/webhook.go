@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type WebhookSubscription struct {
+	ID     string
+	URL    string
+	Events []string
+	// Secret is the key of the HMAC-SHA256 signature of the deliveries
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDeadLetter is a delivery that failed every attempt
+type WebhookDeadLetter struct {
+	ID             string
+	DeliveryID     string
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	Payload        []byte
+	Attempts       int
+	LastError      string
+	FailedAt       time.Time
+}
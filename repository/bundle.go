@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type BundleRepository interface {
+	GetAll(ctx context.Context) ([]models.Bundle, error)
+	// GetByID returns nil when no bundle has the ID
+	GetByID(ctx context.Context, id string) (*models.Bundle, error)
+	// Save creates the bundle or replaces the one with the same ID
+	Save(ctx context.Context, bundle *models.Bundle) error
+}
+
+func NewBundleRepository(repoType string) BundleRepository {
+	var repo BundleRepository
+	switch repoType {
+	case "InMemory":
+		repo = memory.NewBundleRepository()
+	}
+	return repo
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type CategoryRepository interface {
+	GetAll(ctx context.Context) ([]models.Category, error)
+	// GetBySlug returns nil when no category has the slug
+	GetBySlug(ctx context.Context, slug string) (*models.Category, error)
+}
+
+func NewCategoryRepository(repoType string) CategoryRepository {
+	var repo CategoryRepository
+	switch repoType {
+	case "InMemory":
+		repo = memory.NewCategoryRepository()
+	}
+	return repo
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type CreditNoteRepository interface {
+	// Save assigns the ID, and the next number of the sequence when the note
+	// is issued
+	Save(ctx context.Context, note *models.CreditNote) error
+	// Update replaces a saved note, assigning the next number of the sequence
+	// when it becomes issued
+	Update(ctx context.Context, note *models.CreditNote) error
+	GetByNumber(ctx context.Context, number string) (*models.CreditNote, error)
+	// GetByOrderID returns the credit notes of an order, in the order they
+	// were saved
+	GetByOrderID(ctx context.Context, orderID string) ([]*models.CreditNote, error)
+}
+
+func NewCreditNoteRepository(repoType string) CreditNoteRepository {
+	var repoCreditNote CreditNoteRepository
+	switch repoType {
+	case "InMemory":
+		repoCreditNote = memory.NewCreditNoteRepository()
+	}
+	return repoCreditNote
+}
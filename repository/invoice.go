@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type InvoiceRepository interface {
+	// Save stores an invoice under the next sequence of its country and
+	// fiscal year, setting Sequence and the Number built by format from it.
+	// The counter only advances when the invoice is stored, so numbers have no
+	// gaps; an order can have a single invoice
+	Save(ctx context.Context, invoice *models.Invoice, format func(sequence int64) string) error
+	GetByOrderID(ctx context.Context, orderID string) (*models.Invoice, error)
+	GetByNumber(ctx context.Context, number string) (*models.Invoice, error)
+}
+
+func NewInvoiceRepository(repoType string) InvoiceRepository {
+	var repoInvoice InvoiceRepository
+	switch repoType {
+	case "InMemory":
+		repoInvoice = memory.NewInvoiceRepository()
+	}
+	return repoInvoice
+}
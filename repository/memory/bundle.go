@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"slices"
+	"sync"
+)
+
+type BundleRepository struct {
+	mu      sync.RWMutex
+	bundles []models.Bundle
+}
+
+func NewBundleRepository() *BundleRepository {
+	return &BundleRepository{bundles: []models.Bundle{
+		{ID: "starter-kit", Name: "Starter kit", Description: "Product 1 with two Product 2", Price: 45.0, Components: []models.BundleComponent{
+			{ProductID: "prod1", Quantity: 1},
+			{ProductID: "prod2", Quantity: 2},
+		}},
+		{ID: "reading-box", Name: "Reading box", Description: "A book, a snack and a white t-shirt", Price: 50.0, Components: []models.BundleComponent{
+			{ProductID: "novel", Quantity: 1},
+			{ProductID: "olive-oil", Quantity: 1},
+			{ProductID: "tshirt-m-white", Quantity: 1},
+		}},
+	}}
+}
+
+func (r *BundleRepository) GetAll(ctx context.Context) ([]models.Bundle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.bundles), nil
+}
+
+func (r *BundleRepository) GetByID(ctx context.Context, id string) (*models.Bundle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, b := range r.bundles {
+		if b.ID == id {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *BundleRepository) Save(ctx context.Context, bundle *models.Bundle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, b := range r.bundles {
+		if b.ID == bundle.ID {
+			r.bundles[i] = *bundle
+			return nil
+		}
+	}
+	r.bundles = append(r.bundles, *bundle)
+	return nil
+}
+
+func (r *BundleRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"sync"
+)
+
+type CategoryRepository struct {
+	mu         sync.RWMutex
+	categories []models.Category
+}
+
+func NewCategoryRepository() *CategoryRepository {
+	return &CategoryRepository{categories: []models.Category{
+		{ID: "cat-apparel", Slug: "apparel", Name: "Apparel", Position: 1, TaxClass: models.TaxClassStandard},
+		{ID: "cat-tshirts", Slug: "t-shirts", Name: "T-Shirts", ParentID: "cat-apparel", Position: 1},
+		{ID: "cat-electronics", Slug: "electronics", Name: "Electronics", Position: 2, TaxClass: models.TaxClassStandard},
+		{ID: "cat-accessories", Slug: "accessories", Name: "Accessories", ParentID: "cat-electronics", Position: 1},
+		{ID: "cat-books", Slug: "books", Name: "Books", Position: 3, TaxClass: models.TaxClassSuperReduced},
+		{ID: "cat-food", Slug: "food", Name: "Food", Position: 4, TaxClass: models.TaxClassReduced},
+		{ID: "cat-gifts", Slug: "gifts", Name: "Gift ideas", Position: 5},
+	}}
+}
+
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.Category(nil), r.categories...), nil
+}
+
+func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.Slug == slug {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *CategoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"purchase-cart-service/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreditNoteRepository keeps the credit notes in save order; numbers come from
+// a counter advanced under the same lock as the write issuing the note, so
+// there are no gaps
+type CreditNoteRepository struct {
+	mu     sync.RWMutex
+	notes  []models.CreditNote
+	issued int
+}
+
+func NewCreditNoteRepository() *CreditNoteRepository {
+	return &CreditNoteRepository{}
+}
+
+func (c *CreditNoteRepository) Save(ctx context.Context, note *models.CreditNote) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	note.ID = uuid.NewString()
+	c.number(note)
+	c.notes = append(c.notes, cloneCreditNote(*note))
+	return nil
+}
+
+func (c *CreditNoteRepository) Update(ctx context.Context, note *models.CreditNote) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.notes {
+		if c.notes[i].ID == note.ID {
+			c.number(note)
+			c.notes[i] = cloneCreditNote(*note)
+			return nil
+		}
+	}
+	return errors.New("credit note not found")
+}
+
+// number assigns the next number to a note issued without one
+func (c *CreditNoteRepository) number(note *models.CreditNote) {
+	if note.Status != models.CreditNoteStatusIssued || note.Number != "" {
+		return
+	}
+	c.issued++
+	note.Number = fmt.Sprintf("CN-%06d", c.issued)
+	note.IssuedAt = time.Now().UTC()
+}
+
+func (c *CreditNoteRepository) GetByNumber(ctx context.Context, number string) (*models.CreditNote, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, note := range c.notes {
+		if note.Number == number {
+			note = cloneCreditNote(note)
+			return &note, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *CreditNoteRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.CreditNote, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var notes []*models.CreditNote
+	for _, note := range c.notes {
+		if note.OrderID == orderID {
+			note = cloneCreditNote(note)
+			notes = append(notes, &note)
+		}
+	}
+	return notes, nil
+}
+
+func (c *CreditNoteRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func cloneCreditNote(note models.CreditNote) models.CreditNote {
+	note.Lines = append([]models.CreditNoteLine(nil), note.Lines...)
+	return note
+}
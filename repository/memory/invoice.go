@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/models"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type invoiceSeries struct {
+	country string
+	year    int
+}
+
+type InvoiceRepository struct {
+	mu       sync.RWMutex
+	counters map[invoiceSeries]int64
+	byOrder  map[string]models.Invoice
+	byNumber map[string]string
+}
+
+func NewInvoiceRepository() *InvoiceRepository {
+	return &InvoiceRepository{
+		counters: make(map[invoiceSeries]int64),
+		byOrder:  make(map[string]models.Invoice),
+		byNumber: make(map[string]string),
+	}
+}
+
+func (r *InvoiceRepository) Save(ctx context.Context, invoice *models.Invoice, format func(sequence int64) string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byOrder[invoice.OrderID]; ok {
+		return errors.New("order already invoiced")
+	}
+	series := invoiceSeries{country: invoice.CountryCode, year: invoice.FiscalYear}
+	sequence := r.counters[series] + 1
+	number := format(sequence)
+	if _, ok := r.byNumber[number]; ok {
+		return errors.New("invoice number already issued: " + number)
+	}
+	r.counters[series] = sequence
+	invoice.ID = uuid.NewString()
+	invoice.Sequence = sequence
+	invoice.Number = number
+	r.byOrder[invoice.OrderID] = cloneInvoice(*invoice)
+	r.byNumber[number] = invoice.OrderID
+	return nil
+}
+
+func (r *InvoiceRepository) GetByOrderID(ctx context.Context, orderID string) (*models.Invoice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if invoice, ok := r.byOrder[orderID]; ok {
+		invoice = cloneInvoice(invoice)
+		return &invoice, nil
+	}
+	return nil, nil
+}
+
+func (r *InvoiceRepository) GetByNumber(ctx context.Context, number string) (*models.Invoice, error) {
+	r.mu.RLock()
+	orderID, ok := r.byNumber[number]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return r.GetByOrderID(ctx, orderID)
+}
+
+func (r *InvoiceRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func cloneInvoice(invoice models.Invoice) models.Invoice {
+	invoice.Lines = append([]models.InvoiceLine(nil), invoice.Lines...)
+	return invoice
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"purchase-cart-service/models"
 	"sync"
@@ -11,20 +12,38 @@ import (
 type OrderRepository struct {
 	mu     sync.RWMutex
 	orders map[string]*models.Order
+	// outbox receives the messages of Save and Update; nil discards them
+	outbox *OutboxRepository
 }
 
-func NewOrderRepository() *OrderRepository {
-	return &OrderRepository{orders: make(map[string]*models.Order)}
+func NewOrderRepository(outbox *OutboxRepository) *OrderRepository {
+	return &OrderRepository{orders: make(map[string]*models.Order), outbox: outbox}
 }
 
-func (o *OrderRepository) Save(ctx context.Context, order *models.Order) error {
+func (o *OrderRepository) Save(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
-	order.ID = uuid.NewString()
+	if order.ID == "" {
+		order.ID = uuid.NewString()
+	}
 	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
 	o.orders[order.ID] = order
+	o.outbox.append(outbox)
 	return nil
 }
+func (o *OrderRepository) Update(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.orders[order.ID]; !ok {
+		return errors.New("order not found")
+	}
+	order.UpdatedAt = time.Now()
+	o.orders[order.ID] = order
+	o.outbox.append(outbox)
+	return nil
+}
+
 func (o *OrderRepository) GetByID(ctx context.Context, id string) (*models.Order, error) {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
@@ -44,3 +63,7 @@ func (o *OrderRepository) GetAll(ctx context.Context) ([]*models.Order, error) {
 	return orders, nil
 
 }
+
+func (o *OrderRepository) Ping(ctx context.Context) error {
+	return nil
+}
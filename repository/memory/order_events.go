@@ -0,0 +1,396 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"purchase-cart-service/models"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSnapshotInterval is the number of events between two snapshots
+const DefaultSnapshotInterval = 50
+
+// orderState is the payload of the created and updated events, and the content of the snapshots
+type orderState struct {
+	Status      string `json:"status"`
+	CountryCode string `json:"country_code,omitempty"`
+	// BillingAddress is nil when the order has none
+	BillingAddress  *addressState `json:"billing_address,omitempty"`
+	ShippingAddress *addressState `json:"shipping_address,omitempty"`
+	BuyerVATID      string        `json:"buyer_vat_id,omitempty"`
+	ReverseCharge   bool          `json:"reverse_charge,omitempty"`
+	TaxTerritory    string        `json:"tax_territory,omitempty"`
+	Items           []itemState   `json:"items"`
+	TotalPrice      float64       `json:"total_price"`
+	TotalVAT        float64       `json:"total_vat"`
+}
+
+type itemState struct {
+	ProductID string  `json:"product_id"`
+	SKU       string  `json:"sku,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	VATRate   float64 `json:"vat_rate"`
+	TaxClass  string  `json:"tax_class,omitempty"`
+	VAT       float64 `json:"vat"`
+	// Taxes is omitted by the events recorded before the jurisdiction breakdown
+	Taxes            []lineTaxState `json:"taxes,omitempty"`
+	PriceMode        string         `json:"price_mode,omitempty"`
+	UnitPriceWithVAT float64        `json:"unit_price_with_vat,omitempty"`
+	PriceList        string         `json:"price_list,omitempty"`
+	// Bundle is nil for the products ordered on their own
+	Bundle *bundleState `json:"bundle,omitempty"`
+}
+
+type bundleState struct {
+	Line     int    `json:"line"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+type lineTaxState struct {
+	Type   string  `json:"type"`
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+type addressState struct {
+	Name        string `json:"name,omitempty"`
+	Line1       string `json:"line1,omitempty"`
+	Line2       string `json:"line2,omitempty"`
+	City        string `json:"city,omitempty"`
+	PostalCode  string `json:"postal_code,omitempty"`
+	Region      string `json:"region,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+type statusChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type orderSnapshot struct {
+	version int
+	order   models.Order
+}
+
+// EventSourcedOrderRepository stores an append-only event stream per order
+// and rebuilds the orders by replaying it from the latest snapshot
+type EventSourcedOrderRepository struct {
+	mu               sync.RWMutex
+	streams          map[string][]models.OrderEvent
+	snapshots        map[string]orderSnapshot
+	ids              []string
+	snapshotInterval int
+	// outbox receives the messages of Save and Update; nil discards them
+	outbox *OutboxRepository
+}
+
+// NewEventSourcedOrderRepository takes a snapshot every snapshotInterval
+// events, DefaultSnapshotInterval when not positive
+func NewEventSourcedOrderRepository(outbox *OutboxRepository, snapshotInterval int) *EventSourcedOrderRepository {
+	if snapshotInterval <= 0 {
+		snapshotInterval = DefaultSnapshotInterval
+	}
+	return &EventSourcedOrderRepository{
+		streams:          make(map[string][]models.OrderEvent),
+		snapshots:        make(map[string]orderSnapshot),
+		snapshotInterval: snapshotInterval,
+		outbox:           outbox,
+	}
+}
+
+func (r *EventSourcedOrderRepository) Save(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if order.ID == "" {
+		order.ID = uuid.NewString()
+	}
+	if _, ok := r.streams[order.ID]; ok {
+		return fmt.Errorf("order %s already exists", order.ID)
+	}
+	now := time.Now()
+	event, err := newOrderEvent(order.ID, 1, models.OrderEventCreated, now, stateOf(order))
+	if err != nil {
+		return err
+	}
+	r.streams[order.ID] = []models.OrderEvent{event}
+	r.ids = append(r.ids, order.ID)
+	r.outbox.append(outbox)
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	return nil
+}
+
+// Update appends the events describing the differences between the stored
+// order and the given one; an unchanged order appends nothing
+func (r *EventSourcedOrderRepository) Update(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, err := r.load(order.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("order not found")
+	}
+	stream := r.streams[order.ID]
+	version := len(stream)
+	now := time.Now()
+	var changes []models.OrderEvent
+	if current.Status != order.Status {
+		version++
+		event, err := newOrderEvent(order.ID, version, models.OrderEventStatusChanged, now, statusChange{From: current.Status, To: order.Status})
+		if err != nil {
+			return err
+		}
+		changes = append(changes, event)
+	}
+	if !sameContent(current, order) {
+		version++
+		event, err := newOrderEvent(order.ID, version, models.OrderEventUpdated, now, stateOf(order))
+		if err != nil {
+			return err
+		}
+		changes = append(changes, event)
+	}
+	r.streams[order.ID] = append(stream, changes...)
+	r.outbox.append(outbox)
+	if len(changes) > 0 {
+		order.UpdatedAt = now
+	}
+	r.snapshotIfDue(order.ID, len(stream), version)
+	return nil
+}
+
+func (r *EventSourcedOrderRepository) GetByID(ctx context.Context, id string) (*models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.load(id)
+}
+
+func (r *EventSourcedOrderRepository) GetAll(ctx context.Context) ([]*models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []*models.Order
+	for _, id := range r.ids {
+		order, err := r.load(id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (r *EventSourcedOrderRepository) GetHistory(ctx context.Context, id string) ([]models.OrderEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stream, ok := r.streams[id]
+	if !ok {
+		return nil, nil
+	}
+	history := make([]models.OrderEvent, len(stream))
+	for i, e := range stream {
+		e.Data = append([]byte(nil), e.Data...)
+		history[i] = e
+	}
+	return history, nil
+}
+
+func (r *EventSourcedOrderRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// load rebuilds an order from its latest snapshot and the events after it
+func (r *EventSourcedOrderRepository) load(id string) (*models.Order, error) {
+	stream, ok := r.streams[id]
+	if !ok {
+		return nil, nil
+	}
+	order := models.Order{ID: id}
+	from := 0
+	if snapshot, ok := r.snapshots[id]; ok {
+		order = snapshot.order
+		order.Items = append([]models.Item(nil), snapshot.order.Items...)
+		from = snapshot.version
+	}
+	for _, event := range stream[from:] {
+		if err := apply(&order, event); err != nil {
+			return nil, err
+		}
+	}
+	return &order, nil
+}
+
+// snapshotIfDue takes a snapshot when the stream crossed a multiple of the interval
+func (r *EventSourcedOrderRepository) snapshotIfDue(id string, before, after int) {
+	if after/r.snapshotInterval == before/r.snapshotInterval {
+		return
+	}
+	order, err := r.load(id)
+	if err != nil || order == nil {
+		return
+	}
+	r.snapshots[id] = orderSnapshot{version: after, order: *order}
+}
+
+func apply(order *models.Order, event models.OrderEvent) error {
+	switch event.Type {
+	case models.OrderEventCreated, models.OrderEventUpdated:
+		var state orderState
+		if err := json.Unmarshal(event.Data, &state); err != nil {
+			return fmt.Errorf("order %s event %d: %w", event.OrderID, event.Version, err)
+		}
+		order.Status = state.Status
+		order.CountryCode = state.CountryCode
+		order.BuyerVATID = state.BuyerVATID
+		order.ReverseCharge = state.ReverseCharge
+		order.TaxTerritory = state.TaxTerritory
+		order.BillingAddress = models.Address{}
+		if state.BillingAddress != nil {
+			order.BillingAddress = models.Address(*state.BillingAddress)
+		}
+		order.ShippingAddress = models.Address{}
+		if state.ShippingAddress != nil {
+			order.ShippingAddress = models.Address(*state.ShippingAddress)
+		}
+		order.Items = make([]models.Item, 0, len(state.Items))
+		for _, it := range state.Items {
+			order.Items = append(order.Items, it.item())
+		}
+		order.TotalPrice = state.TotalPrice
+		order.TotalVAT = state.TotalVAT
+		if event.Type == models.OrderEventCreated {
+			order.CreatedAt = event.OccurredAt
+		}
+	case models.OrderEventStatusChanged:
+		var change statusChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("order %s event %d: %w", event.OrderID, event.Version, err)
+		}
+		order.Status = change.To
+	default:
+		return fmt.Errorf("order %s event %d: unknown type %q", event.OrderID, event.Version, event.Type)
+	}
+	order.UpdatedAt = event.OccurredAt
+	return nil
+}
+
+func newOrderEvent(orderID string, version int, eventType string, at time.Time, data any) (models.OrderEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return models.OrderEvent{}, err
+	}
+	return models.OrderEvent{
+		OrderID:    orderID,
+		Version:    version,
+		Type:       eventType,
+		OccurredAt: at.UTC(),
+		Data:       payload,
+	}, nil
+}
+
+func stateOf(order *models.Order) orderState {
+	items := make([]itemState, 0, len(order.Items))
+	for _, it := range order.Items {
+		items = append(items, itemStateOf(it))
+	}
+	var billingAddress, shippingAddress *addressState
+	if !order.BillingAddress.IsZero() {
+		address := addressState(order.BillingAddress)
+		billingAddress = &address
+	}
+	if !order.ShippingAddress.IsZero() {
+		address := addressState(order.ShippingAddress)
+		shippingAddress = &address
+	}
+	return orderState{
+		Status:          order.Status,
+		CountryCode:     order.CountryCode,
+		BillingAddress:  billingAddress,
+		ShippingAddress: shippingAddress,
+		BuyerVATID:      order.BuyerVATID,
+		ReverseCharge:   order.ReverseCharge,
+		TaxTerritory:    order.TaxTerritory,
+		Items:           items,
+		TotalPrice:      order.TotalPrice,
+		TotalVAT:        order.TotalVAT,
+	}
+}
+
+func sameContent(a, b *models.Order) bool {
+	if a.CountryCode != b.CountryCode || a.BillingAddress != b.BillingAddress || a.ShippingAddress != b.ShippingAddress ||
+		a.BuyerVATID != b.BuyerVATID || a.ReverseCharge != b.ReverseCharge || a.TaxTerritory != b.TaxTerritory ||
+		a.TotalPrice != b.TotalPrice || a.TotalVAT != b.TotalVAT || len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i := range a.Items {
+		x, y := a.Items[i], b.Items[i]
+		if x.ProductID != y.ProductID || x.SKU != y.SKU || x.TaxClass != y.TaxClass || x.Name != y.Name || x.Quantity != y.Quantity ||
+			x.UnitPrice != y.UnitPrice || x.VATRate != y.VATRate || x.VAT != y.VAT || !slices.Equal(x.Taxes, y.Taxes) ||
+			x.PriceMode != y.PriceMode || x.UnitPriceWithVAT != y.UnitPriceWithVAT || x.PriceList != y.PriceList ||
+			(x.Bundle == nil) != (y.Bundle == nil) || x.Bundle != nil && *x.Bundle != *y.Bundle {
+			return false
+		}
+	}
+	return true
+}
+
+func itemStateOf(item models.Item) itemState {
+	state := itemState{
+		ProductID:        item.ProductID,
+		SKU:              item.SKU,
+		TaxClass:         item.TaxClass,
+		Name:             item.Name,
+		Quantity:         item.Quantity,
+		UnitPrice:        item.UnitPrice,
+		VATRate:          item.VATRate,
+		VAT:              item.VAT,
+		PriceMode:        item.PriceMode,
+		UnitPriceWithVAT: item.UnitPriceWithVAT,
+		PriceList:        item.PriceList,
+	}
+	if b := item.Bundle; b != nil {
+		state.Bundle = &bundleState{Line: b.Line, ID: b.ID, Name: b.Name, Quantity: b.Quantity}
+	}
+	for _, t := range item.Taxes {
+		state.Taxes = append(state.Taxes, lineTaxState{Type: t.Type, Name: t.Name, Rate: t.Rate, Amount: t.Amount})
+	}
+	return state
+}
+
+func (s itemState) item() models.Item {
+	item := models.Item{
+		ProductID:        s.ProductID,
+		SKU:              s.SKU,
+		TaxClass:         s.TaxClass,
+		Name:             s.Name,
+		Quantity:         s.Quantity,
+		UnitPrice:        s.UnitPrice,
+		VATRate:          s.VATRate,
+		VAT:              s.VAT,
+		PriceMode:        s.PriceMode,
+		UnitPriceWithVAT: s.UnitPriceWithVAT,
+		PriceList:        s.PriceList,
+	}
+	if b := s.Bundle; b != nil {
+		item.Bundle = &models.ItemBundle{Line: b.Line, ID: b.ID, Name: b.Name, Quantity: b.Quantity}
+	}
+	for _, t := range s.Taxes {
+		item.Taxes = append(item.Taxes, models.LineTax{
+			TaxJurisdiction: models.TaxJurisdiction{Type: t.Type, Name: t.Name, Rate: t.Rate},
+			Amount:          t.Amount,
+		})
+	}
+	return item
+}
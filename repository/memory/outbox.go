@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxRepository struct {
+	mu       sync.RWMutex
+	sequence int64
+	messages map[string]*models.OutboxMessage
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{messages: make(map[string]*models.OutboxMessage)}
+}
+
+// append stores the messages; repositories sharing the outbox call it while
+// holding their own lock, so that the change and its messages are committed together
+func (r *OutboxRepository) append(messages []*models.OutboxMessage) {
+	if r == nil || len(messages) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range messages {
+		if m.ID == "" {
+			m.ID = uuid.NewString()
+		}
+		r.sequence++
+		m.Sequence = r.sequence
+		stored := cloneMessage(*m)
+		r.messages[m.ID] = &stored
+	}
+}
+
+func (r *OutboxRepository) GetPending(ctx context.Context, limit int) ([]*models.OutboxMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var pending []*models.OutboxMessage
+	for _, m := range r.messages {
+		if m.DispatchedAt == nil && m.DeadLetteredAt == nil {
+			msg := cloneMessage(*m)
+			pending = append(pending, &msg)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Sequence < pending[j].Sequence })
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, id string, sink string) error {
+	return r.modify(id, func(m *models.OutboxMessage) {
+		for _, s := range m.Delivered {
+			if s == sink {
+				return
+			}
+		}
+		m.Delivered = append(m.Delivered, sink)
+	})
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause string) error {
+	return r.modify(id, func(m *models.OutboxMessage) {
+		m.Attempts++
+		m.LastError = cause
+	})
+}
+
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id string) error {
+	return r.modify(id, func(m *models.OutboxMessage) {
+		now := time.Now().UTC()
+		m.DispatchedAt = &now
+	})
+}
+
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id string) error {
+	return r.modify(id, func(m *models.OutboxMessage) {
+		now := time.Now().UTC()
+		m.DeadLetteredAt = &now
+	})
+}
+
+func (r *OutboxRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *OutboxRepository) modify(id string, fn func(m *models.OutboxMessage)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.messages[id]
+	if !ok {
+		return errors.New("outbox message not found")
+	}
+	fn(m)
+	return nil
+}
+
+func cloneMessage(m models.OutboxMessage) models.OutboxMessage {
+	m.Payload = append([]byte(nil), m.Payload...)
+	m.Delivered = append([]string(nil), m.Delivered...)
+	if m.DispatchedAt != nil {
+		at := *m.DispatchedAt
+		m.DispatchedAt = &at
+	}
+	if m.DeadLetteredAt != nil {
+		at := *m.DeadLetteredAt
+		m.DeadLetteredAt = &at
+	}
+	return m
+}
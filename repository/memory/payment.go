@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentRepository struct {
+	mu      sync.RWMutex
+	intents map[string]models.PaymentIntent
+}
+
+func NewPaymentRepository() *PaymentRepository {
+	return &PaymentRepository{intents: make(map[string]models.PaymentIntent)}
+}
+
+func (p *PaymentRepository) Save(ctx context.Context, intent *models.PaymentIntent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if intent.ID == "" {
+		intent.ID = uuid.NewString()
+	}
+	intent.CreatedAt = time.Now()
+	intent.UpdatedAt = intent.CreatedAt
+	p.intents[intent.ID] = *intent
+	return nil
+}
+
+func (p *PaymentRepository) Update(ctx context.Context, intent *models.PaymentIntent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.intents[intent.ID]; !ok {
+		return errors.New("payment intent not found")
+	}
+	intent.UpdatedAt = time.Now()
+	p.intents[intent.ID] = *intent
+	return nil
+}
+
+func (p *PaymentRepository) GetByID(ctx context.Context, id string) (*models.PaymentIntent, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if intent, ok := p.intents[id]; ok {
+		return &intent, nil
+	}
+	return nil, nil
+}
+
+func (p *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.PaymentIntent, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var intents []*models.PaymentIntent
+	for _, intent := range p.intents {
+		if intent.OrderID == orderID {
+			intent := intent
+			intents = append(intents, &intent)
+		}
+	}
+	sort.Slice(intents, func(i, j int) bool { return intents[i].CreatedAt.Before(intents[j].CreatedAt) })
+	return intents, nil
+}
+
+func (p *PaymentRepository) Ping(ctx context.Context) error {
+	return nil
+}
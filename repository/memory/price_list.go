@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+type PriceListRepository struct {
+	mu    sync.RWMutex
+	lists []models.PriceList
+}
+
+func NewPriceListRepository() *PriceListRepository {
+	return &PriceListRepository{lists: []models.PriceList{
+		{ID: "wholesale", Name: "Wholesale", CustomerGroups: []string{"wholesale"}, Prices: []models.ListPrice{
+			{ProductID: "prod1", Price: 8.0},
+			{ProductID: "prod2", Price: 16.0, Tiers: []models.PriceTier{{MinQuantity: 20, Price: 14.0}}},
+			{ProductID: "tshirt", Price: 12.0, Tiers: []models.PriceTier{{MinQuantity: 24, Price: 10.0}}},
+		}},
+		{ID: "acme-2026", Name: "ACME contract 2026", Clients: []string{"acme"}, Prices: []models.ListPrice{
+			{ProductID: "prod1", Price: 7.5, ValidFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), ValidTo: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{ProductID: "tshirt-s-black", Price: 13.0},
+		}},
+	}}
+}
+
+func (r *PriceListRepository) GetAll(ctx context.Context) ([]models.PriceList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.lists), nil
+}
+
+func (r *PriceListRepository) GetByID(ctx context.Context, id string) (*models.PriceList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, l := range r.lists {
+		if l.ID == id {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *PriceListRepository) Save(ctx context.Context, list *models.PriceList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, l := range r.lists {
+		if l.ID == list.ID {
+			r.lists[i] = *list
+			return nil
+		}
+	}
+	r.lists = append(r.lists, *list)
+	return nil
+}
+
+func (r *PriceListRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -2,36 +2,89 @@ package memory
 
 import (
 	"context"
+	"errors"
 	"purchase-cart-service/models"
+	"slices"
 	"sync"
 )
 
 type ProductRepository struct {
 	products map[string]models.Product
-	mu       sync.RWMutex
+	// skus maps the SKU of every variant to its product ID
+	skus map[string]string
+	mu   sync.RWMutex
+	// outbox receives the messages of Update; nil discards them
+	outbox *OutboxRepository
 }
 
-func NewProductRepository() *ProductRepository {
+func NewProductRepository(outbox *OutboxRepository) *ProductRepository {
 	products := make(map[string]models.Product)
-	products["prod1"] = models.Product{ID: "prod1", Name: "Product 1", Description: "Description of Product 1", Price: 10.0}
-	products["prod2"] = models.Product{ID: "prod2", Name: "Product 2", Description: "Description of Product 2", Price: 20.0}
-	products["prod3"] = models.Product{ID: "prod2", Name: "Product 3", Description: "Description of Product 3", Price: 20.0}
-	products["prod4"] = models.Product{ID: "prod2", Name: "Product 4", Description: "Description of Product 4", Price: 20.0}
-	products["prod5"] = models.Product{ID: "prod2", Name: "Product 5", Description: "Description of Product 5", Price: 20.0}
+	products["prod1"] = models.Product{ID: "prod1", Name: "Product 1", Description: "Description of Product 1", Price: 10.0, CategoryIDs: []string{"cat-electronics"}}
+	products["prod2"] = models.Product{ID: "prod2", Name: "Product 2", Description: "Description of Product 2", Price: 20.0, CategoryIDs: []string{"cat-electronics"}, Tiers: []models.PriceTier{
+		{MinQuantity: 10, Price: 18.0},
+		{MinQuantity: 50, Price: 16.0},
+	}}
+	products["prod3"] = models.Product{ID: "prod3", Name: "Product 3", Description: "Description of Product 3", Price: 20.0, CategoryIDs: []string{"cat-accessories", "cat-gifts"}}
+	products["prod4"] = models.Product{ID: "prod4", Name: "Product 4", Description: "Description of Product 4", Price: 20.0}
+	products["prod5"] = models.Product{ID: "prod5", Name: "Product 5", Description: "Description of Product 5", Price: 20.0}
+	products["novel"] = models.Product{ID: "novel", Name: "Novel", Description: "Paperback novel", Price: 20.0, CategoryIDs: []string{"cat-books"}}
+	products["olive-oil"] = models.Product{ID: "olive-oil", Name: "Olive oil", Description: "Extra virgin olive oil", Price: 20.0, CategoryIDs: []string{"cat-food"}}
+	products["tshirt"] = models.Product{ID: "tshirt", Name: "T-Shirt", Description: "Cotton t-shirt", Price: 15.0, CategoryIDs: []string{"cat-tshirts", "cat-gifts"}, Variants: []models.Variant{
+		{SKU: "tshirt-s-white", Attributes: []models.Attribute{{Name: "size", Value: "S"}, {Name: "color", Value: "white"}}, Stock: 10},
+		{SKU: "tshirt-m-white", Attributes: []models.Attribute{{Name: "size", Value: "M"}, {Name: "color", Value: "white"}}, Stock: 10},
+		{SKU: "tshirt-l-white", Attributes: []models.Attribute{{Name: "size", Value: "L"}, {Name: "color", Value: "white"}}, Stock: 5},
+		{SKU: "tshirt-s-black", Attributes: []models.Attribute{{Name: "size", Value: "S"}, {Name: "color", Value: "black"}}, Price: 17.0, Stock: 8},
+		{SKU: "tshirt-m-black", Attributes: []models.Attribute{{Name: "size", Value: "M"}, {Name: "color", Value: "black"}}, Price: 17.0, Stock: 0},
+	}}
 
-	return &ProductRepository{products: products}
+	r := &ProductRepository{products: products, skus: make(map[string]string), outbox: outbox}
+	for _, p := range products {
+		r.indexSKUs(p)
+	}
+	return r
+}
+
+func (p *ProductRepository) indexSKUs(product models.Product) {
+	for _, v := range product.Variants {
+		p.skus[v.SKU] = product.ID
+	}
+}
+
+// lookup finds a product by ID or by the SKU of one of its variants
+func (p *ProductRepository) lookup(id string) (models.Product, bool) {
+	if product, ok := p.products[id]; ok {
+		return product, true
+	}
+	if productID, ok := p.skus[id]; ok {
+		product, ok := p.products[productID]
+		return product, ok
+	}
+	return models.Product{}, false
 }
 
 func (p *ProductRepository) GetProduct(ctx context.Context, id string) (*models.Product, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	if product, ok := p.products[id]; ok {
+	if product, ok := p.lookup(id); ok {
 		return &product, nil
 	}
 	return nil, nil
 }
 
+func (p *ProductRepository) GetProducts(ctx context.Context, ids []string) (map[string]models.Product, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	products := make(map[string]models.Product, len(ids))
+	for _, id := range ids {
+		if product, ok := p.lookup(id); ok {
+			products[id] = product
+		}
+	}
+	return products, nil
+}
+
 func (p *ProductRepository) GetAll(ctx context.Context) ([]models.Product, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -42,3 +95,66 @@ func (p *ProductRepository) GetAll(ctx context.Context) ([]models.Product, error
 	}
 	return products, nil
 }
+
+func (p *ProductRepository) Update(ctx context.Context, product *models.Product, outbox ...*models.OutboxMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old, ok := p.products[product.ID]
+	if !ok {
+		return errors.New("product not found")
+	}
+	stock := make(map[string]int, len(old.Variants))
+	for _, v := range old.Variants {
+		stock[v.SKU] = v.Stock
+		delete(p.skus, v.SKU)
+	}
+	// the caller's copy may predate a reservation: keep the stored stock
+	product.Variants = slices.Clone(product.Variants)
+	for i := range product.Variants {
+		product.Variants[i].Stock = stock[product.Variants[i].SKU]
+	}
+	p.products[product.ID] = *product
+	p.indexSKUs(*product)
+	p.outbox.append(outbox)
+	return nil
+}
+
+func (p *ProductRepository) ReserveStock(ctx context.Context, sku string, quantity int) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.adjustStock(sku, -quantity)
+}
+
+func (p *ProductRepository) ReleaseStock(ctx context.Context, sku string, quantity int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.adjustStock(sku, quantity)
+	return err
+}
+
+// adjustStock changes the stock of a SKU by delta, unless it would go negative
+func (p *ProductRepository) adjustStock(sku string, delta int) (bool, error) {
+	product, ok := p.lookup(sku)
+	if !ok {
+		return false, errors.New("SKU not found")
+	}
+	variants := make([]models.Variant, len(product.Variants))
+	copy(variants, product.Variants)
+	for i, v := range variants {
+		if v.SKU != sku {
+			continue
+		}
+		if v.Stock+delta < 0 {
+			return false, nil
+		}
+		variants[i].Stock += delta
+		product.Variants = variants
+		p.products[product.ID] = product
+		return true, nil
+	}
+	return false, errors.New("SKU not found")
+}
+
+func (p *ProductRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"sync"
+)
+
+type salesTaxKey struct {
+	state      string
+	postalCode string
+}
+
+type SalesTaxRepository struct {
+	mu      sync.RWMutex
+	regions map[salesTaxKey]models.SalesTaxRegion
+	states  map[string]bool
+}
+
+func NewSalesTaxRepository() *SalesTaxRepository {
+	return &SalesTaxRepository{
+		regions: make(map[salesTaxKey]models.SalesTaxRegion),
+		states:  make(map[string]bool),
+	}
+}
+
+func (r *SalesTaxRepository) GetRegion(ctx context.Context, state, postalCode string) (*models.SalesTaxRegion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if region, ok := r.regions[salesTaxKey{state, postalCode}]; ok {
+		return &region, nil
+	}
+	return nil, nil
+}
+
+func (r *SalesTaxRepository) HasState(ctx context.Context, state string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.states[state], nil
+}
+
+func (r *SalesTaxRepository) ReplaceAll(ctx context.Context, regions []models.SalesTaxRegion) error {
+	byKey := make(map[salesTaxKey]models.SalesTaxRegion, len(regions))
+	states := make(map[string]bool)
+	for _, region := range regions {
+		byKey[salesTaxKey{region.State, region.PostalCode}] = region
+		states[region.State] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.regions = byKey
+	r.states = states
+	return nil
+}
+
+func (r *SalesTaxRepository) Ping(ctx context.Context) error {
+	return nil
+}
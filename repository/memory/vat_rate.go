@@ -1,9 +1,21 @@
 package memory
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/models"
+	"strings"
+	"sync"
+)
 
 type VatRateRepository struct {
 	vatRates map[string]float64
+	// classRates are the rates of the tax classes other than the standard
+	// one, by country
+	classRates map[string]map[string]float64
+
+	mu          sync.RWMutex
+	territories map[string][]models.VATTerritory
 }
 
 func NewVatRateRepository() *VatRateRepository {
@@ -11,10 +23,25 @@ func NewVatRateRepository() *VatRateRepository {
 		vatRates: map[string]float64{
 			"US": 0.0,
 			"UK": 0.2,
+			"GB": 0.2,
 			"DE": 0.19,
+			"ES": 0.21,
+			"FI": 0.255,
 			"FR": 0.2,
+			"GR": 0.24,
 			"IT": 0.22,
 		},
+		classRates: map[string]map[string]float64{
+			"UK": {models.TaxClassReduced: 0.05},
+			"GB": {models.TaxClassReduced: 0.05},
+			"DE": {models.TaxClassReduced: 0.07},
+			"ES": {models.TaxClassReduced: 0.10, models.TaxClassSuperReduced: 0.04},
+			"FI": {models.TaxClassReduced: 0.14, models.TaxClassSuperReduced: 0.10},
+			"FR": {models.TaxClassReduced: 0.055, models.TaxClassSuperReduced: 0.021},
+			"GR": {models.TaxClassReduced: 0.13, models.TaxClassSuperReduced: 0.06},
+			"IT": {models.TaxClassReduced: 0.10, models.TaxClassSuperReduced: 0.04},
+		},
+		territories: make(map[string][]models.VATTerritory),
 	}
 }
 func (v *VatRateRepository) GetVATRate(countryCode string) (float64, error) {
@@ -24,3 +51,68 @@ func (v *VatRateRepository) GetVATRate(countryCode string) (float64, error) {
 	}
 	return rate, nil
 }
+
+// GetVATRateForClass falls back from the super-reduced to the reduced rate,
+// and from the reduced to the standard rate, for the countries without them
+func (v *VatRateRepository) GetVATRateForClass(countryCode string, class string) (float64, error) {
+	standard, err := v.GetVATRate(countryCode)
+	if err != nil {
+		return 0, err
+	}
+	rates := v.classRates[countryCode]
+	switch class {
+	case models.TaxClassZero:
+		return 0, nil
+	case models.TaxClassSuperReduced:
+		if rate, ok := rates[models.TaxClassSuperReduced]; ok {
+			return rate, nil
+		}
+		fallthrough
+	case models.TaxClassReduced:
+		if rate, ok := rates[models.TaxClassReduced]; ok {
+			return rate, nil
+		}
+	}
+	return standard, nil
+}
+
+func (v *VatRateRepository) GetAllVATRates() (map[string]float64, error) {
+	rates := make(map[string]float64, len(v.vatRates))
+	for country, rate := range v.vatRates {
+		rates[country] = rate
+	}
+	return rates, nil
+}
+
+// GetTerritory matches the region first, then the longest postal code prefix
+func (v *VatRateRepository) GetTerritory(destination models.Destination) (*models.VATTerritory, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	postalCode := strings.ToUpper(strings.ReplaceAll(destination.PostalCode, " ", ""))
+	var match *models.VATTerritory
+	for _, t := range v.territories[destination.CountryCode] {
+		if t.Region != "" && strings.EqualFold(t.Region, strings.TrimSpace(destination.Region)) {
+			return &t, nil
+		}
+		if t.PostalCodePrefix != "" && strings.HasPrefix(postalCode, t.PostalCodePrefix) &&
+			(match == nil || len(t.PostalCodePrefix) > len(match.PostalCodePrefix)) {
+			match = &t
+		}
+	}
+	return match, nil
+}
+
+func (v *VatRateRepository) ReplaceTerritories(territories []models.VATTerritory) error {
+	byCountry := make(map[string][]models.VATTerritory)
+	for _, t := range territories {
+		byCountry[t.CountryCode] = append(byCountry[t.CountryCode], t)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.territories = byCountry
+	return nil
+}
+
+func (v *VatRateRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"purchase-cart-service/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type WebhookRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]models.WebhookSubscription
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{subscriptions: make(map[string]models.WebhookSubscription)}
+}
+
+func (w *WebhookRepository) Save(ctx context.Context, subscription *models.WebhookSubscription) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	subscription.ID = uuid.NewString()
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	w.subscriptions[subscription.ID] = cloneSubscription(*subscription)
+	return nil
+}
+
+func (w *WebhookRepository) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.subscriptions[subscription.ID]; !ok {
+		return errors.New("webhook subscription not found")
+	}
+	subscription.UpdatedAt = time.Now()
+	w.subscriptions[subscription.ID] = cloneSubscription(*subscription)
+	return nil
+}
+
+func (w *WebhookRepository) Delete(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscriptions, id)
+	return nil
+}
+
+func (w *WebhookRepository) GetByID(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if s, ok := w.subscriptions[id]; ok {
+		s = cloneSubscription(s)
+		return &s, nil
+	}
+	return nil, nil
+}
+
+func (w *WebhookRepository) GetAll(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var subscriptions []*models.WebhookSubscription
+	for _, s := range w.subscriptions {
+		s = cloneSubscription(s)
+		subscriptions = append(subscriptions, &s)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt) })
+	return subscriptions, nil
+}
+
+func (w *WebhookRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func cloneSubscription(s models.WebhookSubscription) models.WebhookSubscription {
+	s.Events = append([]string(nil), s.Events...)
+	return s
+}
+
+type WebhookDeadLetterRepository struct {
+	mu          sync.RWMutex
+	deadLetters map[string]models.WebhookDeadLetter
+}
+
+func NewWebhookDeadLetterRepository() *WebhookDeadLetterRepository {
+	return &WebhookDeadLetterRepository{deadLetters: make(map[string]models.WebhookDeadLetter)}
+}
+
+func (w *WebhookDeadLetterRepository) Save(ctx context.Context, deadLetter *models.WebhookDeadLetter) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	deadLetter.ID = uuid.NewString()
+	w.deadLetters[deadLetter.ID] = *deadLetter
+	return nil
+}
+
+func (w *WebhookDeadLetterRepository) Delete(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.deadLetters, id)
+	return nil
+}
+
+func (w *WebhookDeadLetterRepository) GetByID(ctx context.Context, id string) (*models.WebhookDeadLetter, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if d, ok := w.deadLetters[id]; ok {
+		return &d, nil
+	}
+	return nil, nil
+}
+
+func (w *WebhookDeadLetterRepository) GetAll(ctx context.Context) ([]*models.WebhookDeadLetter, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var deadLetters []*models.WebhookDeadLetter
+	for _, d := range w.deadLetters {
+		d := d
+		deadLetters = append(deadLetters, &d)
+	}
+	sort.Slice(deadLetters, func(i, j int) bool { return deadLetters[i].FailedAt.Before(deadLetters[j].FailedAt) })
+	return deadLetters, nil
+}
+
+func (w *WebhookDeadLetterRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -7,16 +7,41 @@ import (
 )
 
 type OrderRepository interface {
-	Save(ctx context.Context, order *models.Order) error
+	// Save stores a new order and appends the outbox messages atomically
+	Save(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error
+	// Update stores the changes of an order and appends the outbox messages atomically
+	Update(ctx context.Context, order *models.Order, outbox ...*models.OutboxMessage) error
 	GetByID(ctx context.Context, id string) (*models.Order, error)
 	GetAll(ctx context.Context) ([]*models.Order, error)
 }
 
-func NewOrderRepository(repoType string) OrderRepository {
+// OrderHistoryRepository is implemented by order repositories that keep
+// every change of an order as an immutable event
+type OrderHistoryRepository interface {
+	// GetHistory returns the events of an order, oldest first; nil when the order does not exist
+	GetHistory(ctx context.Context, id string) ([]models.OrderEvent, error)
+}
+
+// WithEventSourcing makes the order repository store an append-only event
+// stream per order, rebuilding the orders by replay. A snapshot is taken every
+// snapshotInterval events, so that long streams are replayed from the last one
+func WithEventSourcing(snapshotInterval int) Option {
+	return func(o *options) {
+		o.eventSourced = true
+		o.snapshotInterval = snapshotInterval
+	}
+}
+
+func NewOrderRepository(repoType string, opts ...Option) OrderRepository {
+	o := applyOptions(opts)
 	var repoOrder OrderRepository
 	switch repoType {
 	case "InMemory":
-		repoOrder = memory.NewOrderRepository()
+		if o.eventSourced {
+			repoOrder = memory.NewEventSourcedOrderRepository(o.memoryOutbox(), o.snapshotInterval)
+		} else {
+			repoOrder = memory.NewOrderRepository(o.memoryOutbox())
+		}
 	}
 	return repoOrder
 }
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+// OutboxRepository gives the relay access to the messages appended by the
+// order and product repositories together with their changes
+type OutboxRepository interface {
+	// GetPending returns up to limit messages neither dispatched nor
+	// dead-lettered, oldest first
+	GetPending(ctx context.Context, limit int) ([]*models.OutboxMessage, error)
+	// MarkDelivered records that sink received the message
+	MarkDelivered(ctx context.Context, id string, sink string) error
+	// MarkFailed records a failed relay attempt
+	MarkFailed(ctx context.Context, id string, cause string) error
+	// MarkDispatched records that every sink received the message
+	MarkDispatched(ctx context.Context, id string) error
+	// MarkDeadLettered takes the message out of the pending ones after its
+	// last failed attempt
+	MarkDeadLettered(ctx context.Context, id string) error
+}
+
+func NewOutboxRepository(repoType string) OutboxRepository {
+	var repoOutbox OutboxRepository
+	switch repoType {
+	case "InMemory":
+		repoOutbox = memory.NewOutboxRepository()
+	}
+	return repoOutbox
+}
+
+// WithOutbox makes the repository append the outbox messages passed to its
+// write methods to outbox, in the same transaction as the change. Without
+// it the messages are discarded
+func WithOutbox(outbox OutboxRepository) Option {
+	return func(o *options) {
+		o.outbox = outbox
+	}
+}
+
+// memoryOutbox returns the in-memory outbox configured in o, if any
+func (o options) memoryOutbox() *memory.OutboxRepository {
+	outbox, _ := o.outbox.(*memory.OutboxRepository)
+	return outbox
+}
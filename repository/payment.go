@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type PaymentRepository interface {
+	Save(ctx context.Context, intent *models.PaymentIntent) error
+	Update(ctx context.Context, intent *models.PaymentIntent) error
+	GetByID(ctx context.Context, id string) (*models.PaymentIntent, error)
+	// GetByOrderID returns the intents of an order, oldest first
+	GetByOrderID(ctx context.Context, orderID string) ([]*models.PaymentIntent, error)
+}
+
+func NewPaymentRepository(repoType string) PaymentRepository {
+	var repoPayment PaymentRepository
+	switch repoType {
+	case "InMemory":
+		repoPayment = memory.NewPaymentRepository()
+	}
+	return repoPayment
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type PriceListRepository interface {
+	GetAll(ctx context.Context) ([]models.PriceList, error)
+	// GetByID returns nil when no price list has the ID
+	GetByID(ctx context.Context, id string) (*models.PriceList, error)
+	// Save creates the price list or replaces the one with the same ID
+	Save(ctx context.Context, list *models.PriceList) error
+}
+
+func NewPriceListRepository(repoType string) PriceListRepository {
+	var repo PriceListRepository
+	switch repoType {
+	case "InMemory":
+		repo = memory.NewPriceListRepository()
+	}
+	return repo
+}
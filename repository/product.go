@@ -6,16 +6,31 @@ import (
 	"purchase-cart-service/repository/memory"
 )
 
+// ProductRepository stores the catalog. The lookups accept either a product
+// ID or the SKU of one of its variants, which finds the parent product
 type ProductRepository interface {
 	GetProduct(ctx context.Context, id string) (*models.Product, error)
+	// GetProducts looks up several products at once, keyed by the requested
+	// IDs; missing IDs are absent from the result
+	GetProducts(ctx context.Context, ids []string) (map[string]models.Product, error)
 	GetAll(ctx context.Context) ([]models.Product, error)
+	// Update stores the changes of a product and appends the outbox messages
+	// atomically. The stock of the variants is left as stored, since only
+	// ReserveStock and ReleaseStock change it, and copied back into product
+	Update(ctx context.Context, product *models.Product, outbox ...*models.OutboxMessage) error
+	// ReserveStock takes quantity units of a SKU from its stock; it reports
+	// false, and takes nothing, when the stock is not enough
+	ReserveStock(ctx context.Context, sku string, quantity int) (bool, error)
+	// ReleaseStock gives back units taken by ReserveStock
+	ReleaseStock(ctx context.Context, sku string, quantity int) error
 }
 
-func NewProductRepository(repoType string) ProductRepository {
+func NewProductRepository(repoType string, opts ...Option) ProductRepository {
+	o := applyOptions(opts)
 	var repoProduct ProductRepository
 	switch repoType {
 	case "InMemory":
-		repoProduct = memory.NewProductRepository()
+		repoProduct = memory.NewProductRepository(o.memoryOutbox())
 	}
 	return repoProduct
 }
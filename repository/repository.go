@@ -0,0 +1,32 @@
+package repository
+
+import "context"
+
+// Closer is implemented by repositories that hold resources (connection pools,
+// buffered writes) which must be flushed and released on shutdown
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// Pinger is implemented by repositories that can verify that their backing
+// store is reachable; it is used by the readiness probe
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Option configures the repositories built by the factories
+type Option func(*options)
+
+type options struct {
+	outbox           OutboxRepository
+	eventSourced     bool
+	snapshotInterval int
+}
+
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
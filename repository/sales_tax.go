@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+// SalesTaxRepository holds the US sales tax table, one region per ZIP code
+type SalesTaxRepository interface {
+	// GetRegion returns the rates of a ZIP code of a state, nil if the table has none
+	GetRegion(ctx context.Context, state, postalCode string) (*models.SalesTaxRegion, error)
+	// HasState reports whether the table covers a state; sales to states it
+	// does not cover are not taxed
+	HasState(ctx context.Context, state string) (bool, error)
+	// ReplaceAll swaps the whole table
+	ReplaceAll(ctx context.Context, regions []models.SalesTaxRegion) error
+}
+
+func NewSalesTaxRepository(repoType string) SalesTaxRepository {
+	var repoSalesTax SalesTaxRepository
+	switch repoType {
+	case "InMemory":
+		repoSalesTax = memory.NewSalesTaxRepository()
+	}
+	return repoSalesTax
+}
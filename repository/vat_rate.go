@@ -1,9 +1,21 @@
 package repository
 
-import "purchase-cart-service/repository/memory"
+import (
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
 
 type VatRateRepository interface {
 	GetVATRate(countryCode string) (float64, error)
+	// GetVATRateForClass returns the rate of a tax class in a country; the
+	// classes the country has no rate for are charged the closest higher rate
+	GetVATRateForClass(countryCode string, class string) (float64, error)
+	GetAllVATRates() (map[string]float64, error)
+	// GetTerritory returns the special territory destination is in, nil when
+	// the rules of its country apply
+	GetTerritory(destination models.Destination) (*models.VATTerritory, error)
+	// ReplaceTerritories swaps the table of special territories
+	ReplaceTerritories(territories []models.VATTerritory) error
 }
 
 func NewVatRateRepository(repoType string) VatRateRepository {
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository/memory"
+)
+
+type WebhookRepository interface {
+	Save(ctx context.Context, subscription *models.WebhookSubscription) error
+	Update(ctx context.Context, subscription *models.WebhookSubscription) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	GetAll(ctx context.Context) ([]*models.WebhookSubscription, error)
+}
+
+type WebhookDeadLetterRepository interface {
+	Save(ctx context.Context, deadLetter *models.WebhookDeadLetter) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*models.WebhookDeadLetter, error)
+	GetAll(ctx context.Context) ([]*models.WebhookDeadLetter, error)
+}
+
+func NewWebhookRepository(repoType string) WebhookRepository {
+	var repo WebhookRepository
+	switch repoType {
+	case "InMemory":
+		repo = memory.NewWebhookRepository()
+	}
+	return repo
+}
+
+func NewWebhookDeadLetterRepository(repoType string) WebhookDeadLetterRepository {
+	var repo WebhookDeadLetterRepository
+	switch repoType {
+	case "InMemory":
+		repo = memory.NewWebhookDeadLetterRepository()
+	}
+	return repo
+}
@@ -0,0 +1,18 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Trova la root del repository
+repo_root="$(cd "$(dirname "${BASH_SOURCE[0]}")"/.. && pwd)"
+cd "$repo_root"
+
+# Rigenera il codice Go del gRPC API a partire dai file .proto.
+# Richiede: protoc, protoc-gen-go, protoc-gen-go-grpc
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+PROTO_DIR="internal/api/grpc/proto"
+
+echo "+ protoc (${PROTO_DIR})"
+protoc -I "$PROTO_DIR" \
+  --go_out=. --go_opt=module=purchase-cart-service \
+  --go-grpc_out=. --go-grpc_opt=module=purchase-cart-service \
+  $(cd "$PROTO_DIR" && find . -name '*.proto' | sed 's|^\./||')
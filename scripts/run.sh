@@ -12,6 +12,7 @@ GIN_MODE="${GIN_MODE:-release}"
 export GIN_MODE
 
 CONFIG_PATH="${CONFIG_PATH:-config.json}"
+export CONFIG_PATH
 
 if [[ ! -f "$CONFIG_PATH" ]]; then
   echo "WARN: config file '${CONFIG_PATH}' non trovato. Procedo comunque..." >&2
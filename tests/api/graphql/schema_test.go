@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	graphqlapi "purchase-cart-service/internal/api/graphql"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingProductRepository conta le chiamate al repository prodotti
+type countingProductRepository struct {
+	repository.ProductRepository
+	single int
+	batch  int
+}
+
+func (r *countingProductRepository) GetProduct(ctx context.Context, id string) (*models.Product, error) {
+	r.single++
+	return r.ProductRepository.GetProduct(ctx, id)
+}
+
+func (r *countingProductRepository) GetProducts(ctx context.Context, ids []string) (map[string]models.Product, error) {
+	r.batch++
+	return r.ProductRepository.GetProducts(ctx, ids)
+}
+
+func setupSchema(t *testing.T, limits graphqlapi.Limits) (*graphqlapi.Schema, *order.Service, *countingProductRepository) {
+	t.Helper()
+	productRepo := &countingProductRepository{ProductRepository: repository.NewProductRepository("InMemory")}
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, productRepo)
+	schema, err := graphqlapi.NewSchema(orders, product.NewService(productRepo, vatRepo), limits)
+	require.NoError(t, err)
+	return schema, orders, productRepo
+}
+
+func TestGraphQL_ProductsWithVAT(t *testing.T) {
+	schema, _, _ := setupSchema(t, graphqlapi.Limits{MaxComplexity: 1000, MaxDepth: 8})
+
+	res, err := schema.Execute(context.Background(), graphqlapi.Request{
+		Query:     `query($c: String!) { product(id: "prod1", countryCode: $c) { id priceWithVat vat } }`,
+		Variables: map[string]interface{}{"c": "it"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Errors)
+
+	b, _ := json.Marshal(res.Data)
+	require.JSONEq(t, `{"product":{"id":"prod1","priceWithVat":12.2,"vat":0.22}}`, string(b))
+}
+
+// ordini, righe e prodotti in un solo round trip, con un solo accesso batch al catalogo per le righe
+func TestGraphQL_OrdersWithLines_BatchesProductLookups(t *testing.T) {
+	schema, orders, productRepo := setupSchema(t, graphqlapi.Limits{MaxComplexity: 1000, MaxDepth: 8})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{
+			{ProductID: "prod1", Quantity: 1},
+			{ProductID: "prod2", Quantity: 2},
+		})
+		require.NoError(t, err)
+	}
+	productRepo.single, productRepo.batch = 0, 0
+
+	res, err := schema.Execute(ctx, graphqlapi.Request{
+		Query: `{ orders { id totalPrice lines { productId quantity product(countryCode: "DE") { name priceWithVat } } } }`,
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Errors)
+
+	var data struct {
+		Orders []struct {
+			Lines []struct {
+				Product struct {
+					PriceWithVat float64 `json:"priceWithVat"`
+				} `json:"product"`
+			} `json:"lines"`
+		} `json:"orders"`
+	}
+	b, _ := json.Marshal(res.Data)
+	require.NoError(t, json.Unmarshal(b, &data))
+	require.Len(t, data.Orders, 3)
+	for _, o := range data.Orders {
+		require.Len(t, o.Lines, 2)
+	}
+
+	// una chiamata per il dettaglio ordini, una per i prodotti delle righe
+	require.Equal(t, 0, productRepo.single)
+	require.Equal(t, 2, productRepo.batch)
+}
+
+func TestGraphQL_ComplexityLimit(t *testing.T) {
+	schema, _, _ := setupSchema(t, graphqlapi.Limits{MaxComplexity: 50, MaxDepth: 8})
+
+	res, err := schema.Execute(context.Background(), graphqlapi.Request{
+		Query: `{ orders { id lines { productId product(countryCode: "IT") { id name } } } }`,
+	})
+	require.ErrorIs(t, err, graphqlapi.ErrLimitExceeded)
+	require.NotEmpty(t, res.Errors)
+	require.Contains(t, res.Errors[0].Message, "complexity")
+}
+
+func TestGraphQL_DepthLimit(t *testing.T) {
+	schema, _, _ := setupSchema(t, graphqlapi.Limits{MaxComplexity: 100000, MaxDepth: 2})
+
+	res, err := schema.Execute(context.Background(), graphqlapi.Request{
+		Query: `query { ...F } fragment F on Query { orders { lines { product(countryCode: "IT") { id } } } }`,
+	})
+	require.ErrorIs(t, err, graphqlapi.ErrLimitExceeded)
+	require.Contains(t, res.Errors[0].Message, "depth")
+}
+
+func TestGraphQL_InvalidQuery(t *testing.T) {
+	schema, _, _ := setupSchema(t, graphqlapi.Limits{MaxComplexity: 1000, MaxDepth: 8})
+
+	res, err := schema.Execute(context.Background(), graphqlapi.Request{Query: `{ unknownField }`})
+	require.NoError(t, err)
+	require.Nil(t, res.Data)
+	require.NotEmpty(t, res.Errors)
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	grpcapi "purchase-cart-service/internal/api/grpc"
+	"purchase-cart-service/internal/api/grpc/pb"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/vat"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupClient(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	orderRepo := repository.NewOrderRepository("InMemory")
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	productRepo := repository.NewProductRepository("InMemory")
+	srv := grpcapi.NewServer(
+		order.NewService(orderRepo, vatRepo, productRepo),
+		product.NewService(productRepo, vatRepo),
+		vat.NewService(vatRepo),
+		2,
+	)
+
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPC_CreateAndGetOrder(t *testing.T) {
+	client := pb.NewOrderServiceClient(setupClient(t))
+	ctx := context.Background()
+
+	created, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CountryCode: "it",
+		Items:       []*pb.CreateOrderItem{{ProductId: "prod1", Quantity: 2}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.OrderId)
+	require.Equal(t, 24.40, created.TotalPrice)
+	require.Equal(t, 4.40, created.TotalVat)
+
+	got, err := client.GetOrder(ctx, &pb.GetOrderRequest{Id: created.OrderId})
+	require.NoError(t, err)
+	require.Equal(t, created.OrderId, got.OrderId)
+	require.Len(t, got.Items, 1)
+
+	list, err := client.ListOrders(ctx, &pb.ListOrdersRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Orders, 1)
+}
+
+// errori di dominio → codici gRPC
+func TestGRPC_ErrorMapping(t *testing.T) {
+	conn := setupClient(t)
+	orders := pb.NewOrderServiceClient(conn)
+	rates := pb.NewVatServiceClient(conn)
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		call func() error
+		code codes.Code
+	}{
+		"paese non supportato": {func() error {
+			_, err := orders.CreateOrder(ctx, &pb.CreateOrderRequest{CountryCode: "XX", Items: []*pb.CreateOrderItem{{ProductId: "prod1", Quantity: 1}}})
+			return err
+		}, codes.InvalidArgument},
+		"prodotto inesistente": {func() error {
+			_, err := orders.CreateOrder(ctx, &pb.CreateOrderRequest{CountryCode: "IT", Items: []*pb.CreateOrderItem{{ProductId: "unknown", Quantity: 1}}})
+			return err
+		}, codes.NotFound},
+		"troppi items": {func() error {
+			item := &pb.CreateOrderItem{ProductId: "prod1", Quantity: 1}
+			_, err := orders.CreateOrder(ctx, &pb.CreateOrderRequest{CountryCode: "IT", Items: []*pb.CreateOrderItem{item, item, item}})
+			return err
+		}, codes.InvalidArgument},
+		"ordine inesistente": {func() error {
+			_, err := orders.GetOrder(ctx, &pb.GetOrderRequest{Id: "missing"})
+			return err
+		}, codes.NotFound},
+		"aliquota inesistente": {func() error {
+			_, err := rates.GetVatRate(ctx, &pb.GetVatRateRequest{CountryCode: "XX"})
+			return err
+		}, codes.NotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.call()
+			require.Error(t, err)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestGRPC_ProductsAndVat(t *testing.T) {
+	conn := setupClient(t)
+	ctx := context.Background()
+
+	p, err := pb.NewProductServiceClient(conn).GetProduct(ctx, &pb.GetProductRequest{Id: "prod1", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, 12.20, p.PriceWithVat)
+
+	rate, err := pb.NewVatServiceClient(conn).GetVatRate(ctx, &pb.GetVatRateRequest{CountryCode: "de"})
+	require.NoError(t, err)
+	require.Equal(t, 0.19, rate.Rate)
+}
+
+func TestGRPC_Health(t *testing.T) {
+	resp, err := healthpb.NewHealthClient(setupClient(t)).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "purchasecart.v1.OrderService"})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForBundles() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	products := repository.NewProductRepository("InMemory")
+	bundles := bundle.NewService(repository.NewBundleRepository("InMemory"), products)
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), products, order.WithBundles(bundles))
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewBundleHandler(bundles), handlers.NewOrderHandler(orders))
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewBundleAdminHandler(bundles))
+	return r.Engine()
+}
+
+func TestBundleHandler_List(t *testing.T) {
+	r := setupRouterForBundles()
+
+	w := doJSONRequest(r, http.MethodGet, "/api/v1/bundles", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var bundles []handlers.BundleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundles))
+	require.Len(t, bundles, 2)
+	require.Equal(t, "starter-kit", bundles[1].ID)
+	require.Equal(t, []handlers.BundleComponentRequest{{ProductID: "prod1", Quantity: 1}, {ProductID: "prod2", Quantity: 2}}, bundles[1].Components)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/bundles/missing", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBundleHandler_Save(t *testing.T) {
+	r := setupRouterForBundles()
+
+	w := doAdminRequest(r, http.MethodPut, "/api/v1/admin/bundles/duo", map[string]any{
+		"name":       "Duo",
+		"price":      25,
+		"components": []map[string]any{{"product_id": "prod1", "quantity": 1}, {"product_id": "prod3", "quantity": 1}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/bundles/duo", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var duo handlers.BundleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &duo))
+	require.Equal(t, 25.0, duo.Price)
+
+	w = doAdminRequest(r, http.MethodPut, "/api/v1/admin/bundles/broken", map[string]any{
+		"name":       "Broken",
+		"price":      25,
+		"components": []map[string]any{{"product_id": "tshirt", "quantity": 2}},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "a SKU is required")
+
+	w = doJSONRequest(r, http.MethodPut, "/api/v1/admin/bundles/duo", map[string]any{"name": "Duo"})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCreateOrderHandler_Bundle(t *testing.T) {
+	r := setupRouterForBundles()
+
+	w := doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items": []map[string]any{
+			{"product_id": "prod3", "quantity": 1},
+			{"bundle_id": "starter-kit", "quantity": 1},
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var o handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &o))
+	require.Len(t, o.Items, 3)
+	require.Zero(t, o.Items[0].BundleLine)
+	require.Equal(t, 2, o.Items[1].BundleLine)
+	require.Equal(t, 2, o.Items[2].BundleLine)
+	require.Equal(t, 18.0, o.Items[2].UnitPrice)
+	// il kit costa 45 più IVA 22%
+	require.Equal(t, []handlers.OrderBundleReply{{Line: 2, BundleID: "starter-kit", Name: "Starter kit", Quantity: 1, TotalPrice: 54.9}}, o.Bundles)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+o.OrderID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	require.Equal(t, o.Bundles, stored.Bundles)
+
+	w = doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"bundle_id": "missing", "quantity": 1}},
+	})
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), "Bundle not found")
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForCategories() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	categories := category.NewService(repository.NewCategoryRepository("InMemory"))
+	products := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"), product.WithCategories(categories))
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewCategoryHandler(categories, products))
+	return r.Engine()
+}
+
+func TestGetCategoriesHandler_OK(t *testing.T) {
+	r := setupRouterForCategories()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var tree []handlers.CategoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	require.Len(t, tree, 5)
+	require.Equal(t, "apparel", tree[0].Slug)
+	require.Equal(t, []handlers.CategoryResponse{{ID: "cat-tshirts", Slug: "t-shirts", Name: "T-Shirts", Position: 1}}, tree[0].Children)
+	require.Equal(t, "super-reduced", tree[2].TaxClass)
+}
+
+func TestGetCategoryProductsHandler(t *testing.T) {
+	r := setupRouterForCategories()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories/food/products?country_code=it", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var products []handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &products))
+	require.Len(t, products, 1)
+	require.Equal(t, "olive-oil", products[0].ID)
+	require.Equal(t, "reduced", products[0].TaxClass)
+	require.Equal(t, 0.10, products[0].VAT)
+
+	// gifts è una categoria secondaria dei suoi prodotti
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories/gifts/products?country_code=IT", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &products))
+	require.Len(t, products, 2)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories/garden/products", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	graphqlapi "purchase-cart-service/internal/api/graphql"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForGraphQL(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	productRepo := repository.NewProductRepository("InMemory")
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	schema, err := graphqlapi.NewSchema(
+		order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, productRepo),
+		product.NewService(productRepo, vatRepo),
+		graphqlapi.Limits{MaxComplexity: 20, MaxDepth: 4},
+	)
+	require.NoError(t, err)
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/", handlers.NewGraphQLHandler(schema))
+	return r.Engine()
+}
+
+func TestGraphQLHandler_Post_OK(t *testing.T) {
+	r := setupRouterForGraphQL(t)
+
+	b, _ := json.Marshal(map[string]any{"query": `{ product(id: "prod1", countryCode: "IT") { name } }`})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `{"data":{"product":{"name":"Product 1"}}}`, w.Body.String())
+}
+
+func TestGraphQLHandler_Get_OK(t *testing.T) {
+	r := setupRouterForGraphQL(t)
+
+	q := url.Values{"query": {`{ orders { id } }`}}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+q.Encode(), nil))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `{"data":{"orders":[]}}`, w.Body.String())
+}
+
+// query troppo complessa → 400
+func TestGraphQLHandler_ComplexityExceeded(t *testing.T) {
+	r := setupRouterForGraphQL(t)
+
+	b, _ := json.Marshal(map[string]any{"query": `{ products(countryCode: "IT") { id name description price vat priceWithVat } }`})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
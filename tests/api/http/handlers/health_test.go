@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/health"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForHealth(checker *health.Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/", handlers.NewHealthCheckHandler(checker))
+	return r.Engine()
+}
+
+func TestLivez_OK(t *testing.T) {
+	r := setupRouterForHealth(health.NewChecker())
+
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyz_AllChecksPass(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Register("repository", func(ctx context.Context) error { return nil })
+	r := setupRouterForHealth(checker)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, health.StatusOK, report.Checks["repository"].Status)
+}
+
+// un controllo fallito → 503 con il dettaglio dell'errore
+func TestReadyz_FailingCheck(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Register("repository", func(ctx context.Context) error { return nil })
+	checker.Register("catalog", func(ctx context.Context) error { return errors.New("product catalog is empty") })
+	r := setupRouterForHealth(checker)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, health.StatusUnavailable, report.Status)
+	require.Equal(t, "product catalog is empty", report.Checks["catalog"].Error)
+	require.Equal(t, health.StatusOK, report.Checks["repository"].Status)
+}
+
+// durante lo shutdown la readiness passa a 503, la liveness resta 200
+func TestReadyz_ShuttingDown(t *testing.T) {
+	checker := health.NewChecker()
+	r := setupRouterForHealth(checker)
+	checker.SetShuttingDown()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
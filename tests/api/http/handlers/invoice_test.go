@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/domain/invoice"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRouterForInvoices() (*gin.Engine, *order.Service, *invoice.Service) {
+	gin.SetMode(gin.TestMode)
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+	invoices := invoice.NewService(repository.NewInvoiceRepository("InMemory"), orders, invoice.Numbering{Digits: 6}, invoice.Seller{Name: "Purchase Cart"}, "EUR")
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewOrderHandler(orders), handlers.NewInvoiceHandler(invoices))
+	return r.Engine(), orders, invoices
+}
+
+func TestGetInvoiceHandler(t *testing.T) {
+	r, orders, invoices := setupRouterForInvoices()
+	orderID := createOrderForTest(t, r)
+
+	w := doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/invoice", nil)
+	require.Equal(t, http.StatusNotFound, w.Code, "ordine non ancora pagato")
+
+	_, err := orders.MarkPaid(context.Background(), orderID)
+	require.NoError(t, err)
+	_, err = invoices.Issue(context.Background(), orderID, time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/invoice", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp handlers.InvoiceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "IT-2026-000001", resp.Number)
+	require.Equal(t, 2026, resp.FiscalYear)
+	require.Equal(t, 24.40, resp.Total)
+	require.Len(t, resp.Lines, 1)
+}
+
+// l'header Accept sceglie tra JSON, HTML e PDF
+func TestGetInvoiceHandler_ContentNegotiation(t *testing.T) {
+	r, orders, invoices := setupRouterForInvoices()
+	w := doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "it",
+		"items":        []map[string]any{{"product_id": "prod1", "quantity": 1}},
+		"billing_address": map[string]any{
+			"name": "Mario Rossi", "line1": "Via Po 3", "city": "Torino", "postal_code": "10123",
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		OrderID string `json:"order_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	_, err := orders.MarkPaid(context.Background(), created.OrderID)
+	require.NoError(t, err)
+	_, err = invoices.Issue(context.Background(), created.OrderID, time.Now())
+	require.NoError(t, err)
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+created.OrderID+"/invoice", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w = get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	w = get("text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "Mario Rossi")
+	require.Contains(t, w.Body.String(), "10123 Torino")
+	require.Contains(t, w.Body.String(), "Purchase Cart")
+
+	w = get("application/pdf")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Header().Get("Content-Disposition"), "invoice-IT-")
+	require.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+
+	w = get("image/png")
+	require.Equal(t, http.StatusNotAcceptable, w.Code)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/require"
@@ -9,7 +10,11 @@ import (
 	"net/http/httptest"
 	httpapi "purchase-cart-service/internal/api/http"
 	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
 	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/models"
 	"purchase-cart-service/repository"
 	"testing"
 
@@ -18,9 +23,10 @@ import (
 
 func setupRouterForOrders() *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	h := handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory")))
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
 	r := httpapi.NewRouter()
-	r.RegisterMethods("/api/v1", h)
+	r.RegisterMethods("/api/v1", handlers.NewOrderHandler(svc))
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewOrderAdminHandler(svc))
 	return r.Engine()
 }
 
@@ -322,3 +328,286 @@ func TestGetOrderByID_NotFound(t *testing.T) {
 		t.Fatalf("status code errato, got=%d want=%d body=%s", w.Code, http.StatusNotFound, w.Body.String())
 	}
 }
+
+// numero di items oltre il limite configurato → 413
+func TestCreateOrderHandler_TooManyItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory")), handlers.WithMaxOrderItems(2))
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", h)
+	r := router.Engine()
+
+	items := []map[string]any{}
+	for i := 0; i < 3; i++ {
+		items = append(items, map[string]any{"product_id": "prod1", "quantity": 1})
+	}
+	b, _ := json.Marshal(map[string]any{"country_code": "IT", "items": items})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status code errato, got=%d want=%d body=%s", w.Code, http.StatusRequestEntityTooLarge, w.Body.String())
+	}
+}
+
+func TestCancelOrderHandler_Transitions(t *testing.T) {
+	r := setupRouterForOrders()
+	id := createOrderForTest(t, r)
+
+	// un ordine non ancora pagato non può essere spedito
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+id+"/ship", nil)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+id+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "cancelled", resp.Status)
+}
+
+func TestShipOrderHandler_NotFound(t *testing.T) {
+	r := setupRouterForOrders()
+
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/missing/ship", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// annullamento e spedizione sono esposti solo nell'API admin
+func TestOrderTransitions_RequireAPIKey(t *testing.T) {
+	r := setupRouterForOrders()
+	id := createOrderForTest(t, r)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/orders/"+id+"/cancel", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/orders/"+id+"/cancel", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetOrderHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orderRepo := repository.NewOrderRepository("InMemory", repository.WithEventSourcing(0))
+	svc := order.NewService(orderRepo, repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(svc))
+	router.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewOrderAdminHandler(svc))
+	r := router.Engine()
+	id := createOrderForTest(t, r)
+
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+id+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+id+"/history", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var history []handlers.OrderHistoryEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history, 2)
+	require.Equal(t, "order.created", history[0].Type)
+	require.Equal(t, 1, history[0].Version)
+	require.Equal(t, "order.status_changed", history[1].Type)
+	require.JSONEq(t, `{"from":"created","to":"cancelled"}`, string(history[1].Data))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/missing/history", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetOrderHistoryHandler_NotEventSourced(t *testing.T) {
+	r := setupRouterForOrders()
+	id := createOrderForTest(t, r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+id+"/history", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestCreateOrderHandler_ReverseCharge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"),
+		order.WithReverseCharge(vatid.NewStubVerifier("DE136695976"), "IT"))
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(svc))
+	r := router.Engine()
+
+	newOrder := func(country, vatID string) *httptest.ResponseRecorder {
+		return doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{
+			"country_code": country,
+			"buyer_vat_id": vatID,
+			"items":        []map[string]any{{"product_id": "prod1", "quantity": 2}},
+		})
+	}
+
+	w := newOrder("DE", "DE 136 695 976")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.ReverseCharge)
+	require.Equal(t, "DE136695976", resp.BuyerVATID)
+	require.Zero(t, resp.TotalVAT)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+resp.OrderID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"reverse_charge":true`)
+
+	require.Equal(t, http.StatusBadRequest, newOrder("DE", "DE136695977").Code, "cifra di controllo errata")
+	require.Equal(t, http.StatusUnprocessableEntity, newOrder("FR", "FR40303265045").Code, "numero non registrato")
+}
+
+func TestCreateOrderHandler_USSalesTax(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	salesTax := repository.NewSalesTaxRepository("InMemory")
+	require.NoError(t, salesTax.ReplaceAll(context.Background(), []models.SalesTaxRegion{
+		{State: "WA", PostalCode: "98101", StateRate: 0.065, City: "Seattle", CityRate: 0.0215, Special: "Regional Transit Authority", SpecialRate: 0.017},
+	}))
+	taxes := tax.NewService(vatRepo, tax.WithSalesTax(salesTax, models.Destination{CountryCode: "US", Region: "TX", PostalCode: "73301"}))
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, repository.NewProductRepository("InMemory"), order.WithTax(taxes))))
+	r := router.Engine()
+
+	newOrder := func(shipping map[string]any) *httptest.ResponseRecorder {
+		return doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{
+			"country_code":     "us",
+			"shipping_address": shipping,
+			"items":            []map[string]any{{"product_id": "prod1", "quantity": 2}},
+		})
+	}
+
+	w := newOrder(map[string]any{"name": "Jane Doe", "line1": "400 Pine St", "city": "Seattle", "region": "WA", "postal_code": "98101"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.InDelta(t, 2.07, resp.TotalVAT, 0.0001)
+	require.Equal(t, []handlers.LineTaxReply{
+		{Type: "state", Name: "WA", Rate: 0.065, Amount: 1.3},
+		{Type: "city", Name: "Seattle", Rate: 0.0215, Amount: 0.43},
+		{Type: "special", Name: "Regional Transit Authority", Rate: 0.017, Amount: 0.34},
+	}, resp.Items[0].Taxes)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+resp.OrderID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"name":"Seattle"`)
+
+	require.Equal(t, http.StatusBadRequest, newOrder(nil).Code, "stato e ZIP obbligatori")
+	require.Equal(t, http.StatusBadRequest, newOrder(map[string]any{"region": "WA", "postal_code": "98000"}).Code, "ZIP sconosciuto")
+	require.Equal(t, http.StatusBadRequest, newOrder(map[string]any{"region": "ON", "postal_code": "M5V", "country_code": "CA"}).Code, "paese diverso dall'ordine")
+}
+
+func TestCreateOrderHandler_VATTerritory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	require.NoError(t, vatRepo.ReplaceTerritories([]models.VATTerritory{{CountryCode: "IT", PostalCodePrefix: "23041", Name: "Livigno"}}))
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, repository.NewProductRepository("InMemory"))))
+
+	w := doJSONRequest(router.Engine(), http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code":     "IT",
+		"shipping_address": map[string]any{"line1": "Via Saroch 1", "city": "Livigno", "postal_code": "23041"},
+		"items":            []map[string]any{{"product_id": "prod1", "quantity": 2}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "Livigno", resp.TaxTerritory)
+	require.Zero(t, resp.TotalVAT)
+}
+
+func TestCreateOrderHandler_GrossPrices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"),
+		repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"), order.WithPriceMode(models.PriceModeGross))))
+
+	w := doJSONRequest(router.Engine(), http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"product_id": "prod1", "quantity": 2}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 20.0, resp.TotalPrice)
+	require.Equal(t, 3.61, resp.TotalVAT)
+	require.Equal(t, 16.39, resp.TotalNet)
+	require.Equal(t, "gross", resp.Items[0].PriceMode)
+	require.Equal(t, 8.2, resp.Items[0].UnitPrice)
+	require.Equal(t, 10.0, resp.Items[0].UnitPriceWithVAT)
+
+	w = doJSONRequest(router.Engine(), http.MethodGet, "/api/v1/orders/"+resp.OrderID, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Equal(t, resp.TotalNet, got.TotalNet)
+	require.Equal(t, resp.Items[0].UnitPriceWithVAT, got.Items[0].UnitPriceWithVAT)
+}
+
+func TestCreateOrderHandler_SKU(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := httpapi.NewRouter()
+	router.RegisterMethods("/api/v1", handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"),
+		repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))))
+
+	w := doJSONRequest(router.Engine(), http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"product_id": "tshirt", "sku": "tshirt-l-white", "quantity": 2}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "tshirt-l-white", resp.Items[0].SKU)
+	require.Equal(t, "T-Shirt (L, white)", resp.Items[0].Name)
+
+	w = doJSONRequest(router.Engine(), http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"product_id": "tshirt", "quantity": 1}},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// restano 3 unità
+	w = doJSONRequest(router.Engine(), http.MethodPut, "/api/v1/orders", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"sku": "tshirt-l-white", "quantity": 4}},
+	})
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestQuoteOrderHandler(t *testing.T) {
+	r := setupRouterForOrders()
+
+	// 10 unità di prod2 raggiungono lo scaglione a 18
+	w := doJSONRequest(r, http.MethodPost, "/api/v1/orders/quote", map[string]any{
+		"country_code": "it",
+		"items":        []map[string]any{{"product_id": "prod2", "quantity": 10}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var quote handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &quote))
+	require.Empty(t, quote.OrderID)
+	require.Empty(t, quote.Status)
+	require.Equal(t, 18.0, quote.Items[0].UnitPrice)
+	require.Equal(t, 219.6, quote.TotalPrice)
+
+	// il preventivo non crea ordini
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Empty(t, list)
+
+	w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/quote", map[string]any{
+		"country_code": "IT",
+		"items":        []map[string]any{{"product_id": "missing", "quantity": 1}},
+	})
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
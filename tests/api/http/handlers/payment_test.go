@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/payment"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRouterForPayments() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+	payments := payment.NewService(repository.NewPaymentRepository("InMemory"), repository.NewCreditNoteRepository("InMemory"), orders, payment.NewFakeGateway(), "EUR")
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewOrderHandler(orders), handlers.NewPaymentHandler(payments, true))
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewPaymentAdminHandler(payments))
+	return r.Engine()
+}
+
+func doJSONRequest(r *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	var b []byte
+	if body != nil {
+		b, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodePayment(t *testing.T, w *httptest.ResponseRecorder) handlers.PaymentResponse {
+	t.Helper()
+	var resp handlers.PaymentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return resp
+}
+
+// pagamento con cattura automatica → 201 e ordine in stato paid
+func TestStartPaymentHandler_Captured(t *testing.T) {
+	r := setupRouterForPayments()
+	orderID := createOrderForTest(t, r)
+
+	w := doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": "tok_visa"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	resp := decodePayment(t, w)
+	require.Equal(t, "captured", resp.Status)
+	require.Equal(t, orderID, resp.OrderID)
+	require.Equal(t, 24.40, resp.CapturedAmount)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID, nil)
+	require.Contains(t, w.Body.String(), `"status":"paid"`)
+
+	// ordine già pagato → 409
+	w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": "tok_visa"})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/payments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []handlers.PaymentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/payments/"+resp.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+// metodo di pagamento rifiutato → 402 con il payment intent fallito
+func TestStartPaymentHandler_Declined(t *testing.T) {
+	r := setupRouterForPayments()
+	orderID := createOrderForTest(t, r)
+
+	w := doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": payment.TokenDeclined})
+	require.Equal(t, http.StatusPaymentRequired, w.Code, w.Body.String())
+	resp := decodePayment(t, w)
+	require.Equal(t, "failed", resp.Status)
+	require.NotEmpty(t, resp.FailureReason)
+}
+
+// autorizzazione senza cattura, poi cattura e annullo espliciti
+func TestPaymentHandler_CaptureAndVoid(t *testing.T) {
+	r := setupRouterForPayments()
+
+	orderID := createOrderForTest(t, r)
+	w := doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": "tok_visa", "capture": false})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	resp := decodePayment(t, w)
+	require.Equal(t, "authorized", resp.Status)
+	// cattura, annullo e rimborso richiedono la chiave dell'API admin
+	for _, path := range []string{"/payments/" + resp.ID + "/capture", "/payments/" + resp.ID + "/void", "/refunds"} {
+		w = doJSONRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+path, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code, path)
+		w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+path, nil)
+		require.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/payments/"+resp.ID+"/capture", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "captured", decodePayment(t, w).Status)
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/payments/"+resp.ID+"/void", nil)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	orderID = createOrderForTest(t, r)
+	w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": "tok_visa", "capture": false})
+	resp = decodePayment(t, w)
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/payments/"+resp.ID+"/void", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "voided", decodePayment(t, w).Status)
+}
+
+func TestPaymentHandler_Errors(t *testing.T) {
+	r := setupRouterForPayments()
+
+	w := doJSONRequest(r, http.MethodPost, "/api/v1/orders/missing/payments", map[string]any{"payment_method": "tok_visa"})
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	orderID := createOrderForTest(t, r)
+	w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/payments/missing", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// rimborso parziale → nota di credito numerata, consultabile dall'ordine
+func TestRefundHandler(t *testing.T) {
+	r := setupRouterForPayments()
+	orderID := createOrderForTest(t, r)
+
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/refunds", map[string]any{"lines": []map[string]any{{"line": 1, "quantity": 1}}})
+	require.Equal(t, http.StatusConflict, w.Code, "ordine non pagato")
+
+	w = doJSONRequest(r, http.MethodPost, "/api/v1/orders/"+orderID+"/payments", map[string]any{"payment_method": "tok_visa"})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/refunds", map[string]any{
+		"lines":  []map[string]any{{"line": 1, "quantity": 1}},
+		"reason": "reso",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var note handlers.CreditNoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	require.Equal(t, "CN-000001", note.Number)
+	require.Equal(t, orderID, note.OrderID)
+	require.Equal(t, 12.20, note.Total)
+	require.Equal(t, 2.20, note.TotalVAT)
+	require.Equal(t, 0.22, note.Lines[0].VATRate)
+	require.Equal(t, 1, note.Lines[0].Line)
+
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/refunds", map[string]any{"lines": []map[string]any{{"line": 1, "quantity": 2}}})
+	require.Equal(t, http.StatusConflict, w.Code)
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/orders/"+orderID+"/refunds", map[string]any{"lines": []map[string]any{{"line": 9, "quantity": 1}}})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/credit-notes", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var notes []handlers.CreditNoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notes))
+	require.Len(t, notes, 1)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/credit-notes/CN-000001", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/credit-notes/CN-000002", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/orders/"+orderID+"/payments", nil)
+	require.Contains(t, w.Body.String(), `"refunded_amount":12.2`)
+}
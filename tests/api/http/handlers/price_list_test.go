@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/repository"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientKey = "retail-key"
+
+func setupRouterForPriceLists() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	products := repository.NewProductRepository("InMemory")
+	vat := repository.NewVatRateRepository("InMemory")
+	prices := pricing.NewService(repository.NewPriceListRepository("InMemory"), products)
+	r := httpapi.NewRouter()
+	r.Use(middleware.Buyer(map[string]pricing.Buyer{testClientKey: {Client: "retail-1"}}))
+	r.RegisterMethods("/api/v1",
+		handlers.NewProductHandler(product.NewService(products, vat, product.WithPricing(prices))),
+		handlers.NewOrderHandler(order.NewService(repository.NewOrderRepository("InMemory"), vat, products, order.WithPricing(prices))),
+	)
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewPriceListHandler(prices))
+	return r.Engine()
+}
+
+func TestPriceListHandler_AssignToClient(t *testing.T) {
+	r := setupRouterForPriceLists()
+
+	w := doAdminRequest(r, http.MethodPut, "/api/v1/admin/price-lists/retail", map[string]any{
+		"name":    "Retail partners",
+		"clients": []string{"retail-1"},
+		"prices": []map[string]any{
+			{"product_id": "prod2", "price": 18, "valid_from": "2020-01-01T00:00:00Z"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doAdminRequest(r, http.MethodGet, "/api/v1/admin/price-lists", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var lists []handlers.PriceListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &lists))
+	require.Len(t, lists, 3)
+	require.Equal(t, "retail", lists[1].ID)
+	require.Equal(t, 18.0, lists[1].Prices[0].Price)
+
+	// il client riconosciuto dalla chiave vede e paga il prezzo del suo listino
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod2?country_code=IT", nil)
+	req.Header.Set(middleware.APIKeyHeader, testClientKey)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var p handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	require.Equal(t, 18.0, p.Price)
+	require.Equal(t, "retail", p.PriceList)
+
+	body := `{"country_code": "IT", "items": [{"product_id": "prod2", "quantity": 1}]}`
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.APIKeyHeader, testClientKey)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var o handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &o))
+	require.Equal(t, 18.0, o.Items[0].UnitPrice)
+	require.Equal(t, "retail", o.Items[0].PriceList)
+
+	// senza chiave resta il prezzo di catalogo
+	w = doJSONRequest(r, http.MethodPut, "/api/v1/orders", map[string]any{"country_code": "IT", "items": []map[string]any{{"product_id": "prod2", "quantity": 1}}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var catalog handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &catalog))
+	require.Equal(t, 20.0, catalog.Items[0].UnitPrice)
+	require.Empty(t, catalog.Items[0].PriceList)
+}
+
+func TestPriceListHandler_Tiers(t *testing.T) {
+	r := setupRouterForPriceLists()
+
+	w := doAdminRequest(r, http.MethodPut, "/api/v1/admin/price-lists/retail", map[string]any{
+		"name":    "Retail partners",
+		"clients": []string{"retail-1"},
+		"prices": []map[string]any{
+			{"product_id": "prod1", "price": 9, "tiers": []map[string]any{{"min_quantity": 5, "price": 8}, {"min_quantity": 20, "price": 7}}},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list handlers.PriceListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, []handlers.ListTierRequest{{MinQuantity: 5, Price: 8}, {MinQuantity: 20, Price: 7}}, list.Prices[0].Tiers)
+
+	// la scheda prodotto mostra la tabella degli scaglioni del listino
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod1?country_code=IT", nil)
+	req.Header.Set(middleware.APIKeyHeader, testClientKey)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var p handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	require.Equal(t, []handlers.PriceTierResponse{
+		{MinQuantity: 5, Price: 8, PriceWithVAT: 9.76},
+		{MinQuantity: 20, Price: 7, PriceWithVAT: 8.54},
+	}, p.Tiers)
+
+	// il preventivo applica lo scaglione raggiunto dalla quantità
+	body := `{"country_code": "IT", "items": [{"product_id": "prod1", "quantity": 20}]}`
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/orders/quote", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.APIKeyHeader, testClientKey)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var quote handlers.OrderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &quote))
+	require.Equal(t, 7.0, quote.Items[0].UnitPrice)
+	require.Equal(t, "retail", quote.Items[0].PriceList)
+
+	w = doAdminRequest(r, http.MethodPut, "/api/v1/admin/price-lists/retail", map[string]any{
+		"name":   "Retail partners",
+		"prices": []map[string]any{{"product_id": "prod1", "price": 9, "tiers": []map[string]any{{"min_quantity": 1, "price": 8}}}},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "tier 0")
+}
+
+func TestPriceListHandler_Invalid(t *testing.T) {
+	r := setupRouterForPriceLists()
+
+	w := doAdminRequest(r, http.MethodPut, "/api/v1/admin/price-lists/broken", map[string]any{
+		"name":   "Broken",
+		"prices": []map[string]any{{"product_id": "missing", "price": 5}},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "unknown product")
+
+	w = doJSONRequest(r, http.MethodGet, "/api/v1/admin/price-lists", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	httpapi "purchase-cart-service/internal/api/http"
 	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
 	"purchase-cart-service/internal/domain/product"
 	"purchase-cart-service/repository"
 	"testing"
@@ -59,3 +62,78 @@ func TestGetProductHandler_NotFound(t *testing.T) {
 		t.Fatalf("status code errato, got=%d want=%d body=%s", w.Code, http.StatusNotFound, w.Body.String())
 	}
 }
+
+func TestUpdateProductPriceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"))
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewProductHandler(svc))
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewProductAdminHandler(svc))
+	engine := r.Engine()
+
+	w := doAdminRequest(engine, http.MethodPut, "/api/v1/admin/products/prod1/price", map[string]any{"price": 12.5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `{"id":"prod1","price":12.5}`, w.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod1?country_code=IT", nil)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 12.5, resp.Price)
+
+	w = doAdminRequest(engine, http.MethodPut, "/api/v1/admin/products/prod1/price", map[string]any{"price": 0})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAdminRequest(engine, http.MethodPut, "/api/v1/admin/products/missing/price", map[string]any{"price": 3})
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateProductPriceHandler_PriceMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"))
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewProductHandler(svc))
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewProductAdminHandler(svc))
+	engine := r.Engine()
+
+	w := doAdminRequest(engine, http.MethodPut, "/api/v1/admin/products/prod1/price", map[string]any{"price": 12.2, "price_mode": "gross"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `{"id":"prod1","price":12.2,"price_mode":"gross"}`, w.Body.String())
+
+	// il prezzo netto è estratto dal prezzo IVA inclusa
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/prod1?country_code=IT", nil)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, handlers.ProductResponse{
+		ID: "prod1", Name: "Product 1", Description: "Description of Product 1",
+		Price: 10, VAT: 0.22, PriceWithVAT: 12.2, PriceMode: "gross", TaxClass: "standard", CategoryIDs: []string{"cat-electronics"},
+	}, resp)
+
+	w = doAdminRequest(engine, http.MethodPut, "/api/v1/admin/products/prod1/price", map[string]any{"price": 12.2, "price_mode": "list"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetProductHandler_Variants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := httpapi.NewRouter()
+	r.RegisterMethods("/api/v1", handlers.NewProductHandler(product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"))))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/tshirt?country_code=IT", nil)
+	w := httptest.NewRecorder()
+	r.Engine().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp handlers.ProductResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []handlers.VariantOptionResponse{
+		{Name: "size", Values: []string{"S", "M", "L"}},
+		{Name: "color", Values: []string{"white", "black"}},
+	}, resp.Options)
+	require.Len(t, resp.Variants, 5)
+	require.Equal(t, handlers.VariantResponse{
+		SKU: "tshirt-m-black", Attributes: map[string]string{"size": "M", "color": "black"}, Price: 17, PriceWithVAT: 20.74, Stock: 0,
+	}, resp.Variants[4])
+}
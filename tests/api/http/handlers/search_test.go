@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/search"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForSearch() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	products := repository.NewProductRepository("InMemory")
+	categories := category.NewService(repository.NewCategoryRepository("InMemory"))
+	productSvc := product.NewService(products, repository.NewVatRateRepository("InMemory"), product.WithCategories(categories))
+	r := httpapi.NewRouter()
+	// la ricerca convive con /products/:id
+	r.RegisterMethods("/api/v1", handlers.NewProductHandler(productSvc), handlers.NewSearchHandler(search.NewService(products, productSvc, categories)))
+	return r.Engine()
+}
+
+func TestSearchProductsHandler_OK(t *testing.T) {
+	r := setupRouterForSearch()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/search?q=shirts&country_code=it&category=apparel&max_price=100", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res handlers.SearchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, "shirts", res.Query)
+	require.Equal(t, 1, res.Total)
+	require.Equal(t, "tshirt", res.Results[0].ID)
+	require.Equal(t, 18.3, res.Results[0].PriceWithVAT)
+	require.Positive(t, res.Results[0].Score)
+	to := 25.0
+	require.Equal(t, []handlers.PriceFacetResponse{{From: 10, To: &to, Count: 1}}, res.Facets.Prices)
+	require.Len(t, res.Facets.Categories, 3)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/prod1?country_code=IT", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSearchProductsHandler_BadRequest(t *testing.T) {
+	r := setupRouterForSearch()
+
+	for _, query := range []string{
+		"",
+		"q=product",
+		"q=product&country_code=IT&category=garden",
+		"q=product&country_code=IT&min_price=abc",
+		"q=product&country_code=IT&max_price=-1",
+		"q=product&country_code=IT&limit=0",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/search?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
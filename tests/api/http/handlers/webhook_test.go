@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	httpapi "purchase-cart-service/internal/api/http"
+	"purchase-cart-service/internal/api/http/handlers"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/domain/webhook"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testAdminKey = "admin-secret"
+
+func setupRouterForWebhooks(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	deadLetters := repository.NewWebhookDeadLetterRepository("InMemory")
+	dispatcher := webhook.NewDispatcher(webhook.DispatcherConfig{
+		Workers:        1,
+		QueueSize:      10,
+		MaxAttempts:    1,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+	}, deadLetters, nil)
+	t.Cleanup(func() { _ = dispatcher.Close(context.Background()) })
+	svc := webhook.NewService(repository.NewWebhookRepository("InMemory"), deadLetters, dispatcher)
+	r := httpapi.NewRouter()
+	r.RegisterProtectedMethods("/api/v1/admin", middleware.RequireAPIKey(testAdminKey), handlers.NewWebhookHandler(svc))
+	return r.Engine()
+}
+
+func doAdminRequest(r *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	var b []byte
+	if body != nil {
+		b, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.APIKeyHeader, testAdminKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookHandler_RequiresAPIKey(t *testing.T) {
+	r := setupRouterForWebhooks(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/webhooks", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/webhooks", nil)
+	req.Header.Set(middleware.APIKeyHeader, "wrong")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWebhookHandler_CRUD(t *testing.T) {
+	r := setupRouterForWebhooks(t)
+
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/webhooks", map[string]any{
+		"url":    "https://fulfilment.example.com/hooks",
+		"events": []string{"order.created", "order.shipped"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created handlers.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.ID)
+	require.NotEmpty(t, created.Secret, "il secret generato deve essere restituito alla creazione")
+	require.True(t, created.Active)
+
+	w = doAdminRequest(r, http.MethodGet, "/api/v1/admin/webhooks/"+created.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var got handlers.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Empty(t, got.Secret, "il secret non deve essere esposto in lettura")
+	require.Equal(t, created.URL, got.URL)
+
+	w = doAdminRequest(r, http.MethodPut, "/api/v1/admin/webhooks/"+created.ID, map[string]any{
+		"url":    "https://fulfilment.example.com/v2/hooks",
+		"events": []string{"order.cancelled"},
+		"active": false,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Equal(t, []string{"order.cancelled"}, got.Events)
+	require.False(t, got.Active)
+
+	w = doAdminRequest(r, http.MethodGet, "/api/v1/admin/webhooks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []handlers.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+
+	w = doAdminRequest(r, http.MethodDelete, "/api/v1/admin/webhooks/"+created.ID, nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = doAdminRequest(r, http.MethodGet, "/api/v1/admin/webhooks/"+created.ID, nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhookHandler_InvalidInput(t *testing.T) {
+	r := setupRouterForWebhooks(t)
+
+	w := doAdminRequest(r, http.MethodPost, "/api/v1/admin/webhooks", map[string]any{
+		"url":    "ftp://example.com",
+		"events": []string{"order.created"},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/webhooks", map[string]any{
+		"url":    "https://example.com",
+		"events": []string{"order.unknown"},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandler_DeadLetters(t *testing.T) {
+	r := setupRouterForWebhooks(t)
+
+	w := doAdminRequest(r, http.MethodGet, "/api/v1/admin/webhooks/dead-letters", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, "[]", w.Body.String())
+
+	w = doAdminRequest(r, http.MethodPost, "/api/v1/admin/webhooks/dead-letters/missing/redeliver", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
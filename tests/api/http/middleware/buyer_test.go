@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/domain/pricing"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuyer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Buyer(map[string]pricing.Buyer{"acme-key": {Client: "acme", Group: "wholesale"}}))
+	r.GET("/buyer", func(c *gin.Context) {
+		buyer, ok := pricing.FromContext(c.Request.Context())
+		if !ok {
+			c.String(http.StatusOK, "catalog")
+			return
+		}
+		c.String(http.StatusOK, buyer.Client+"/"+buyer.Group)
+	})
+
+	for key, want := range map[string]string{"acme-key": "acme/wholesale", "other-key": "catalog", "": "catalog"} {
+		req := httptest.NewRequest(http.MethodGet, "/buyer", nil)
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, want, w.Body.String(), key)
+	}
+}
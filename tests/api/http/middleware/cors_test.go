@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func corsConfig() config.CORS {
+	cfg := config.Default().CORS
+	cfg.Enabled = true
+	cfg.AllowedOrigins = []string{"https://shop.example.com"}
+	return cfg
+}
+
+// preflight da origine consentita → 204 con gli header Access-Control-*
+func TestCORS_Preflight(t *testing.T) {
+	r := setupRouter(middleware.CORS(corsConfig()))
+
+	req := httptest.NewRequest(http.MethodOptions, "/orders", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PUT")
+	require.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
+	require.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_SimpleRequest(t *testing.T) {
+	r := setupRouter(middleware.CORS(corsConfig()))
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "RateLimit-Remaining")
+	require.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+// origine non consentita → nessun header CORS
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	r := setupRouter(middleware.CORS(corsConfig()))
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	r := setupRouter(middleware.SecurityHeaders(config.Default().Security))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+	require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	require.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	// HSTS solo su HTTPS
+	require.Empty(t, w.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.TLS = &tls.ConnectionState{}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"purchase-cart-service/internal/api/http/middleware"
+	"purchase-cart-service/internal/config"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouter(mw ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(mw...)
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	r.GET("/products", ok)
+	r.PUT("/orders", func(c *gin.Context) {
+		if _, err := c.GetRawData(); err != nil {
+			c.String(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func rateLimitConfig() config.RateLimit {
+	return config.RateLimit{
+		Enabled:           true,
+		RequestsPerSecond: 0.001,
+		Burst:             3,
+		Routes: []config.RouteRateLimit{
+			{Method: "PUT", Route: "/orders", RequestsPerSecond: 0.001, Burst: 1},
+		},
+	}
+}
+
+// API key note al limiter, associate all'ID del client
+var testClients = map[string]string{"key-a": "client-a", "key-b": "client-b"}
+
+// superato il burst → 429 con problem+json e header RateLimit-*
+func TestRateLimit_ExceedBurst(t *testing.T) {
+	r := setupRouter(middleware.RateLimit(rateLimitConfig(), testClients))
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Contains(t, w.Body.String(), `"status":429`)
+}
+
+// budget specifico per rotta e bucket separati per API key
+func TestRateLimit_RouteBudgetAndClientKeys(t *testing.T) {
+	r := setupRouter(middleware.RateLimit(rateLimitConfig(), testClients))
+
+	put := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodPut, "/orders", strings.NewReader("{}"))
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, put("key-a"))
+	require.Equal(t, http.StatusTooManyRequests, put("key-a"))
+	require.Equal(t, http.StatusOK, put("key-b"))
+
+	// il budget di default non è consumato dalle chiamate a PUT /orders
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set(middleware.APIKeyHeader, "key-a")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+// una API key sconosciuta non dà un bucket nuovo: conta l'IP del client
+func TestRateLimit_UnknownKeysShareIPBucket(t *testing.T) {
+	r := setupRouter(middleware.RateLimit(rateLimitConfig(), testClients))
+
+	put := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodPut, "/orders", strings.NewReader("{}"))
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, put("random-1"))
+	require.Equal(t, http.StatusTooManyRequests, put("random-2"))
+	require.Equal(t, http.StatusTooManyRequests, put(""))
+	require.Equal(t, http.StatusOK, put("key-a"))
+}
+
+// raggiunto il tetto dei bucket i nuovi client condividono un solo bucket
+func TestLimiter_MaxBuckets(t *testing.T) {
+	limiter := middleware.NewLimiter(2)
+	budget := middleware.Budget{RequestsPerSecond: 0.001, Burst: 1}
+
+	require.True(t, limiter.Allow("a", budget).Allowed)
+	require.True(t, limiter.Allow("b", budget).Allowed)
+	require.True(t, limiter.Allow("c", budget).Allowed)
+	require.False(t, limiter.Allow("d", budget).Allowed)
+	// i client già noti mantengono il proprio bucket
+	require.False(t, limiter.Allow("a", budget).Allowed)
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	r := setupRouter(middleware.RateLimit(config.RateLimit{}, nil))
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	r := setupRouter(middleware.BodyLimit(16))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/orders", strings.NewReader(`{"a":1}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Content-Length dichiarato oltre il limite → 413 immediato
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/orders", strings.NewReader(strings.Repeat("x", 64))))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+	// body senza Content-Length: il limite scatta in lettura
+	req := httptest.NewRequest(http.MethodPut, "/orders", nil)
+	req.Body = io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("x"), 64)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,77 @@
+package certreload
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"purchase-cart-service/internal/certreload"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert genera un certificato self-signed con il CN indicato
+func writeCert(t *testing.T, certFile, keyFile, cn string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, r *certreload.Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+// certificato sostituito su disco → viene ricaricato senza riavvio
+func TestReloader_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "old.example.com", time.Now().Add(-time.Minute))
+
+	r, err := certreload.New(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, "old.example.com", commonName(t, r))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	writeCert(t, certFile, keyFile, "new.example.com", time.Now())
+	require.Eventually(t, func() bool {
+		return commonName(t, r) == "new.example.com"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestReloader_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := certreload.New(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"))
+	require.Error(t, err)
+}
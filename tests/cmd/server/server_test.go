@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"purchase-cart-service/cmd/server"
+	"purchase-cart-service/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer() *server.Server {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		WebApp: config.Server{
+			HostName:        "127.0.0.1",
+			ShutdownTimeout: config.Duration{Duration: 2 * time.Second},
+		},
+		Database: config.Database{Type: "InMemory"},
+	}
+	return server.New(cfg)
+}
+
+// cancellazione del contesto → il server si ferma ed esegue gli hook in ordine inverso
+func TestServer_GracefulShutdown_RunsHooks(t *testing.T) {
+	srv := newTestServer()
+	var calls []string
+	srv.RegisterShutdownHook("first", func(ctx context.Context) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	srv.RegisterShutdownHook("second", func(ctx context.Context) error {
+		calls = append(calls, "second")
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/health", ln.Addr().String()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("il server non si è fermato entro il timeout")
+	}
+	require.Equal(t, []string{"second", "first"}, calls)
+
+	_, err = http.Get(fmt.Sprintf("http://%s/health", ln.Addr().String()))
+	require.Error(t, err)
+}
+
+// allo shutdown /readyz risponde 503 mentre il server continua a servire per DrainDelay
+func TestServer_Shutdown_DrainDelay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := server.New(&config.Config{
+		WebApp: config.Server{
+			HostName:        "127.0.0.1",
+			ShutdownTimeout: config.Duration{Duration: 2 * time.Second},
+			DrainDelay:      config.Duration{Duration: 300 * time.Millisecond},
+		},
+		Database: config.Database{Type: "InMemory"},
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	readyz := func() int {
+		resp, err := http.Get(fmt.Sprintf("http://%s/readyz", ln.Addr().String()))
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	require.Eventually(t, func() bool { return readyz() == http.StatusOK }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.Eventually(t, func() bool { return readyz() == http.StatusServiceUnavailable }, 250*time.Millisecond, 5*time.Millisecond)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("il server non si è fermato entro il timeout")
+	}
+}
+
+// errore di un hook → restituito da Shutdown, gli altri hook vengono comunque eseguiti
+func TestServer_Shutdown_HookError(t *testing.T) {
+	srv := newTestServer()
+	called := false
+	srv.RegisterShutdownHook("ok", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	srv.RegisterShutdownHook("failing", func(ctx context.Context) error {
+		return errors.New("flush failed")
+	})
+
+	err := srv.Shutdown()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failing")
+	require.True(t, called)
+}
+
+// i controlli registrati dal server (repository, tabella IVA, catalogo) passano con lo storage InMemory
+func TestServer_Readiness(t *testing.T) {
+	srv := newTestServer()
+
+	report := srv.Checker().Ready(context.Background())
+	require.True(t, report.Ready(), "report: %+v", report)
+	for _, name := range []string{"order_repository", "vat_rate_repository", "product_repository", "vat_rates", "catalog"} {
+		require.Contains(t, report.Checks, name)
+	}
+
+	require.NoError(t, srv.Shutdown())
+	require.False(t, srv.Checker().Ready(context.Background()).Ready())
+}
+
+// ordine creato via HTTP → evento in outbox inoltrato al file sink, al più tardi allo shutdown
+func TestServer_OutboxRelaysToFileSink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	cfg := &config.Config{
+		WebApp: config.Server{
+			HostName:        "127.0.0.1",
+			ShutdownTimeout: config.Duration{Duration: 2 * time.Second},
+		},
+		Database: config.Database{Type: "InMemory"},
+		Outbox: config.Outbox{
+			PollInterval: config.Duration{Duration: time.Hour},
+			FileSink:     path,
+		},
+	}
+	srv := server.New(cfg)
+
+	body := strings.NewReader(`{"country_code":"IT","items":[{"product_id":"prod1","quantity":1}]}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	require.NoError(t, srv.Shutdown())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"type":"order.created"`)
+}
+
+// ordine pagato via HTTP → fattura emessa dal bus tramite l'outbox
+func TestServer_PaidOrderIsInvoiced(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		WebApp: config.Server{
+			HostName:        "127.0.0.1",
+			ShutdownTimeout: config.Duration{Duration: 2 * time.Second},
+		},
+		Database:  config.Database{Type: "InMemory"},
+		Outbox:    config.Outbox{PollInterval: config.Duration{Duration: 10 * time.Millisecond}},
+		Payments:  config.Payments{Currency: "EUR", AutoCapture: true},
+		Invoicing: config.Invoicing{NumberFormat: "{country}-{year}-{seq}", SequenceDigits: 6},
+	}
+	srv := server.New(cfg)
+	defer srv.Shutdown()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, "/api/v1/orders", `{"country_code":"IT","items":[{"product_id":"prod1","quantity":1}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		OrderID string `json:"order_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	w = do(http.MethodPost, "/api/v1/orders/"+created.OrderID+"/payments", `{"payment_method":"tok_visa"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	require.Eventually(t, func() bool {
+		return do(http.MethodGet, "/api/v1/orders/"+created.OrderID+"/invoice", "").Code == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	w = do(http.MethodGet, "/api/v1/orders/"+created.OrderID+"/invoice", "")
+	require.Contains(t, w.Body.String(), fmt.Sprintf(`"number":"IT-%d-000001"`, time.Now().UTC().Year()))
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"purchase-cart-service/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("scrittura file di configurazione fallita: %v", err)
+	}
+	return path
+}
+
+// default → file → env → flag: ogni livello sovrascrive il precedente
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.json", `{
+  "ServiceName": "from-file",
+  "WebApp": { "HostName": "127.0.0.1", "Port": 9000 },
+  "Database": { "Type": "InMemory", "Username": "file-user" }
+}`)
+	t.Setenv("WEBAPP_PORT", "9100")
+	t.Setenv("DATABASE_USERNAME", "env-user")
+
+	flags, err := config.ParseFlags("test", []string{"--config", path, "--port", "9200"})
+	require.NoError(t, err)
+	cfg, err := config.Load(flags)
+	require.NoError(t, err)
+
+	require.Equal(t, "from-file", cfg.ServiceName)
+	require.Equal(t, "127.0.0.1", cfg.WebApp.HostName)
+	require.Equal(t, 9200, cfg.WebApp.Port)
+	require.Equal(t, "env-user", cfg.Database.Username)
+	require.Equal(t, 20*time.Second, cfg.WebApp.ShutdownTimeout.Duration)
+	require.Equal(t, 5*time.Second, cfg.WebApp.DrainDelay.Duration)
+	require.Equal(t, 10, cfg.Outbox.MaxAttempts)
+}
+
+// la vecchia chiave Database.User è ancora accettata come alias di Username
+func TestLoad_DatabaseUserAlias(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.json": `{ "Database": { "Type": "InMemory", "User": "legacy" } }`,
+		"config.yaml": "Database:\n  Type: InMemory\n  User: legacy\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.Load(&config.Flags{ConfigPath: writeFile(t, name, content)})
+			require.NoError(t, err)
+			require.Equal(t, "legacy", cfg.Database.Username)
+			require.Empty(t, cfg.Database.User)
+		})
+	}
+
+	path := writeFile(t, "config.json", `{ "Database": { "Type": "InMemory", "User": "legacy", "Username": "current" } }`)
+	cfg, err := config.Load(&config.Flags{ConfigPath: path})
+	require.NoError(t, err)
+	require.Equal(t, "current", cfg.Database.Username)
+
+	var out bytes.Buffer
+	require.NoError(t, config.Print(&out, cfg))
+	require.NotContains(t, out.String(), `"User"`)
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+ServiceName: yaml-service
+WebApp:
+  Port: 8181
+  ShutdownTimeout: 5s
+`)
+	cfg, err := config.Load(&config.Flags{ConfigPath: path})
+	require.NoError(t, err)
+	require.Equal(t, "yaml-service", cfg.ServiceName)
+	require.Equal(t, 8181, cfg.WebApp.Port)
+	require.Equal(t, 5*time.Second, cfg.WebApp.ShutdownTimeout.Duration)
+}
+
+// CONFIG_PATH viene usato quando --config non è indicato
+func TestLoad_ConfigPathEnv(t *testing.T) {
+	path := writeFile(t, "config.json", `{"ServiceName": "from-env-path"}`)
+	t.Setenv(config.ConfigPathEnv, path)
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	require.Equal(t, "from-env-path", cfg.ServiceName)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	cases := map[string]struct {
+		file     string
+		contains string
+	}{
+		"campo sconosciuto":           {`{"WebApp": {"Prot": 80}}`, "Prot"},
+		"porta non valida":            {`{"WebApp": {"Port": 70000}}`, "WebApp.Port"},
+		"database non supportato":     {`{"Database": {"Type": "Oracle"}}`, "Database.Type"},
+		"durata non valida":           {`{"WebApp": {"IdleTimeout": "forever"}}`, "forever"},
+		"numerazione senza anno":      {`{"Invoicing": {"NumberFormat": "{country}-{seq}"}}`, "Invoicing.NumberFormat"},
+		"fuso orario sconosciuto":     {`{"Invoicing": {"TimeZone": "Mars/Olympus"}}`, "Invoicing.TimeZone"},
+		"verificatore sconosciuto":    {`{"ReverseCharge": {"Verifier": "VIES"}}`, "ReverseCharge.Verifier"},
+		"origine incompleta":          {`{"SalesTax": {"RatesFile": "rates.csv", "OriginState": "TX"}}`, "SalesTax"},
+		"reverse charge senza paese":  {`{"ReverseCharge": {"Enabled": true}}`, "Invoicing.Seller.CountryCode"},
+		"modalità prezzo sconosciuta": {`{"Catalog": {"PriceMode": "list"}}`, "Catalog.PriceMode"},
+		"arrotondamento sconosciuto":  {`{"Rounding": {"Method": "ceil"}}`, "Rounding"},
+		"arrotondamento senza paese":  {`{"Rounding": {"Countries": [{"Level": "total", "Method": "half-up"}]}}`, "Rounding.Countries[0]"},
+		"cliente senza chiave":        {`{"Pricing": {"Clients": [{"ID": "acme"}]}}`, "Pricing.Clients[0]"},
+		"chiave cliente ripetuta":     {`{"Pricing": {"Clients": [{"ID": "a", "APIKey": "k"}, {"ID": "b", "APIKey": "k"}]}}`, "Pricing.Clients[1]"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, "config.json", tc.file)
+			_, err := config.Load(&config.Flags{ConfigPath: path})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.contains)
+		})
+	}
+}
+
+func TestLoad_MissingExplicitFile(t *testing.T) {
+	_, err := config.Load(&config.Flags{ConfigPath: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+}
+
+func TestLoad_InvalidEnv(t *testing.T) {
+	t.Setenv("WEBAPP_PORT", "abc")
+	_, err := config.Load(&config.Flags{ConfigPath: writeFile(t, "config.json", `{}`)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "WEBAPP_PORT")
+}
+
+// i segreti non compaiono nell'output di --print-config
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Password = "super-secret"
+
+	var buf bytes.Buffer
+	require.NoError(t, config.Print(&buf, cfg))
+	require.NotContains(t, buf.String(), "super-secret")
+	require.Contains(t, buf.String(), "******")
+	require.Equal(t, "super-secret", cfg.Database.Password)
+
+	// anche le chiavi dei clienti nelle liste
+	cfg.Pricing.Clients = []config.PricingClient{{ID: "acme", APIKey: "acme-key"}}
+	buf.Reset()
+	require.NoError(t, config.Print(&buf, cfg))
+	require.NotContains(t, buf.String(), "acme-key")
+	require.Equal(t, "acme-key", cfg.Pricing.Clients[0].APIKey)
+}
@@ -0,0 +1,85 @@
+package bundle
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newService() *bundle.Service {
+	return bundle.NewService(repository.NewBundleRepository("InMemory"), repository.NewProductRepository("InMemory"))
+}
+
+func TestApportion(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		weights []float64
+		want    []float64
+	}{
+		{"in proporzione", 45, []float64{10, 40}, []float64{9, 36}},
+		// 50 su 20, 20, 15: il centesimo del resto va al resto più grande
+		{"resto al più grande", 50, []float64{20, 20, 15}, []float64{18.18, 18.18, 13.64}},
+		{"parti uguali", 10, []float64{1, 1, 1}, []float64{3.34, 3.33, 3.33}},
+		{"senza pesi in parti uguali", 1, []float64{0, 0}, []float64{0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := bundle.Apportion(tt.amount, tt.weights)
+			require.InDeltaSlice(t, tt.want, shares, 0.0001)
+			var total float64
+			for _, s := range shares {
+				total += s
+			}
+			require.InDelta(t, tt.amount, total, 0.0001)
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	svc := newService()
+	ctx := context.Background()
+
+	kit := models.Bundle{ID: "duo", Name: "Duo", Price: 25, Components: []models.BundleComponent{
+		{ProductID: "prod1", Quantity: 1},
+		{ProductID: "tshirt-s-white", Quantity: 1},
+	}}
+	require.NoError(t, svc.Save(ctx, &kit))
+	got, err := svc.Get(ctx, "duo")
+	require.NoError(t, err)
+	require.Equal(t, kit, *got)
+
+	bundles, err := svc.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"duo", "reading-box", "starter-kit"}, []string{bundles[0].ID, bundles[1].ID, bundles[2].ID})
+
+	// senza servizio nessun kit
+	var none *bundle.Service
+	got, err = none.Get(ctx, "starter-kit")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestSave_Invalid(t *testing.T) {
+	svc := newService()
+	components := []models.BundleComponent{{ProductID: "prod1", Quantity: 2}}
+
+	for name, b := range map[string]models.Bundle{
+		"senza nome":            {ID: "x", Price: 5, Components: components},
+		"prezzo nullo":          {ID: "x", Name: "X", Components: components},
+		"modalità sconosciuta":  {ID: "x", Name: "X", Price: 5, PriceMode: "list", Components: components},
+		"una sola unità":        {ID: "x", Name: "X", Price: 5, Components: []models.BundleComponent{{ProductID: "prod1", Quantity: 1}}},
+		"quantità nulla":        {ID: "x", Name: "X", Price: 5, Components: []models.BundleComponent{{ProductID: "prod1"}, {ProductID: "prod2", Quantity: 2}}},
+		"prodotto sconosciuto":  {ID: "x", Name: "X", Price: 5, Components: []models.BundleComponent{{ProductID: "nope", Quantity: 2}}},
+		"prodotto con varianti": {ID: "x", Name: "X", Price: 5, Components: []models.BundleComponent{{ProductID: "tshirt", Quantity: 2}}},
+		"prodotto ripetuto":     {ID: "x", Name: "X", Price: 5, Components: []models.BundleComponent{{ProductID: "prod1", Quantity: 1}, {ProductID: "prod1", Quantity: 1}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, svc.Save(context.Background(), &b), bundle.ErrInvalidBundle)
+		})
+	}
+}
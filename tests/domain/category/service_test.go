@@ -0,0 +1,81 @@
+package category
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newService() *category.Service {
+	return category.NewService(repository.NewCategoryRepository("InMemory"))
+}
+
+func slugs(nodes []*category.Node) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.Slug)
+	}
+	return out
+}
+
+func TestTree(t *testing.T) {
+	tree, err := newService().Tree(context.Background())
+	require.NoError(t, err)
+
+	// le radici seguono la posizione, le sottocategorie stanno sotto il padre
+	require.Equal(t, []string{"apparel", "electronics", "books", "food", "gifts"}, slugs(tree))
+	require.Equal(t, []string{"t-shirts"}, slugs(tree[0].Children))
+	require.Equal(t, []string{"accessories"}, slugs(tree[1].Children))
+	require.Empty(t, tree[2].Children)
+}
+
+func TestSubtree(t *testing.T) {
+	svc := newService()
+	ctx := context.Background()
+
+	c, ids, err := svc.Subtree(ctx, "electronics")
+	require.NoError(t, err)
+	require.Equal(t, "Electronics", c.Name)
+	require.Equal(t, []string{"cat-electronics", "cat-accessories"}, ids)
+
+	_, ids, err = svc.Subtree(ctx, "accessories")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cat-accessories"}, ids)
+
+	_, _, err = svc.Subtree(ctx, "garden")
+	require.ErrorIs(t, err, category.ErrCategoryNotFound)
+}
+
+func TestTaxClass(t *testing.T) {
+	svc := newService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		product models.Product
+		want    string
+	}{
+		{"dalla categoria principale", models.Product{CategoryIDs: []string{"cat-books", "cat-gifts"}}, models.TaxClassSuperReduced},
+		{"ereditata dal padre", models.Product{CategoryIDs: []string{"cat-tshirts"}}, models.TaxClassStandard},
+		{"il prodotto prevale", models.Product{CategoryIDs: []string{"cat-books"}, TaxClass: models.TaxClassZero}, models.TaxClassZero},
+		{"senza classe nella catena", models.Product{CategoryIDs: []string{"cat-gifts"}}, models.TaxClassStandard},
+		{"senza categorie", models.Product{}, models.TaxClassStandard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, err := svc.TaxClass(ctx, tt.product)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, class)
+		})
+	}
+
+	// senza servizio conta solo la classe del prodotto
+	var none *category.Service
+	class, err := none.TaxClass(ctx, models.Product{CategoryIDs: []string{"cat-books"}})
+	require.NoError(t, err)
+	require.Equal(t, models.TaxClassStandard, class)
+}
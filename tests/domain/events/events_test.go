@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxMessage_RoundTrip(t *testing.T) {
+	order := &models.Order{
+		ID:         "ord-1",
+		Status:     models.OrderStatusCreated,
+		TotalPrice: 24.4,
+		TotalVAT:   4.4,
+		Items:      []models.Item{{ProductID: "prod1", Name: "Product 1", Quantity: 2, UnitPrice: 10, VAT: 24.4}},
+	}
+	cases := []events.Event{
+		events.OrderCreated{Order: events.NewOrder(order)},
+		events.OrderCancelled{Order: events.NewOrder(order)},
+		events.OrderShipped{Order: events.NewOrder(order)},
+		events.ProductPriceChanged{ProductID: "prod1", OldPrice: 10, NewPrice: 12.5},
+	}
+	for _, event := range cases {
+		t.Run(event.EventType(), func(t *testing.T) {
+			msg, err := events.NewOutboxMessage(event)
+			require.NoError(t, err)
+			require.NotEmpty(t, msg.ID)
+			require.Equal(t, event.EventType(), msg.EventType)
+			require.Equal(t, event.AggregateID(), msg.AggregateID)
+
+			envelope, err := events.Decode(msg)
+			require.NoError(t, err)
+			require.Equal(t, msg.ID, envelope.ID)
+			require.Equal(t, event, envelope.Event)
+		})
+	}
+}
+
+func TestDecode_UnknownType(t *testing.T) {
+	_, err := events.Decode(&models.OutboxMessage{ID: "1", EventType: "order.lost", Payload: []byte("{}")})
+	require.ErrorIs(t, err, events.ErrUnknownEvent)
+}
+
+func TestBus_DispatchesByType(t *testing.T) {
+	bus := events.NewBus()
+	var orders, prices []string
+	bus.Subscribe("orders", func(ctx context.Context, e events.Envelope) error {
+		orders = append(orders, e.Event.AggregateID())
+		return nil
+	}, events.TypeOrderCreated, events.TypeOrderCancelled)
+	bus.Subscribe("prices", func(ctx context.Context, e events.Envelope) error {
+		prices = append(prices, e.Event.(events.ProductPriceChanged).ProductID)
+		return nil
+	}, events.TypeProductPriceChanged)
+	sinks := bus.Sinks()
+	require.Len(t, sinks, 2)
+	require.Equal(t, "bus:orders", sinks[0].Name())
+	require.Equal(t, "bus:prices", sinks[1].Name())
+
+	for _, event := range []events.Event{
+		events.OrderCreated{Order: events.Order{ID: "a"}},
+		events.OrderShipped{Order: events.Order{ID: "b"}},
+		events.OrderCancelled{Order: events.Order{ID: "c"}},
+		events.ProductPriceChanged{ProductID: "prod1"},
+	} {
+		msg, err := events.NewOutboxMessage(event)
+		require.NoError(t, err)
+		// ogni sottoscrizione riceve solo i propri tipi
+		for _, sink := range sinks {
+			require.NoError(t, sink.Send(context.Background(), msg))
+		}
+	}
+	require.Equal(t, []string{"a", "c"}, orders, "order.shipped non ha sottoscrittori")
+	require.Equal(t, []string{"prod1"}, prices)
+}
+
+func TestBus_ReportsHandlerErrors(t *testing.T) {
+	bus := events.NewBus()
+	calls := 0
+	bus.Subscribe("failing", func(ctx context.Context, e events.Envelope) error {
+		calls++
+		return errors.New("boom")
+	}, events.TypeOrderCreated)
+	bus.Subscribe("healthy", func(ctx context.Context, e events.Envelope) error {
+		calls++
+		return nil
+	}, events.TypeOrderCreated)
+
+	err := bus.Publish(context.Background(), events.Envelope{ID: "1", Event: events.OrderCreated{}})
+	require.EqualError(t, err, "boom")
+	require.Equal(t, 2, calls, "un handler in errore non blocca gli altri")
+}
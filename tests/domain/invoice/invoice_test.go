@@ -0,0 +1,218 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/invoice"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var defaultNumbering = invoice.Numbering{Format: invoice.DefaultNumberFormat, Digits: 6, FiscalYearStart: time.January}
+
+func newServices(numbering invoice.Numbering) (*order.Service, *invoice.Service) {
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+	return orders, invoice.NewService(repository.NewInvoiceRepository("InMemory"), orders, numbering, invoice.Seller{Name: "Purchase Cart"}, "EUR")
+}
+
+// paidOrder crea un ordine per il paese e lo segna come pagato
+func paidOrder(t *testing.T, orders *order.Service, countryCode string) string {
+	t.Helper()
+	o, err := orders.CreateOrder(context.Background(), countryCode, []order.CreateItem{{ProductID: "prod1", Quantity: 2}})
+	require.NoError(t, err)
+	_, err = orders.MarkPaid(context.Background(), o.ID)
+	require.NoError(t, err)
+	return o.ID
+}
+
+func TestNumbering(t *testing.T) {
+	require.Equal(t, "IT-2026-000123", defaultNumbering.Number("IT", 2026, 123))
+	require.Equal(t, "IT-2026-1234567", defaultNumbering.Number("IT", 2026, 1234567), "la sequenza non viene troncata")
+	custom := invoice.Numbering{Format: "FT/{year}/{country}/{seq}", Digits: 4}
+	require.Equal(t, "FT/2026/DE/0042", custom.Number("DE", 2026, 42))
+
+	// anno fiscale da aprile: marzo appartiene all'anno precedente
+	april := invoice.Numbering{FiscalYearStart: time.April}
+	require.Equal(t, 2025, april.FiscalYear(time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, 2026, april.FiscalYear(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)))
+
+	// la data di emissione è letta nel fuso configurato
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+	newYearsEve := time.Date(2025, time.December, 31, 23, 30, 0, 0, time.UTC)
+	require.Equal(t, 2025, defaultNumbering.FiscalYear(newYearsEve))
+	require.Equal(t, 2026, invoice.Numbering{Location: rome}.FiscalYear(newYearsEve))
+}
+
+func TestIssue_SequencePerCountryAndYear(t *testing.T) {
+	orders, invoices := newServices(defaultNumbering)
+	ctx := context.Background()
+	at2026 := time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+	at2027 := time.Date(2027, time.January, 2, 10, 0, 0, 0, time.UTC)
+
+	var numbers []string
+	for _, tc := range []struct {
+		country string
+		at      time.Time
+	}{{"IT", at2026}, {"IT", at2026}, {"DE", at2026}, {"IT", at2027}, {"DE", at2026}} {
+		inv, err := invoices.Issue(ctx, paidOrder(t, orders, tc.country), tc.at)
+		require.NoError(t, err)
+		numbers = append(numbers, inv.Number)
+	}
+	require.Equal(t, []string{"IT-2026-000001", "IT-2026-000002", "DE-2026-000001", "IT-2027-000001", "DE-2026-000002"}, numbers)
+}
+
+func TestIssue_ContentAndIdempotency(t *testing.T) {
+	orders, invoices := newServices(defaultNumbering)
+	ctx := context.Background()
+	id := paidOrder(t, orders, "IT")
+	at := time.Date(2026, time.May, 4, 9, 0, 0, 0, time.UTC)
+
+	inv, err := invoices.Issue(ctx, id, at)
+	require.NoError(t, err)
+	require.Equal(t, id, inv.OrderID)
+	require.Equal(t, "IT", inv.CountryCode)
+	require.Equal(t, 2026, inv.FiscalYear)
+	require.Equal(t, int64(1), inv.Sequence)
+	require.Equal(t, "EUR", inv.Currency)
+	require.Equal(t, at, inv.IssuedAt)
+	require.Equal(t, []models.InvoiceLine{{
+		ProductID: "prod1", Name: "Product 1", Quantity: 2, UnitPrice: 10, VATRate: 0.22, Net: 20, VAT: 4.40, Total: 24.40,
+	}}, inv.Lines)
+	require.Equal(t, 20.0, inv.TotalNet)
+	require.Equal(t, 4.40, inv.TotalVAT)
+	require.Equal(t, 24.40, inv.Total)
+
+	// una seconda emissione restituisce la stessa fattura senza consumare numeri
+	again, err := invoices.Issue(ctx, id, at.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, inv.Number, again.Number)
+	next, err := invoices.Issue(ctx, paidOrder(t, orders, "IT"), at)
+	require.NoError(t, err)
+	require.Equal(t, "IT-2026-000002", next.Number)
+}
+
+func TestIssue_RequiresPaidOrder(t *testing.T) {
+	orders, invoices := newServices(defaultNumbering)
+	ctx := context.Background()
+	o, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+
+	_, err = invoices.Issue(ctx, o.ID, time.Now())
+	require.Equal(t, invoice.ErrOrderNotPaid, err)
+	_, err = invoices.Issue(ctx, "missing", time.Now())
+	require.Equal(t, invoice.ErrOrderNotFound, err)
+	_, err = invoices.GetInvoice(ctx, o.ID)
+	require.Equal(t, invoice.ErrInvoiceNotFound, err)
+
+	// il numero non consumato resta disponibile per la prossima fattura
+	inv, err := invoices.Issue(ctx, paidOrder(t, orders, "IT"), time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, "IT-2026-000001", inv.Number)
+}
+
+func TestHandleOrderEvent_IssuesOnPaid(t *testing.T) {
+	orders, invoices := newServices(defaultNumbering)
+	ctx := context.Background()
+	id := paidOrder(t, orders, "IT")
+	paidAt := time.Date(2026, time.July, 1, 8, 0, 0, 0, time.UTC)
+
+	require.NoError(t, invoices.HandleOrderEvent(ctx, events.Envelope{ID: "evt-1", Event: events.OrderShipped{Order: events.Order{ID: id}}}))
+	_, err := invoices.GetInvoice(ctx, id)
+	require.Equal(t, invoice.ErrInvoiceNotFound, err, "solo order.paid emette la fattura")
+
+	envelope := events.Envelope{ID: "evt-2", OccurredAt: paidAt, Event: events.OrderPaid{Order: events.Order{ID: id}}}
+	require.NoError(t, invoices.HandleOrderEvent(ctx, envelope))
+	require.NoError(t, invoices.HandleOrderEvent(ctx, envelope), "consegna ripetuta")
+	inv, err := invoices.GetInvoice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "IT-2026-000001", inv.Number)
+	require.Equal(t, paidAt, inv.IssuedAt)
+}
+
+// emissioni concorrenti → numeri consecutivi, senza buchi né duplicati
+func TestIssue_Concurrent(t *testing.T) {
+	orders, invoices := newServices(defaultNumbering)
+	ctx := context.Background()
+	at := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	const n = 50
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = paidOrder(t, orders, "IT")
+	}
+
+	var wg sync.WaitGroup
+	numbers := make(chan string, 2*n)
+	for _, id := range ids {
+		// ogni ordine è emesso due volte, come con una consegna ripetuta dell'evento
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				inv, err := invoices.Issue(ctx, id, at)
+				if err == nil {
+					numbers <- inv.Number
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := make(map[string]int)
+	for number := range numbers {
+		seen[number]++
+	}
+	require.Len(t, seen, n)
+	for i := 1; i <= n; i++ {
+		require.Equal(t, 2, seen[fmt.Sprintf("IT-2026-%06d", i)])
+	}
+}
+
+func TestIssue_ReverseCharge(t *testing.T) {
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"),
+		order.WithReverseCharge(vatid.NewStubVerifier("DE136695976"), "IT"))
+	invoices := invoice.NewService(repository.NewInvoiceRepository("InMemory"), orders, defaultNumbering, invoice.Seller{Name: "Purchase Cart"}, "EUR")
+	ctx := context.Background()
+
+	o, err := orders.CreateOrder(ctx, "DE", []order.CreateItem{{ProductID: "prod1", Quantity: 2}}, order.WithBuyerVATID("DE136695976"))
+	require.NoError(t, err)
+	_, err = orders.MarkPaid(ctx, o.ID)
+	require.NoError(t, err)
+
+	inv, err := invoices.Issue(ctx, o.ID, time.Date(2026, time.May, 4, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, inv.ReverseCharge)
+	require.Equal(t, "DE136695976", inv.BuyerVATID)
+	require.Zero(t, inv.TotalVAT)
+	require.Zero(t, inv.Lines[0].VATRate)
+}
+
+func TestIssue_GrossPrice(t *testing.T) {
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"),
+		order.WithPriceMode(models.PriceModeGross))
+	invoices := invoice.NewService(repository.NewInvoiceRepository("InMemory"), orders, defaultNumbering, invoice.Seller{Name: "Purchase Cart"}, "EUR")
+	ctx := context.Background()
+
+	// 3 x 10.00 IVA inclusa: 5.41 di IVA estratti dalla riga, non dal prezzo unitario
+	o, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 3}})
+	require.NoError(t, err)
+	_, err = orders.MarkPaid(ctx, o.ID)
+	require.NoError(t, err)
+
+	inv, err := invoices.Issue(ctx, o.ID, time.Date(2026, time.May, 4, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 30.0, inv.Total)
+	require.Equal(t, 5.41, inv.TotalVAT)
+	require.Equal(t, 24.59, inv.TotalNet)
+	require.Equal(t, 24.59, inv.Lines[0].Net)
+	require.Equal(t, 5.41, inv.Lines[0].VAT)
+}
@@ -0,0 +1,116 @@
+package invoice
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"purchase-cart-service/internal/domain/invoice"
+	"purchase-cart-service/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// go test ./tests/domain/invoice -update riscrive i file golden
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func sampleDocument() invoice.Document {
+	return invoice.Document{
+		Invoice: models.Invoice{
+			Number:      "IT-2026-000123",
+			OrderID:     "5f0c6a2e-8d1b-4c3e-9a57-2b8e4f1d7c90",
+			CountryCode: "IT",
+			FiscalYear:  2026,
+			Sequence:    123,
+			Currency:    "EUR",
+			Buyer: models.Address{
+				Name:        "Niccolò Bianchi",
+				Line1:       "Corso Università 12",
+				City:        "Torino",
+				PostalCode:  "10124",
+				Region:      "TO",
+				CountryCode: "IT",
+			},
+			Lines: []models.InvoiceLine{
+				{ProductID: "prod1", Name: "Product 1", Quantity: 2, UnitPrice: 10, VATRate: 0.22, Net: 20, VAT: 4.40, Total: 24.40},
+				{ProductID: "book", Name: "Guida <rapida> & pratica", Quantity: 1, UnitPrice: 15, VATRate: 0.04, Net: 15, VAT: 0.60, Total: 15.60},
+				{ProductID: "prod2", Name: "Product 2", Quantity: 3, UnitPrice: 3.33, VATRate: 0.22, Net: 9.99, VAT: 2.20, Total: 12.19},
+			},
+			TotalNet: 44.99,
+			TotalVAT: 7.20,
+			Total:    52.19,
+			IssuedAt: time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC),
+		},
+		Seller: invoice.Seller{
+			Name:  "Purchase Cart S.r.l.",
+			VATID: "IT12345678903",
+			Email: "billing@purchase-cart.example",
+			Address: models.Address{
+				Line1:       "Via Roma 1",
+				City:        "Milano",
+				PostalCode:  "20121",
+				Region:      "MI",
+				CountryCode: "IT",
+			},
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "file golden mancante: eseguire con -update")
+	require.True(t, bytes.Equal(want, got), "%s diverso dal file golden: eseguire con -update e verificare il diff", name)
+}
+
+func TestVATSummary(t *testing.T) {
+	require.Equal(t, []invoice.VATBreakdown{
+		{Rate: 0.04, Net: 15, VAT: 0.60},
+		{Rate: 0.22, Net: 29.99, VAT: 6.60},
+	}, sampleDocument().VATSummary())
+}
+
+func TestRenderHTML_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, invoice.RenderHTML(&buf, sampleDocument()))
+	assertGolden(t, "invoice.html.golden", buf.Bytes())
+	require.Contains(t, buf.String(), "Guida &lt;rapida&gt; &amp; pratica", "il testo è sempre escapato")
+}
+
+func TestRenderPDF_Golden(t *testing.T) {
+	var first, second bytes.Buffer
+	require.NoError(t, invoice.RenderPDF(&first, sampleDocument()))
+	require.NoError(t, invoice.RenderPDF(&second, sampleDocument()))
+	require.Equal(t, first.Bytes(), second.Bytes(), "lo stesso documento produce gli stessi byte")
+	require.True(t, bytes.HasPrefix(first.Bytes(), []byte("%PDF-")))
+	assertGolden(t, "invoice.pdf.golden", first.Bytes())
+}
+
+func TestRender_ReverseCharge(t *testing.T) {
+	doc := sampleDocument()
+	doc.Buyer = models.Address{Name: "Muster GmbH", Line1: "Hauptstraße 5", City: "Berlin", PostalCode: "10115", CountryCode: "DE"}
+	doc.CountryCode = "DE"
+	doc.BuyerVATID = "DE136695976"
+	doc.ReverseCharge = true
+
+	var html bytes.Buffer
+	require.NoError(t, invoice.RenderHTML(&html, doc))
+	require.Contains(t, html.String(), "VAT ID DE136695976")
+	require.Contains(t, html.String(), invoice.ReverseChargeNote)
+
+	var pdf bytes.Buffer
+	require.NoError(t, invoice.RenderPDF(&pdf, doc))
+	require.Contains(t, pdf.String(), "DE136695976", "il PDF non è compresso: il testo è leggibile")
+	require.Contains(t, pdf.String(), "Reverse charge")
+
+	// senza inversione contabile la dicitura non compare
+	html.Reset()
+	require.NoError(t, invoice.RenderHTML(&html, sampleDocument()))
+	require.NotContains(t, html.String(), invoice.ReverseChargeNote)
+}
@@ -0,0 +1,102 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newBundleOrderService(orders repository.OrderRepository) *order.Service {
+	products := repository.NewProductRepository("InMemory")
+	return order.NewService(orders, repository.NewVatRateRepository("InMemory"), products,
+		order.WithCategories(category.NewService(repository.NewCategoryRepository("InMemory"))),
+		order.WithBundles(bundle.NewService(repository.NewBundleRepository("InMemory"), products)))
+}
+
+func TestCreateOrder_Bundle(t *testing.T) {
+	svc := newBundleOrderService(repository.NewOrderRepository("InMemory", repository.WithEventSourcing(0)))
+	ctx := context.Background()
+
+	// starter kit a 45: prod1 (10) e 2 prod2 (20), il prezzo si ripartisce 9 e 2×18
+	o, err := svc.CreateOrder(ctx, "IT", []order.CreateItem{
+		{ProductID: "prod3", Quantity: 1},
+		{BundleID: "starter-kit", Quantity: 2},
+	})
+	require.NoError(t, err)
+	require.Len(t, o.Items, 3)
+	require.Nil(t, o.Items[0].Bundle)
+	kit := &models.ItemBundle{Line: 2, ID: "starter-kit", Name: "Starter kit", Quantity: 2}
+	require.Equal(t, "prod1", o.Items[1].ProductID)
+	require.Equal(t, 2, o.Items[1].Quantity)
+	require.Equal(t, 9.0, o.Items[1].UnitPrice)
+	require.Equal(t, kit, o.Items[1].Bundle)
+	require.Equal(t, "prod2", o.Items[2].ProductID)
+	require.Equal(t, 4, o.Items[2].Quantity)
+	require.Equal(t, 18.0, o.Items[2].UnitPrice)
+	require.Equal(t, kit, o.Items[2].Bundle)
+	// netto 20 + 2×45 = 110, IVA 22% 24.20
+	require.InDelta(t, 134.2, o.TotalPrice, 0.0001)
+
+	// il raggruppamento resta nell'ordine salvato
+	detail, err := svc.GetOrderByID(ctx, o.ID)
+	require.NoError(t, err)
+	require.Equal(t, kit, detail.Items[2].Bundle)
+	require.Nil(t, detail.Items[0].Bundle)
+}
+
+func TestCreateOrder_BundleApportionedVAT(t *testing.T) {
+	svc := newBundleOrderService(repository.NewOrderRepository("InMemory"))
+
+	// reading box a 50: libro (20, 4%), cibo (20, 10%) e t-shirt (15, 22%), per valore di catalogo
+	o, err := svc.CreateOrder(context.Background(), "IT", []order.CreateItem{{BundleID: "reading-box", Quantity: 1}})
+	require.NoError(t, err)
+	require.Len(t, o.Items, 3)
+	for i, want := range []struct {
+		price float64
+		rate  float64
+		total float64
+	}{
+		{18.18, 0.04, 18.91},
+		{18.18, 0.10, 20.00},
+		{13.64, 0.22, 16.64},
+	} {
+		require.Equal(t, want.price, o.Items[i].UnitPrice, "componente %d", i)
+		require.Equal(t, want.rate, o.Items[i].VATRate, "componente %d", i)
+		require.InDelta(t, want.total, o.Items[i].VAT, 0.0001, "componente %d", i)
+	}
+	require.Equal(t, "tshirt-m-white", o.Items[2].SKU)
+	require.InDelta(t, 5.55, o.TotalVAT, 0.0001)
+	require.InDelta(t, 55.55, o.TotalPrice, 0.0001)
+}
+
+func TestCreateOrder_BundleErrors(t *testing.T) {
+	svc := newBundleOrderService(repository.NewOrderRepository("InMemory"))
+	ctx := context.Background()
+
+	_, err := svc.CreateOrder(ctx, "IT", []order.CreateItem{{BundleID: "missing", Quantity: 1}})
+	require.ErrorIs(t, err, bundle.ErrBundleNotFound)
+
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{BundleID: "starter-kit", ProductID: "prod1", Quantity: 1}})
+	require.ErrorIs(t, err, order.ErrInvalidItem)
+
+	// la t-shirt M bianca ha 10 pezzi: gli undici kit non ci stanno
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{BundleID: "reading-box", Quantity: 11}})
+	require.ErrorIs(t, err, order.ErrOutOfStock)
+
+	// i componenti impegnano lo stock della variante
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{BundleID: "reading-box", Quantity: 10}})
+	require.NoError(t, err)
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{SKU: "tshirt-m-white", Quantity: 1}})
+	require.ErrorIs(t, err, order.ErrOutOfStock)
+
+	// senza kit configurati nessun kit si trova
+	plain := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+	_, err = plain.CreateOrder(ctx, "IT", []order.CreateItem{{BundleID: "starter-kit", Quantity: 1}})
+	require.ErrorIs(t, err, bundle.ErrBundleNotFound)
+}
@@ -0,0 +1,48 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrder_PriceLists(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	prices := pricing.NewService(repository.NewPriceListRepository("InMemory"), products,
+		pricing.WithClock(func() time.Time { return time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC) }))
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), products, order.WithPricing(prices))
+	items := []order.CreateItem{
+		{ProductID: "prod1", Quantity: 2},
+		{ProductID: "prod3", Quantity: 1},
+		{SKU: "tshirt-s-black", Quantity: 1},
+	}
+
+	// acme ha un contratto proprio e appartiene al gruppo wholesale
+	ctx := pricing.NewContext(context.Background(), pricing.Buyer{Client: "acme", Group: "wholesale"})
+	o, err := svc.CreateOrder(ctx, "IT", items)
+	require.NoError(t, err)
+	require.Equal(t, 7.5, o.Items[0].UnitPrice)
+	require.Equal(t, "acme-2026", o.Items[0].PriceList)
+	require.Equal(t, 20.0, o.Items[1].UnitPrice)
+	require.Empty(t, o.Items[1].PriceList)
+	require.Equal(t, 13.0, o.Items[2].UnitPrice)
+	require.Equal(t, "acme-2026", o.Items[2].PriceList)
+	// netto 15 + 20 + 13 = 48, IVA 22% 10.56
+	require.InDelta(t, 58.56, o.TotalPrice, 0.0001)
+
+	detail, err := svc.GetOrderByID(ctx, o.ID)
+	require.NoError(t, err)
+	require.Equal(t, "acme-2026", detail.Items[0].PriceList)
+
+	// lo stesso ordine senza cliente è a prezzi di catalogo
+	o, err = svc.CreateOrder(context.Background(), "IT", items)
+	require.NoError(t, err)
+	require.Equal(t, 10.0, o.Items[0].UnitPrice)
+	require.Empty(t, o.Items[0].PriceList)
+	require.Equal(t, 17.0, o.Items[2].UnitPrice)
+}
@@ -0,0 +1,58 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrder_GrossCatalog(t *testing.T) {
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"),
+		order.WithPriceMode(models.PriceModeGross))
+
+	// 2 x 10.00 IVA inclusa al 22%: il cliente paga 20.00, di cui 3.61 di IVA
+	o, err := svc.CreateOrder(context.Background(), "IT", twoOfProd1)
+	require.NoError(t, err)
+	require.Equal(t, 20.0, o.TotalPrice)
+	require.Equal(t, 3.61, o.TotalVAT)
+
+	item := o.Items[0]
+	require.Equal(t, models.PriceModeGross, item.PriceMode)
+	require.Equal(t, 10.0, item.UnitPriceWithVAT)
+	require.Equal(t, 8.20, item.UnitPrice)
+	require.Equal(t, 20.0, item.VAT)
+	require.Equal(t, 16.39, item.Net())
+	require.Len(t, item.Taxes, 1)
+	require.Equal(t, 3.61, item.Taxes[0].Amount)
+
+	detail, err := svc.GetOrderByID(context.Background(), o.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PriceModeGross, detail.Items[0].PriceMode)
+	require.Equal(t, 10.0, detail.Items[0].PriceWithVAT)
+}
+
+func TestCreateOrder_GrossProductOverride(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	_, err := product.NewService(products, vatRepo).UpdatePrice(context.Background(), "prod2", 12.20, models.PriceModeGross)
+	require.NoError(t, err)
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, products)
+
+	o, err := svc.CreateOrder(context.Background(), "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}, {ProductID: "prod2", Quantity: 1}})
+	require.NoError(t, err)
+
+	// prod1 segue il catalogo netto, prod2 ha il prezzo IVA inclusa
+	require.Equal(t, models.PriceModeNet, o.Items[0].PriceMode)
+	require.Equal(t, 10.0, o.Items[0].UnitPrice)
+	require.Equal(t, 12.20, o.Items[0].UnitPriceWithVAT)
+	require.Equal(t, models.PriceModeGross, o.Items[1].PriceMode)
+	require.Equal(t, 10.0, o.Items[1].UnitPrice)
+	require.Equal(t, 12.20, o.Items[1].UnitPriceWithVAT)
+	require.Equal(t, 24.40, o.TotalPrice)
+	require.Equal(t, 4.40, o.TotalVAT)
+}
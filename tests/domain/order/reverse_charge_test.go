@@ -0,0 +1,83 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unavailableVerifier simula il registro VIES non raggiungibile
+type unavailableVerifier struct{}
+
+func (unavailableVerifier) Verify(context.Context, vatid.Number) (vatid.Verification, error) {
+	return vatid.Verification{}, errors.New("registry timeout")
+}
+
+func newReverseChargeService(verifier vatid.Verifier) *order.Service {
+	return order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"),
+		order.WithReverseCharge(verifier, "IT"))
+}
+
+var twoOfProd1 = []order.CreateItem{{ProductID: "prod1", Quantity: 2}}
+
+func TestCreateOrder_ReverseChargeIntraCommunity(t *testing.T) {
+	svc := newReverseChargeService(vatid.NewStubVerifier("DE136695976"))
+
+	o, err := svc.CreateOrder(context.Background(), "DE", twoOfProd1, order.WithBuyerVATID("de 136-695-976"))
+	require.NoError(t, err)
+	require.True(t, o.ReverseCharge)
+	require.Equal(t, "DE136695976", o.BuyerVATID, "il numero è normalizzato")
+	require.Zero(t, o.TotalVAT)
+	require.InDelta(t, 20.0, o.TotalPrice, 0.0001)
+	require.Zero(t, o.Items[0].VATRate)
+
+	stored, err := svc.GetOrder(context.Background(), o.ID)
+	require.NoError(t, err)
+	require.True(t, stored.ReverseCharge)
+	require.Equal(t, "DE136695976", stored.BuyerVATID)
+}
+
+func TestCreateOrder_VATChargedOutsideReverseCharge(t *testing.T) {
+	svc := newReverseChargeService(vatid.NewStubVerifier("IT00743110157", "DE136695976"))
+	cases := map[string]struct {
+		country string
+		vatID   string
+		vat     float64
+	}{
+		"vendita nazionale":             {"IT", "IT00743110157", 4.40},
+		"partita IVA di un altro paese": {"FR", "DE136695976", 20 * 0.20},
+		"cliente privato":               {"DE", "", 20 * 0.19},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := svc.CreateOrder(context.Background(), tc.country, twoOfProd1, order.WithBuyerVATID(tc.vatID))
+			require.NoError(t, err)
+			require.False(t, o.ReverseCharge)
+			require.InDelta(t, tc.vat, o.TotalVAT, 0.0001)
+		})
+	}
+}
+
+func TestCreateOrder_ReverseChargeDisabled(t *testing.T) {
+	o, err := svc.CreateOrder(context.Background(), "DE", twoOfProd1, order.WithBuyerVATID("DE136695976"))
+	require.NoError(t, err)
+	require.False(t, o.ReverseCharge, "senza verificatore l'IVA è sempre addebitata")
+	require.Equal(t, "DE136695976", o.BuyerVATID)
+}
+
+func TestCreateOrder_BuyerVATIDErrors(t *testing.T) {
+	_, err := newReverseChargeService(vatid.NewStubVerifier("DE136695976")).CreateOrder(context.Background(), "DE", twoOfProd1, order.WithBuyerVATID("DE136695977"))
+	require.ErrorIs(t, err, order.ErrInvalidVATID)
+	require.ErrorIs(t, err, vatid.ErrInvalidChecksum)
+
+	_, err = newReverseChargeService(vatid.NewStubVerifier()).CreateOrder(context.Background(), "DE", twoOfProd1, order.WithBuyerVATID("DE136695976"))
+	require.ErrorIs(t, err, order.ErrVATIDNotRegistered)
+
+	_, err = newReverseChargeService(unavailableVerifier{}).CreateOrder(context.Background(), "DE", twoOfProd1, order.WithBuyerVATID("DE136695976"))
+	require.ErrorIs(t, err, order.ErrVATIDVerificationUnavailable)
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrder_RoundingByCountry(t *testing.T) {
+	ctx := context.Background()
+	products := repository.NewProductRepository("InMemory")
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	prices := product.NewService(products, vatRepo)
+	for _, id := range []string{"prod1", "prod2", "prod3"} {
+		_, err := prices.UpdatePrice(ctx, id, 0.10, "")
+		require.NoError(t, err)
+	}
+	svc := order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, products, order.WithRounding(rounding.Policies{
+		Countries: map[string]rounding.Policy{"GR": {Level: rounding.PerTotal, Method: rounding.HalfUp}},
+	}))
+	items := []order.CreateItem{{ProductID: "prod1", Quantity: 1}, {ProductID: "prod2", Quantity: 1}, {ProductID: "prod3", Quantity: 1}}
+
+	// GR arrotonda l'IVA sul totale per aliquota: 3 x 0.024 = 0.072 -> 0.07
+	o, err := svc.CreateOrder(ctx, "GR", items)
+	require.NoError(t, err)
+	require.Equal(t, 0.07, o.TotalVAT)
+	require.Equal(t, 0.37, o.TotalPrice)
+	require.Equal(t, 0.13, o.Items[0].VAT)
+
+	// IT segue la regola predefinita, riga per riga: 3 x 0.022 -> 3 x 0.02
+	o, err = svc.CreateOrder(ctx, "IT", items)
+	require.NoError(t, err)
+	require.Equal(t, 0.06, o.TotalVAT)
+	require.Equal(t, 0.36, o.TotalPrice)
+}
@@ -0,0 +1,65 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSalesTaxService(t *testing.T) *order.Service {
+	t.Helper()
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	salesTax := repository.NewSalesTaxRepository("InMemory")
+	require.NoError(t, salesTax.ReplaceAll(context.Background(), []models.SalesTaxRegion{
+		{State: "NY", PostalCode: "10001", StateRate: 0.04, City: "New York City", CityRate: 0.045, Special: "MCTD", SpecialRate: 0.00375},
+	}))
+	taxes := tax.NewService(vatRepo, tax.WithSalesTax(salesTax, models.Destination{CountryCode: "US", Region: "TX", PostalCode: "73301"}))
+	return order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, repository.NewProductRepository("InMemory"), order.WithTax(taxes))
+}
+
+func TestCreateOrder_USSalesTax(t *testing.T) {
+	svc := newSalesTaxService(t)
+	o, err := svc.CreateOrder(context.Background(), "US", []order.CreateItem{{ProductID: "prod1", Quantity: 3}, {ProductID: "prod2", Quantity: 1}},
+		order.WithShippingAddress(models.Address{Line1: "350 5th Ave", City: "New York", Region: "NY", PostalCode: "10001", CountryCode: "US"}))
+	require.NoError(t, err)
+
+	// riga prod1: 30 * 8.875% = 2.6625 -> 2.66; riga prod2: 20 * 8.875% = 1.775 -> 1.78
+	require.InDelta(t, 4.44, o.TotalVAT, 0.0001)
+	require.InDelta(t, 54.44, o.TotalPrice, 0.0001)
+	for _, it := range o.Items {
+		require.Equal(t, 0.08875, it.VATRate)
+		require.Len(t, it.Taxes, 3)
+		var sum float64
+		for _, share := range it.Taxes {
+			sum += share.Amount
+		}
+		lineTax := it.VAT - float64(it.Quantity)*it.UnitPrice
+		require.InDelta(t, lineTax, sum, 0.0001, "le quote delle giurisdizioni sommano all'imposta della riga")
+	}
+	require.Equal(t, "New York City", o.Items[0].Taxes[1].Name)
+	require.Equal(t, 1.35, o.Items[0].Taxes[1].Amount)
+
+	detail, err := svc.GetOrderByID(context.Background(), o.ID)
+	require.NoError(t, err)
+	require.Len(t, detail.Items[0].Taxes, 3)
+}
+
+func TestCreateOrder_USDestinationErrors(t *testing.T) {
+	svc := newSalesTaxService(t)
+	_, err := svc.CreateOrder(context.Background(), "US", twoOfProd1)
+	require.ErrorIs(t, err, order.ErrInvalidDestination)
+	require.ErrorIs(t, err, tax.ErrIncompleteDestination)
+
+	_, err = svc.CreateOrder(context.Background(), "US", twoOfProd1, order.WithShippingAddress(models.Address{Region: "NY", PostalCode: "12207"}))
+	require.ErrorIs(t, err, tax.ErrPostalCodeNotFound)
+
+	// gli altri paesi non richiedono l'indirizzo di consegna
+	o, err := svc.CreateOrder(context.Background(), "DE", twoOfProd1)
+	require.NoError(t, err)
+	require.Equal(t, []models.LineTax{{TaxJurisdiction: models.TaxJurisdiction{Type: models.JurisdictionCountry, Name: "DE", Rate: 0.19}, Amount: 3.8}}, o.Items[0].Taxes)
+}
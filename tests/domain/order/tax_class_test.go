@@ -0,0 +1,57 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newCategoryService() *order.Service {
+	categories := category.NewService(repository.NewCategoryRepository("InMemory"))
+	return order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"), order.WithCategories(categories))
+}
+
+func TestCreateOrder_TaxClassFromCategory(t *testing.T) {
+	svc := newCategoryService()
+
+	o, err := svc.CreateOrder(context.Background(), "IT", []order.CreateItem{
+		{ProductID: "prod1", Quantity: 1},     // electronics, ordinaria
+		{ProductID: "novel", Quantity: 1},     // books, super ridotta
+		{ProductID: "olive-oil", Quantity: 2}, // food, ridotta
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, models.TaxClassStandard, o.Items[0].TaxClass)
+	require.Equal(t, 0.22, o.Items[0].VATRate)
+	require.Equal(t, models.TaxClassSuperReduced, o.Items[1].TaxClass)
+	require.Equal(t, 0.04, o.Items[1].VATRate)
+	require.Equal(t, 20.8, o.Items[1].VAT)
+	require.Equal(t, models.TaxClassReduced, o.Items[2].TaxClass)
+	require.Equal(t, 0.10, o.Items[2].VATRate)
+	require.Equal(t, 44.0, o.Items[2].VAT)
+
+	// IVA = 2.20 + 0.80 + 4.00
+	require.InDelta(t, 7.0, o.TotalVAT, 0.0001)
+	require.InDelta(t, 77.0, o.TotalPrice, 0.0001)
+}
+
+func TestCreateOrder_TaxClassFallback(t *testing.T) {
+	svc := newCategoryService()
+
+	// la Germania non ha un'aliquota super ridotta: vale la ridotta
+	o, err := svc.CreateOrder(context.Background(), "DE", []order.CreateItem{{ProductID: "novel", Quantity: 1}})
+	require.NoError(t, err)
+	require.Equal(t, 0.07, o.Items[0].VATRate)
+
+	// senza categorie ogni prodotto è della classe ordinaria
+	plain, err := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory")).
+		CreateOrder(context.Background(), "IT", []order.CreateItem{{ProductID: "novel", Quantity: 1}})
+	require.NoError(t, err)
+	require.Equal(t, models.TaxClassStandard, plain.Items[0].TaxClass)
+	require.Equal(t, 0.22, plain.Items[0].VATRate)
+}
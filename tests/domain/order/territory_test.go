@@ -0,0 +1,61 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/internal/domain/vatid"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTerritoryService(t *testing.T, opts ...order.ServiceOption) *order.Service {
+	t.Helper()
+	territories, err := tax.LoadTerritoriesFile("../../../data/vat_territories.csv")
+	require.NoError(t, err)
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	require.NoError(t, vatRepo.ReplaceTerritories(territories))
+	return order.NewService(repository.NewOrderRepository("InMemory"), vatRepo, repository.NewProductRepository("InMemory"), opts...)
+}
+
+func TestCreateOrder_TerritoryOutsideVAT(t *testing.T) {
+	svc := newTerritoryService(t)
+	o, err := svc.CreateOrder(context.Background(), "ES", twoOfProd1,
+		order.WithShippingAddress(models.Address{City: "Santa Cruz de Tenerife", PostalCode: "38001", CountryCode: "ES"}))
+	require.NoError(t, err)
+	require.Equal(t, "Canary Islands", o.TaxTerritory)
+	require.Zero(t, o.TotalVAT)
+	require.Nil(t, o.Items[0].Taxes)
+
+	detail, err := svc.GetOrderByID(context.Background(), o.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Canary Islands", detail.TaxTerritory)
+
+	// senza indirizzo di consegna vale l'aliquota del paese
+	o, err = svc.CreateOrder(context.Background(), "ES", twoOfProd1)
+	require.NoError(t, err)
+	require.Empty(t, o.TaxTerritory)
+	require.InDelta(t, 4.2, o.TotalVAT, 0.0001)
+}
+
+func TestCreateOrder_NorthernIrelandReverseCharge(t *testing.T) {
+	svc := newTerritoryService(t, order.WithReverseCharge(vatid.NewStubVerifier("XI980780684"), "IT"))
+	belfast := order.WithShippingAddress(models.Address{City: "Belfast", PostalCode: "BT1 1AA", CountryCode: "GB"})
+
+	// un'impresa con partita IVA XI compra beni come un cliente dell'UE
+	o, err := svc.CreateOrder(context.Background(), "GB", twoOfProd1, belfast, order.WithBuyerVATID("XI980780684"))
+	require.NoError(t, err)
+	require.Equal(t, "Northern Ireland", o.TaxTerritory)
+	require.True(t, o.ReverseCharge)
+	require.Zero(t, o.TotalVAT)
+
+	// un cliente privato paga l'IVA britannica
+	o, err = svc.CreateOrder(context.Background(), "GB", twoOfProd1, belfast)
+	require.NoError(t, err)
+	require.False(t, o.ReverseCharge)
+	require.InDelta(t, 4.0, o.TotalVAT, 0.0001)
+	require.Equal(t, models.JurisdictionTerritory, o.Items[0].Taxes[0].Type)
+}
@@ -0,0 +1,71 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrder_QuantityTiers(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	prices := pricing.NewService(repository.NewPriceListRepository("InMemory"), products)
+	orders := repository.NewOrderRepository("InMemory")
+	svc := order.NewService(orders, repository.NewVatRateRepository("InMemory"), products, order.WithPricing(prices))
+
+	// prod2 costa 20, 18 da 10 unità e 16 da 50: lo scaglione vale per tutte le unità della riga
+	o, err := svc.CreateOrder(context.Background(), "IT", []order.CreateItem{
+		{ProductID: "prod2", Quantity: 9},
+		{ProductID: "prod2", Quantity: 10},
+		{ProductID: "prod2", Quantity: 50},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 20.0, o.Items[0].UnitPrice)
+	require.Equal(t, 18.0, o.Items[1].UnitPrice)
+	require.Equal(t, 16.0, o.Items[2].UnitPrice)
+	// netto 180 + 180 + 800 = 1160, IVA 22% 255.20
+	require.InDelta(t, 1415.2, o.TotalPrice, 0.0001)
+
+	// gli scaglioni del listino wholesale sostituiscono quelli del catalogo
+	ctx := pricing.NewContext(context.Background(), pricing.Buyer{Group: "wholesale"})
+	o, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{
+		{ProductID: "prod2", Quantity: 19},
+		{ProductID: "prod2", Quantity: 20},
+		{SKU: "tshirt-s-white", Quantity: 5},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 16.0, o.Items[0].UnitPrice)
+	require.Equal(t, 14.0, o.Items[1].UnitPrice)
+	require.Equal(t, "wholesale", o.Items[1].PriceList)
+	require.Equal(t, 12.0, o.Items[2].UnitPrice)
+}
+
+func TestQuoteOrder(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	orders := repository.NewOrderRepository("InMemory")
+	svc := order.NewService(orders, repository.NewVatRateRepository("InMemory"), products)
+	items := []order.CreateItem{{ProductID: "prod2", Quantity: 10}, {SKU: "tshirt-l-white", Quantity: 5}}
+
+	quote, err := svc.QuoteOrder(context.Background(), "IT", items)
+	require.NoError(t, err)
+	require.Empty(t, quote.ID)
+	require.Empty(t, quote.Status)
+	require.Equal(t, 18.0, quote.Items[0].UnitPrice)
+	// netto 180 + 75 = 255, IVA 22% 56.10
+	require.InDelta(t, 311.1, quote.TotalPrice, 0.0001)
+
+	// il preventivo non salva l'ordine né impegna lo stock: tutte le 5 unità restano ordinabili
+	saved, err := orders.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, saved)
+	o, err := svc.CreateOrder(context.Background(), "IT", items)
+	require.NoError(t, err)
+	require.NotEmpty(t, o.ID)
+	require.Equal(t, quote.TotalPrice, o.TotalPrice)
+
+	_, err = svc.QuoteOrder(context.Background(), "IT", []order.CreateItem{{SKU: "tshirt-l-white", Quantity: 1}})
+	require.ErrorIs(t, err, order.ErrOutOfStock)
+}
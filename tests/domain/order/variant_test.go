@@ -0,0 +1,76 @@
+package order
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newVariantService() (*order.Service, repository.ProductRepository) {
+	products := repository.NewProductRepository("InMemory")
+	return order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), products), products
+}
+
+func stockOf(t *testing.T, products repository.ProductRepository, sku string) int {
+	t.Helper()
+	p, err := products.GetProduct(context.Background(), sku)
+	require.NoError(t, err)
+	v, ok := p.Variant(sku)
+	require.True(t, ok)
+	return v.Stock
+}
+
+func TestCreateOrder_BySKU(t *testing.T) {
+	svc, products := newVariantService()
+	ctx := context.Background()
+
+	o, err := svc.CreateOrder(ctx, "IT", []order.CreateItem{
+		{ProductID: "tshirt", SKU: "tshirt-m-white", Quantity: 2},
+		// lo SKU può stare anche in product_id; il nero ha un prezzo proprio
+		{ProductID: "tshirt-s-black", Quantity: 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.Item{
+		ProductID: "tshirt", SKU: "tshirt-m-white", Name: "T-Shirt (M, white)", Quantity: 2, UnitPrice: 15, VATRate: 0.22, TaxClass: models.TaxClassStandard, VAT: 36.6,
+		Taxes:     []models.LineTax{{TaxJurisdiction: models.TaxJurisdiction{Type: models.JurisdictionCountry, Name: "IT", Rate: 0.22}, Amount: 6.6}},
+		PriceMode: models.PriceModeNet, UnitPriceWithVAT: 18.3,
+	}, o.Items[0])
+	require.Equal(t, "tshirt-s-black", o.Items[1].SKU)
+	require.Equal(t, 17.0, o.Items[1].UnitPrice)
+	require.Equal(t, 8, stockOf(t, products, "tshirt-m-white"))
+	require.Equal(t, 7, stockOf(t, products, "tshirt-s-black"))
+
+	detail, err := svc.GetOrderByID(ctx, o.ID)
+	require.NoError(t, err)
+	require.Equal(t, "tshirt-m-white", detail.Items[0].SKU)
+	require.Equal(t, "T-Shirt (M, white)", detail.Items[0].Name)
+
+	// l'annullamento restituisce le unità alla disponibilità
+	_, err = svc.CancelOrder(ctx, o.ID)
+	require.NoError(t, err)
+	require.Equal(t, 10, stockOf(t, products, "tshirt-m-white"))
+	require.Equal(t, 8, stockOf(t, products, "tshirt-s-black"))
+}
+
+func TestCreateOrder_VariantErrors(t *testing.T) {
+	svc, products := newVariantService()
+	ctx := context.Background()
+
+	_, err := svc.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "tshirt", Quantity: 1}})
+	require.Equal(t, order.ErrVariantRequired, err)
+
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", SKU: "tshirt-m-white", Quantity: 1}})
+	require.Equal(t, order.ErrProductNotFound, err)
+
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{SKU: "tshirt-m-black", Quantity: 1}})
+	require.Equal(t, order.ErrOutOfStock, err)
+
+	// una riga senza disponibilità non prenota nemmeno le altre
+	_, err = svc.CreateOrder(ctx, "IT", []order.CreateItem{{SKU: "tshirt-l-white", Quantity: 3}, {SKU: "tshirt-l-white", Quantity: 3}})
+	require.Equal(t, order.ErrOutOfStock, err)
+	require.Equal(t, 5, stockOf(t, products, "tshirt-l-white"))
+}
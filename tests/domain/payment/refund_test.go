@@ -0,0 +1,269 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/bundle"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/payment"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// paidOrder crea e paga un ordine con le righe indicate
+func (f *fixture) paidOrder(t *testing.T, countryCode string, items ...order.CreateItem) (*models.Order, *models.PaymentIntent) {
+	t.Helper()
+	o, err := f.orders.CreateOrder(context.Background(), countryCode, items)
+	require.NoError(t, err)
+	intent, err := f.payments.StartPayment(context.Background(), o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.NoError(t, err)
+	return o, intent
+}
+
+// refundOf rimborsa quantity unità della riga line dell'ordine, contata da 1
+func refundOf(line, quantity int) payment.RefundInput {
+	return payment.RefundInput{Lines: []payment.RefundLine{{Line: line, Quantity: quantity}}}
+}
+
+func TestRefund_PartialLine(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	o, intent := f.paidOrder(t, "IT", order.CreateItem{ProductID: "prod1", Quantity: 2}, order.CreateItem{ProductID: "prod2", Quantity: 1})
+	require.Equal(t, 0.22, o.Items[0].VATRate)
+
+	note, err := f.payments.Refund(ctx, o.ID, payment.RefundInput{
+		Lines:  []payment.RefundLine{{Line: 1, Quantity: 1}},
+		Reason: "damaged",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "CN-000001", note.Number)
+	require.Equal(t, o.ID, note.OrderID)
+	require.Equal(t, intent.ID, note.PaymentID)
+	require.Equal(t, "damaged", note.Reason)
+	require.NotEmpty(t, note.RefundID)
+	require.Len(t, note.Lines, 1)
+	require.Equal(t, models.CreditNoteLine{
+		Line: 1, ProductID: "prod1", Name: "Product 1", Quantity: 1, UnitPrice: 10, VATRate: 0.22, Net: 10, VAT: 2.20, Total: 12.20,
+	}, note.Lines[0])
+	require.Equal(t, 12.20, note.Total)
+
+	got, err := f.payments.GetPayment(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, 12.20, got.RefundedAmount)
+	require.Equal(t, models.PaymentStatusCaptured, got.Status)
+}
+
+// l'aliquota è quella della vendita, anche se il catalogo cambia dopo
+func TestRefund_UsesSaleTimeValues(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	o, _ := f.paidOrder(t, "DE", order.CreateItem{ProductID: "prod1", Quantity: 2})
+
+	_, err := product.NewService(f.products, repository.NewVatRateRepository("InMemory")).UpdatePrice(ctx, "prod1", 99, "")
+	require.NoError(t, err)
+
+	note, err := f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, 10.0, note.Lines[0].UnitPrice)
+	require.Equal(t, 0.19, note.Lines[0].VATRate)
+	require.Equal(t, 1.90, note.TotalVAT)
+}
+
+// i rimborsi parziali arrotondano l'IVA; l'ultimo restituisce il resto esatto della riga
+func TestRefund_RoundingLeavesNoRemainder(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, err := product.NewService(f.products, repository.NewVatRateRepository("InMemory")).UpdatePrice(ctx, "prod1", 3.33, "")
+	require.NoError(t, err)
+	o, intent := f.paidOrder(t, "IT", order.CreateItem{ProductID: "prod1", Quantity: 3})
+	require.Equal(t, 12.19, o.TotalPrice)
+
+	var vats, totals []float64
+	for i := 0; i < 3; i++ {
+		note, err := f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+		require.NoError(t, err)
+		vats = append(vats, note.TotalVAT)
+		totals = append(totals, note.Total)
+	}
+	require.Equal(t, []float64{0.73, 0.73, 0.74}, vats)
+	require.Equal(t, []float64{4.06, 4.06, 4.07}, totals)
+
+	got, err := f.payments.GetPayment(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusRefunded, got.Status)
+	require.Equal(t, 12.19, got.RefundedAmount)
+
+	notes, err := f.payments.GetCreditNotes(ctx, o.ID)
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+	require.Equal(t, "CN-000003", notes[2].Number)
+
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.Equal(t, payment.ErrNotRefundable, err)
+}
+
+// le righe con lo stesso prodotto si rimborsano ciascuna dalla propria posizione
+func TestRefund_RepeatedProductLines(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	o, _ := f.paidOrder(t, "IT", order.CreateItem{ProductID: "prod1", Quantity: 1}, order.CreateItem{ProductID: "prod1", Quantity: 2})
+
+	note, err := f.payments.Refund(ctx, o.ID, refundOf(2, 2))
+	require.NoError(t, err)
+	require.Len(t, note.Lines, 1)
+	require.Equal(t, 2, note.Lines[0].Line)
+	require.Equal(t, 2, note.Lines[0].Quantity)
+
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.Equal(t, payment.ErrRefundExceedsQuantity, err)
+	note, err = f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, 12.20, note.Total)
+}
+
+// le varianti dello stesso prodotto restano distinte, anche nelle note successive
+func TestRefund_VariantLine(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	o, _ := f.paidOrder(t, "IT", order.CreateItem{SKU: "tshirt-m-white", Quantity: 1}, order.CreateItem{SKU: "tshirt-s-black", Quantity: 1})
+
+	note, err := f.payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.NoError(t, err)
+	require.Equal(t, "tshirt", note.Lines[0].ProductID)
+	require.Equal(t, "tshirt-s-black", note.Lines[0].SKU)
+	require.Equal(t, 17.0, note.Lines[0].UnitPrice)
+
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.Equal(t, payment.ErrRefundExceedsQuantity, err)
+	note, err = f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, "tshirt-m-white", note.Lines[0].SKU)
+	require.Equal(t, 15.0, note.Lines[0].UnitPrice)
+}
+
+// un prodotto venduto sia da solo sia in un bundle si rimborsa sulla riga indicata
+func TestRefund_BundleComponentLine(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), f.products,
+		order.WithBundles(bundle.NewService(repository.NewBundleRepository("InMemory"), f.products)))
+	payments := payment.NewService(repository.NewPaymentRepository("InMemory"), repository.NewCreditNoteRepository("InMemory"), orders, f.gateway, "EUR")
+	o, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}, {BundleID: "starter-kit", Quantity: 1}})
+	require.NoError(t, err)
+	require.Equal(t, "prod1", o.Items[1].ProductID)
+	require.NotNil(t, o.Items[1].Bundle)
+	_, err = payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.NoError(t, err)
+
+	// il componente del kit ha il prezzo ripartito, la riga singola quello di listino
+	note, err := payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.NoError(t, err)
+	require.Equal(t, 9.0, note.Lines[0].UnitPrice)
+	note, err = payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, 10.0, note.Lines[0].UnitPrice)
+	_, err = payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.Equal(t, payment.ErrRefundExceedsQuantity, err)
+}
+
+func TestRefund_Guards(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	unpaid := f.createOrder(t)
+	_, err := f.payments.Refund(ctx, unpaid.ID, refundOf(1, 1))
+	require.Equal(t, payment.ErrNotRefundable, err)
+	_, err = f.payments.Refund(ctx, "missing", refundOf(1, 1))
+	require.Equal(t, payment.ErrOrderNotFound, err)
+
+	o, intent := f.paidOrder(t, "IT", order.CreateItem{ProductID: "prod1", Quantity: 2})
+	_, err = f.payments.Refund(ctx, o.ID, payment.RefundInput{})
+	require.Equal(t, payment.ErrInvalidRefund, err)
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(2, 1))
+	require.Equal(t, payment.ErrInvalidRefund, err)
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(0, 1))
+	require.Equal(t, payment.ErrInvalidRefund, err)
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(1, 0))
+	require.Equal(t, payment.ErrInvalidRefund, err)
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(1, 3))
+	require.Equal(t, payment.ErrRefundExceedsQuantity, err)
+
+	// rimborso rifiutato dal gateway: nessuna nota di credito, importo invariato
+	f.gateway.Script(payment.OpRefund, payment.Decline("processing_error"))
+	_, err = f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.ErrorIs(t, err, payment.ErrDeclined)
+	notes, err := f.payments.GetCreditNotes(ctx, o.ID)
+	require.NoError(t, err)
+	require.Empty(t, notes)
+	got, err := f.payments.GetPayment(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Zero(t, got.RefundedAmount)
+	// il rimborso fallito non consuma numeri né quantità
+	note, err := f.payments.Refund(ctx, o.ID, refundOf(1, 2))
+	require.NoError(t, err)
+	require.Equal(t, "CN-000001", note.Number)
+
+	_, err = f.payments.GetCreditNote(ctx, o.ID, "CN-999999")
+	require.Equal(t, payment.ErrCreditNoteNotFound, err)
+}
+
+// issueFailingNotes non riesce a registrare l'emissione delle note di credito
+type issueFailingNotes struct {
+	repository.CreditNoteRepository
+}
+
+func (r issueFailingNotes) Update(ctx context.Context, note *models.CreditNote) error {
+	if note.Status == models.CreditNoteStatusIssued {
+		return errors.New("store unavailable")
+	}
+	return r.CreditNoteRepository.Update(ctx, note)
+}
+
+// un rimborso eseguito ma non registrato resta in sospeso e non può essere ripetuto
+func TestRefund_PendingNoteWhenIssueFails(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	notes := issueFailingNotes{repository.NewCreditNoteRepository("InMemory")}
+	payments := payment.NewService(repository.NewPaymentRepository("InMemory"), notes, f.orders, f.gateway, "EUR")
+	o, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+	_, err = payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.NoError(t, err)
+
+	_, err = payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.Error(t, err)
+	require.Equal(t, 1, f.gateway.Calls(payment.OpRefund))
+	saved, err := notes.GetByOrderID(ctx, o.ID)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, models.CreditNoteStatusPending, saved[0].Status)
+	require.Equal(t, 12.20, saved[0].Total)
+
+	_, err = payments.Refund(ctx, o.ID, refundOf(1, 1))
+	require.Equal(t, payment.ErrRefundExceedsQuantity, err)
+	require.Equal(t, 1, f.gateway.Calls(payment.OpRefund))
+}
+
+// con il prezzo IVA inclusa il cliente riceve il prezzo pagato per unità
+func TestRefund_GrossPrice(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, err := product.NewService(f.products, repository.NewVatRateRepository("InMemory")).UpdatePrice(ctx, "prod1", 3.33, models.PriceModeGross)
+	require.NoError(t, err)
+	o, _ := f.paidOrder(t, "IT", order.CreateItem{ProductID: "prod1", Quantity: 3})
+	require.Equal(t, 9.99, o.TotalPrice)
+	require.Equal(t, 1.80, o.TotalVAT)
+
+	var vat float64
+	for i := 0; i < 3; i++ {
+		note, err := f.payments.Refund(ctx, o.ID, refundOf(1, 1))
+		require.NoError(t, err)
+		require.Equal(t, 3.33, note.Total)
+		vat += note.TotalVAT
+	}
+	require.InDelta(t, 1.80, vat, 1e-9)
+}
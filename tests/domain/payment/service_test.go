@@ -0,0 +1,309 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/payment"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+	products repository.ProductRepository
+	orders   *order.Service
+	payments *payment.Service
+	gateway  *payment.FakeGateway
+}
+
+func newFixture() *fixture {
+	products := repository.NewProductRepository("InMemory")
+	orders := order.NewService(repository.NewOrderRepository("InMemory"), repository.NewVatRateRepository("InMemory"), products)
+	gateway := payment.NewFakeGateway()
+	return &fixture{
+		products: products,
+		orders:   orders,
+		payments: payment.NewService(repository.NewPaymentRepository("InMemory"), repository.NewCreditNoteRepository("InMemory"), orders, gateway, "EUR"),
+		gateway:  gateway,
+	}
+}
+
+func (f *fixture) createOrder(t *testing.T) *models.Order {
+	t.Helper()
+	o, err := f.orders.CreateOrder(context.Background(), "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 2}})
+	require.NoError(t, err)
+	return o
+}
+
+func (f *fixture) orderStatus(t *testing.T, id string) string {
+	t.Helper()
+	d, err := f.orders.GetOrderByID(context.Background(), id)
+	require.NoError(t, err)
+	return d.Status
+}
+
+func TestStartPayment_AuthorizeAndCapture(t *testing.T) {
+	f := newFixture()
+	o := f.createOrder(t)
+
+	intent, err := f.payments.StartPayment(context.Background(), o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusCaptured, intent.Status)
+	require.Equal(t, 24.40, intent.Amount)
+	require.Equal(t, 24.40, intent.CapturedAmount)
+	require.Equal(t, "EUR", intent.Currency)
+	require.NotEmpty(t, intent.AuthorizationID)
+	require.NotEmpty(t, intent.CaptureID)
+	require.Equal(t, models.OrderStatusPaid, f.orderStatus(t, o.ID))
+
+	// un ordine pagato non può essere pagato di nuovo né annullato
+	_, err = f.payments.StartPayment(context.Background(), o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.Equal(t, payment.ErrOrderNotPayable, err)
+	_, err = f.orders.CancelOrder(context.Background(), o.ID)
+	require.Equal(t, order.ErrInvalidStatusTransition, err)
+}
+
+func TestStartPayment_AuthorizeThenCaptureLater(t *testing.T) {
+	f := newFixture()
+	o := f.createOrder(t)
+	ctx := context.Background()
+
+	intent, err := f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusAuthorized, intent.Status)
+	require.Equal(t, models.OrderStatusCreated, f.orderStatus(t, o.ID), "l'autorizzazione non cambia lo stato")
+
+	// una seconda autorizzazione mentre la prima è aperta è rifiutata
+	_, err = f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.Equal(t, payment.ErrOrderNotPayable, err)
+
+	captured, err := f.payments.Capture(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusCaptured, captured.Status)
+	require.Equal(t, models.OrderStatusPaid, f.orderStatus(t, o.ID))
+
+	_, err = f.payments.Capture(ctx, o.ID, intent.ID)
+	require.Equal(t, payment.ErrInvalidPaymentState, err)
+}
+
+func TestStartPayment_DeclinedThenRetried(t *testing.T) {
+	f := newFixture()
+	o := f.createOrder(t)
+	ctx := context.Background()
+	f.gateway.Script(payment.OpAuthorize, payment.Decline("insufficient_funds"))
+
+	intent, err := f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.ErrorIs(t, err, payment.ErrDeclined)
+	require.NotNil(t, intent)
+	require.Equal(t, models.PaymentStatusFailed, intent.Status)
+	require.Equal(t, "insufficient_funds", intent.FailureReason)
+	require.Equal(t, models.OrderStatusCreated, f.orderStatus(t, o.ID))
+
+	// il secondo tentativo usa l'esito di default (approvato)
+	intent, err = f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusCaptured, intent.Status)
+
+	intents, err := f.payments.GetPayments(ctx, o.ID)
+	require.NoError(t, err)
+	require.Len(t, intents, 2)
+	require.Equal(t, 2, f.gateway.Calls(payment.OpAuthorize))
+}
+
+func TestStartPayment_GatewayFailure(t *testing.T) {
+	f := newFixture()
+	o := f.createOrder(t)
+	f.gateway.Script(payment.OpAuthorize, payment.Fail(errors.New("timeout")))
+
+	intent, err := f.payments.StartPayment(context.Background(), o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.Nil(t, intent)
+	var gatewayErr *payment.GatewayError
+	require.ErrorAs(t, err, &gatewayErr)
+	require.Equal(t, payment.OpAuthorize, gatewayErr.Op)
+}
+
+func TestCapture_Declined(t *testing.T) {
+	f := newFixture()
+	o := f.createOrder(t)
+	ctx := context.Background()
+	f.gateway.Script(payment.OpCapture, payment.Decline("expired_authorization"))
+
+	intent, err := f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.ErrorIs(t, err, payment.ErrDeclined)
+	require.Equal(t, models.PaymentStatusFailed, intent.Status)
+	require.Equal(t, "expired_authorization", intent.FailureReason)
+	require.Equal(t, models.OrderStatusCreated, f.orderStatus(t, o.ID))
+}
+
+// un'autorizzazione non ancora annullata di un ordine annullato non si incassa
+func TestCapture_CancelledOrder(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	o := f.createOrder(t)
+	intent, err := f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	_, err = f.orders.CancelOrder(ctx, o.ID)
+	require.NoError(t, err)
+
+	captured, err := f.payments.Capture(ctx, o.ID, intent.ID)
+	require.Equal(t, payment.ErrOrderNotPayable, err)
+	require.Nil(t, captured)
+	require.Zero(t, f.gateway.Calls(payment.OpCapture))
+	got, err := f.payments.GetPayment(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusAuthorized, got.Status)
+}
+
+// slowCapture trattiene l'incasso finché il test non lo rilascia
+type slowCapture struct {
+	*payment.FakeGateway
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (g slowCapture) Capture(ctx context.Context, authorizationID string, amount float64) (string, error) {
+	close(g.entered)
+	<-g.release
+	return g.FakeGateway.Capture(ctx, authorizationID, amount)
+}
+
+// un annullamento arrivato durante l'incasso attende il pagamento e poi è rifiutato
+func TestCapture_ConcurrentCancel(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	gateway := slowCapture{FakeGateway: f.gateway, entered: make(chan struct{}), release: make(chan struct{})}
+	payments := payment.NewService(repository.NewPaymentRepository("InMemory"), repository.NewCreditNoteRepository("InMemory"), f.orders, gateway, "EUR")
+	o := f.createOrder(t)
+	intent, err := payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+
+	captured := make(chan error, 1)
+	go func() {
+		_, err := payments.Capture(ctx, o.ID, intent.ID)
+		captured <- err
+	}()
+	<-gateway.entered
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := f.orders.CancelOrder(ctx, o.ID)
+		cancelled <- err
+	}()
+	select {
+	case err := <-cancelled:
+		t.Fatalf("annullamento concluso durante l'incasso: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(gateway.release)
+
+	require.NoError(t, <-captured)
+	require.Equal(t, order.ErrInvalidStatusTransition, <-cancelled)
+	require.Equal(t, models.OrderStatusPaid, f.orderStatus(t, o.ID))
+	require.Zero(t, f.gateway.Calls(payment.OpRefund))
+}
+
+// paidFailingOrders non riesce a registrare il pagamento degli ordini
+type paidFailingOrders struct {
+	repository.OrderRepository
+}
+
+func (r paidFailingOrders) Update(ctx context.Context, o *models.Order, outbox ...*models.OutboxMessage) error {
+	if o.Status == models.OrderStatusPaid {
+		return errors.New("store unavailable")
+	}
+	return r.OrderRepository.Update(ctx, o, outbox...)
+}
+
+// un incasso che non si riesce ad associare all'ordine viene rimborsato
+func TestCapture_RefundedWhenOrderNotMarkedPaid(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	orders := order.NewService(paidFailingOrders{repository.NewOrderRepository("InMemory")}, repository.NewVatRateRepository("InMemory"), f.products)
+	payments := payment.NewService(repository.NewPaymentRepository("InMemory"), repository.NewCreditNoteRepository("InMemory"), orders, f.gateway, "EUR")
+	o, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 2}})
+	require.NoError(t, err)
+
+	intent, err := payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa", Capture: true})
+	require.EqualError(t, err, "store unavailable")
+	require.Nil(t, intent)
+	require.Equal(t, 1, f.gateway.Calls(payment.OpRefund))
+
+	intents, err := payments.GetPayments(ctx, o.ID)
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+	require.Equal(t, models.PaymentStatusRefunded, intents[0].Status)
+	require.Equal(t, 24.40, intents[0].RefundedAmount)
+	detail, err := orders.GetOrderByID(ctx, o.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.OrderStatusCreated, detail.Status)
+}
+
+func TestVoid_AndCancelledOrderVoidsAuthorization(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	o := f.createOrder(t)
+	intent, err := f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	voided, err := f.payments.Void(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusVoided, voided.Status)
+	_, err = f.payments.Capture(ctx, o.ID, intent.ID)
+	require.Equal(t, payment.ErrInvalidPaymentState, err)
+
+	o = f.createOrder(t)
+	intent, err = f.payments.StartPayment(ctx, o.ID, payment.StartInput{PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	_, err = f.orders.CancelOrder(ctx, o.ID)
+	require.NoError(t, err)
+	err = f.payments.HandleOrderEvent(ctx, events.Envelope{ID: "evt", Event: events.OrderCancelled{Order: events.Order{ID: o.ID}}})
+	require.NoError(t, err)
+	got, err := f.payments.GetPayment(ctx, o.ID, intent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusVoided, got.Status)
+	require.Equal(t, 2, f.gateway.Calls(payment.OpVoid))
+}
+
+func TestStartPayment_Errors(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, err := f.payments.StartPayment(ctx, "missing", payment.StartInput{PaymentMethod: "tok_visa"})
+	require.Equal(t, payment.ErrOrderNotFound, err)
+
+	o := f.createOrder(t)
+	_, err = f.payments.StartPayment(ctx, o.ID, payment.StartInput{})
+	require.Equal(t, payment.ErrInvalidPaymentMethod, err)
+	_, err = f.payments.GetPayment(ctx, "other-order", "missing")
+	require.Equal(t, payment.ErrPaymentNotFound, err)
+}
+
+func TestFakeGateway_ChecksAmounts(t *testing.T) {
+	g := payment.NewFakeGateway()
+	ctx := context.Background()
+	auth, err := g.Authorize(ctx, payment.AuthorizeRequest{IdempotencyKey: "k1", Amount: 10, PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	again, err := g.Authorize(ctx, payment.AuthorizeRequest{IdempotencyKey: "k1", Amount: 10, PaymentMethod: "tok_visa"})
+	require.NoError(t, err)
+	require.Equal(t, auth, again, "stessa chiave di idempotenza, stessa autorizzazione")
+
+	_, err = g.Capture(ctx, auth, 10.01)
+	require.ErrorIs(t, err, payment.ErrDeclined)
+	capture, err := g.Capture(ctx, auth, 10)
+	require.NoError(t, err)
+	require.ErrorIs(t, g.Void(ctx, auth), payment.ErrDeclined)
+
+	_, err = g.Refund(ctx, capture, 6)
+	require.NoError(t, err)
+	_, err = g.Refund(ctx, capture, 4.01)
+	require.ErrorIs(t, err, payment.ErrDeclined)
+	_, err = g.Refund(ctx, capture, 4)
+	require.NoError(t, err)
+
+	_, err = g.Authorize(ctx, payment.AuthorizeRequest{Amount: 5, PaymentMethod: payment.TokenDeclined})
+	require.ErrorIs(t, err, payment.ErrDeclined)
+}
@@ -0,0 +1,147 @@
+package pricing
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/pricing"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	march = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	april = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newService(t *testing.T, now time.Time, lists ...models.PriceList) *pricing.Service {
+	t.Helper()
+	repo := repository.NewPriceListRepository("InMemory")
+	svc := pricing.NewService(repo, repository.NewProductRepository("InMemory"), pricing.WithClock(func() time.Time { return now }))
+	for _, l := range lists {
+		require.NoError(t, svc.Save(context.Background(), &l))
+	}
+	return svc
+}
+
+func buyer(client, group string) context.Context {
+	return pricing.NewContext(context.Background(), pricing.Buyer{Client: client, Group: group})
+}
+
+func TestResolve(t *testing.T) {
+	svc := newService(t, march,
+		models.PriceList{ID: "gold", Name: "Gold", CustomerGroups: []string{"wholesale"}, Prices: []models.ListPrice{
+			{ProductID: "prod2", Price: 15},
+			{ProductID: "prod3", Price: 18, ValidFrom: april},
+		}},
+		models.PriceList{ID: "beta", Name: "Beta", Clients: []string{"beta"}, Prices: []models.ListPrice{
+			{ProductID: "prod2", Price: 19},
+			{ProductID: "tshirt-l-white", Price: 11, ValidTo: april},
+		}},
+	)
+	prod2 := models.Product{ID: "prod2", Price: 20}
+	prod3 := models.Product{ID: "prod3", Price: 20}
+	tshirt := models.Product{ID: "tshirt", Price: 15}
+	black := models.Variant{SKU: "tshirt-s-black", Price: 17}
+	white := models.Variant{SKU: "tshirt-l-white"}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		product   models.Product
+		variant   models.Variant
+		wantPrice float64
+		wantList  string
+	}{
+		{"senza cliente vale il catalogo", context.Background(), prod2, models.Variant{}, 20, ""},
+		{"cliente sconosciuto", buyer("nobody", ""), prod2, models.Variant{}, 20, ""},
+		{"listino del gruppo, il più basso", buyer("", "wholesale"), prod2, models.Variant{}, 15, "gold"},
+		{"il listino del cliente prevale su quelli del gruppo", buyer("beta", "wholesale"), prod2, models.Variant{}, 19, "beta"},
+		{"prezzo non ancora valido", buyer("", "wholesale"), prod3, models.Variant{}, 20, ""},
+		{"prezzo del prodotto per la variante", buyer("", "wholesale"), tshirt, black, 12, "wholesale"},
+		{"prezzo dello SKU", buyer("beta", "wholesale"), tshirt, white, 11, "beta"},
+		{"catalogo della variante", buyer("beta", ""), tshirt, black, 17, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := svc.Resolve(tt.ctx, tt.product, tt.variant)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPrice, price.Amount)
+			require.Equal(t, tt.wantList, price.List)
+		})
+	}
+
+	// da aprile il prezzo dello SKU è scaduto e quello del gruppo è valido
+	later := newService(t, april, models.PriceList{ID: "gold", Name: "Gold", CustomerGroups: []string{"wholesale"}, Prices: []models.ListPrice{
+		{ProductID: "prod3", Price: 18, ValidFrom: april},
+	}})
+	price, err := later.Resolve(buyer("", "wholesale"), prod3, models.Variant{})
+	require.NoError(t, err)
+	require.Equal(t, 18.0, price.Amount)
+	require.Equal(t, "gold", price.List)
+
+	// senza servizio vale sempre il catalogo
+	var none *pricing.Service
+	price, err = none.Resolve(buyer("beta", ""), prod2, models.Variant{})
+	require.NoError(t, err)
+	require.Equal(t, 20.0, price.Amount)
+	require.Empty(t, price.List)
+}
+
+func TestResolve_Tiers(t *testing.T) {
+	svc := newService(t, march, models.PriceList{ID: "gold", Name: "Gold", CustomerGroups: []string{"gold"}, Prices: []models.ListPrice{
+		{ProductID: "prod2", Price: 15, Tiers: []models.PriceTier{{MinQuantity: 5, Price: 13}}},
+	}})
+	prod2 := models.Product{ID: "prod2", Price: 20, Tiers: []models.PriceTier{{MinQuantity: 10, Price: 18}, {MinQuantity: 50, Price: 16}}}
+	tshirt := models.Product{ID: "tshirt", Price: 15, Tiers: []models.PriceTier{{MinQuantity: 10, Price: 12}}}
+
+	// gli scaglioni del catalogo: il prezzo dello scaglione più alto raggiunto vale per tutte le unità
+	catalog, err := svc.Resolve(context.Background(), prod2, models.Variant{})
+	require.NoError(t, err)
+	for quantity, want := range map[int]float64{1: 20, 9: 20, 10: 18, 49: 18, 50: 16, 500: 16} {
+		require.Equal(t, want, catalog.At(quantity), "quantità %d", quantity)
+	}
+
+	// il listino sostituisce gli scaglioni del catalogo con i propri
+	listed, err := svc.Resolve(buyer("", "gold"), prod2, models.Variant{})
+	require.NoError(t, err)
+	require.Equal(t, "gold", listed.List)
+	require.Equal(t, 15.0, listed.At(4))
+	require.Equal(t, 13.0, listed.At(60))
+
+	// una variante con un prezzo proprio non eredita gli scaglioni del prodotto
+	white, err := svc.Resolve(context.Background(), tshirt, models.Variant{SKU: "tshirt-l-white"})
+	require.NoError(t, err)
+	require.Equal(t, 12.0, white.At(10))
+	black, err := svc.Resolve(context.Background(), tshirt, models.Variant{SKU: "tshirt-s-black", Price: 17})
+	require.NoError(t, err)
+	require.Empty(t, black.Tiers)
+	require.Equal(t, 17.0, black.At(10))
+}
+
+func TestSave_Invalid(t *testing.T) {
+	svc := newService(t, march)
+	ctx := context.Background()
+
+	for name, list := range map[string]models.PriceList{
+		"senza nome":           {ID: "x"},
+		"prezzo nullo":         {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "prod1"}}},
+		"periodo al contrario": {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "prod1", Price: 5, ValidFrom: april, ValidTo: march}}},
+		"prodotto sconosciuto": {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "nope", Price: 5}}},
+		"scaglione da 1":       {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "prod1", Price: 5, Tiers: []models.PriceTier{{MinQuantity: 1, Price: 4}}}}},
+		"scaglioni non crescenti": {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "prod1", Price: 5, Tiers: []models.PriceTier{
+			{MinQuantity: 10, Price: 4}, {MinQuantity: 10, Price: 3},
+		}}}},
+		"scaglione senza prezzo": {ID: "x", Name: "X", Prices: []models.ListPrice{{ProductID: "prod1", Price: 5, Tiers: []models.PriceTier{{MinQuantity: 10}}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, svc.Save(ctx, &list), pricing.ErrInvalidPriceList)
+		})
+	}
+
+	lists, err := svc.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"acme-2026", "wholesale"}, []string{lists[0].ID, lists[1].ID})
+}
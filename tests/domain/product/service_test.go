@@ -2,9 +2,14 @@ package product
 
 import (
 	"context"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/pricing"
 	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/models"
 	"purchase-cart-service/repository"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 var svc *product.Service
@@ -44,3 +49,133 @@ func TestProduct_GetByID(t *testing.T) {
 		t.Fatalf("Prodotto non trovato o ID non combacia, got=%v want=%s", p, id)
 	}
 }
+
+func TestProduct_PriceModes(t *testing.T) {
+	ctx := context.Background()
+	gross := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"), product.WithPriceMode(models.PriceModeGross))
+
+	// catalogo IVA inclusa: 10.00 al 22% sono 8.20 netti
+	p, err := gross.GetProductByID(ctx, "prod1", "IT")
+	require.NoError(t, err)
+	require.Equal(t, models.PriceModeGross, p.PriceMode)
+	require.Equal(t, 8.20, p.Price)
+	require.Equal(t, 10.0, p.PriceWithVAT)
+
+	// il prezzo del singolo prodotto può essere netto
+	_, err = gross.UpdatePrice(ctx, "prod1", 10, models.PriceModeNet)
+	require.NoError(t, err)
+	p, err = gross.GetProductByID(ctx, "prod1", "IT")
+	require.NoError(t, err)
+	require.Equal(t, models.PriceModeNet, p.PriceMode)
+	require.Equal(t, 10.0, p.Price)
+	require.Equal(t, 12.20, p.PriceWithVAT)
+
+	_, err = gross.UpdatePrice(ctx, "prod1", 10, "list")
+	require.Equal(t, product.ErrInvalidPriceMode, err)
+}
+
+func TestProduct_Variants(t *testing.T) {
+	ctx := context.Background()
+	svc := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"))
+
+	p, err := svc.GetProductByID(ctx, "tshirt", "IT")
+	require.NoError(t, err)
+	require.Equal(t, []product.VariantOption{
+		{Name: "size", Values: []string{"S", "M", "L"}},
+		{Name: "color", Values: []string{"white", "black"}},
+	}, p.Options)
+	require.Len(t, p.Variants, 5)
+	require.Equal(t, product.VariantDetail{
+		SKU: "tshirt-s-black", Attributes: map[string]string{"size": "S", "color": "black"}, Price: 17, PriceWithVAT: 20.74, Stock: 8,
+	}, p.Variants[3])
+
+	// il prezzo di uno SKU si cambia senza toccare il prodotto e le altre varianti
+	_, err = svc.UpdatePrice(ctx, "tshirt-m-white", 16, "")
+	require.NoError(t, err)
+	p, err = svc.GetProductByID(ctx, "tshirt-m-white", "IT")
+	require.NoError(t, err)
+	require.Equal(t, 15.0, p.Price)
+	require.Equal(t, 15.0, p.Variants[0].Price)
+	require.Equal(t, 16.0, p.Variants[1].Price)
+
+	_, err = svc.UpdatePrice(ctx, "tshirt-m-white", 16, models.PriceModeGross)
+	require.Equal(t, product.ErrInvalidPriceMode, err)
+}
+
+func TestProduct_ByCategory(t *testing.T) {
+	ctx := context.Background()
+	categories := category.NewService(repository.NewCategoryRepository("InMemory"))
+	svc := product.NewService(repository.NewProductRepository("InMemory"), repository.NewVatRateRepository("InMemory"), product.WithCategories(categories))
+
+	// electronics comprende i prodotti delle sottocategorie
+	list, err := svc.GetProductsByCategory(ctx, "electronics", "IT")
+	require.NoError(t, err)
+	var ids []string
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	require.Equal(t, []string{"prod1", "prod2", "prod3"}, ids)
+
+	// il prezzo con IVA segue la classe della categoria
+	list, err = svc.GetProductsByCategory(ctx, "books", "IT")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, models.TaxClassSuperReduced, list[0].TaxClass)
+	require.Equal(t, 20.8, list[0].PriceWithVAT)
+
+	_, err = svc.GetProductsByCategory(ctx, "garden", "IT")
+	require.ErrorIs(t, err, category.ErrCategoryNotFound)
+}
+
+func TestProduct_PriceLists(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	prices := pricing.NewService(repository.NewPriceListRepository("InMemory"), products)
+	svc := product.NewService(products, repository.NewVatRateRepository("InMemory"), product.WithPricing(prices))
+	ctx := pricing.NewContext(context.Background(), pricing.Buyer{Group: "wholesale"})
+
+	p, err := svc.GetProductByID(ctx, "prod1", "IT")
+	require.NoError(t, err)
+	require.Equal(t, 8.0, p.Price)
+	require.Equal(t, 9.76, p.PriceWithVAT)
+	require.Equal(t, "wholesale", p.PriceList)
+
+	// il prezzo di listino del prodotto vale per tutte le varianti, anche quelle con un prezzo proprio a catalogo
+	p, err = svc.GetProductByID(ctx, "tshirt", "IT")
+	require.NoError(t, err)
+	require.Equal(t, 12.0, p.Price)
+	require.Equal(t, 12.0, p.Variants[3].Price)
+	require.Equal(t, "wholesale", p.Variants[3].PriceList)
+
+	p, err = svc.GetProductByID(context.Background(), "tshirt", "IT")
+	require.NoError(t, err)
+	require.Equal(t, 15.0, p.Price)
+	require.Equal(t, 17.0, p.Variants[3].Price)
+	require.Empty(t, p.Variants[3].PriceList)
+}
+
+func TestProduct_Tiers(t *testing.T) {
+	products := repository.NewProductRepository("InMemory")
+	prices := pricing.NewService(repository.NewPriceListRepository("InMemory"), products)
+	svc := product.NewService(products, repository.NewVatRateRepository("InMemory"), product.WithPricing(prices))
+
+	// la tabella degli scaglioni del catalogo, con e senza IVA
+	p, err := svc.GetProductByID(context.Background(), "prod2", "IT")
+	require.NoError(t, err)
+	require.Equal(t, []product.Tier{
+		{MinQuantity: 10, Price: 18, PriceWithVAT: 21.96},
+		{MinQuantity: 50, Price: 16, PriceWithVAT: 19.52},
+	}, p.Tiers)
+
+	// con il listino wholesale valgono i suoi scaglioni, anche per le varianti
+	ctx := pricing.NewContext(context.Background(), pricing.Buyer{Group: "wholesale"})
+	p, err = svc.GetProductByID(ctx, "prod2", "IT")
+	require.NoError(t, err)
+	require.Equal(t, []product.Tier{{MinQuantity: 20, Price: 14, PriceWithVAT: 17.08}}, p.Tiers)
+	p, err = svc.GetProductByID(ctx, "tshirt", "IT")
+	require.NoError(t, err)
+	require.Equal(t, []product.Tier{{MinQuantity: 24, Price: 10, PriceWithVAT: 12.2}}, p.Variants[3].Tiers)
+
+	p, err = svc.GetProductByID(context.Background(), "prod1", "IT")
+	require.NoError(t, err)
+	require.Empty(t, p.Tiers)
+}
@@ -0,0 +1,50 @@
+package rounding
+
+import (
+	"purchase-cart-service/internal/domain/rounding"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Round(t *testing.T) {
+	halfUp := rounding.Policy{Level: rounding.PerLine, Method: rounding.HalfUp}
+	halfEven := rounding.Policy{Level: rounding.PerLine, Method: rounding.HalfEven}
+	bankers := rounding.Policy{Level: rounding.PerLine, Method: rounding.Bankers}
+
+	cases := []struct {
+		amount, halfUp, halfEven float64
+	}{
+		{0.125, 0.13, 0.12},
+		{0.135, 0.14, 0.14},
+		// 1.005 * 100 è 100.49999... in virgola mobile, ma resta un pareggio
+		{1.005, 1.01, 1.00},
+		{2.675, 2.68, 2.68},
+		{0.124, 0.12, 0.12},
+		{0.126, 0.13, 0.13},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.halfUp, halfUp.Round(c.amount), "half-up %v", c.amount)
+		require.Equal(t, c.halfEven, halfEven.Round(c.amount), "half-even %v", c.amount)
+		require.Equal(t, c.halfEven, bankers.Round(c.amount), "bankers %v", c.amount)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	// 0.066 arrotondato a 0.07: il centesimo in più va al resto più grande, a parità al primo
+	require.Equal(t, []float64{0.03, 0.02, 0.02}, rounding.Spread(0.07, []float64{0.022, 0.022, 0.022}))
+	require.Equal(t, []float64{0.02, 0.03, 0.02}, rounding.Spread(0.07, []float64{0.021, 0.024, 0.022}))
+	require.Equal(t, []float64{1.10, 2.20}, rounding.Spread(3.30, []float64{1.10, 2.20}))
+	require.Empty(t, rounding.Spread(0, nil))
+}
+
+func TestPolicies_For(t *testing.T) {
+	perTotal := rounding.Policy{Level: rounding.PerTotal, Method: rounding.HalfEven}
+	policies := rounding.Policies{Countries: map[string]rounding.Policy{"DE": perTotal}}
+	require.Equal(t, perTotal, policies.For("de"))
+	require.Equal(t, rounding.Default, policies.For("IT"))
+
+	perUnit := rounding.Policy{Level: rounding.PerUnit, Method: rounding.HalfUp}
+	policies.Default = perUnit
+	require.Equal(t, perUnit, policies.For("IT"))
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"purchase-cart-service/internal/domain/search"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ids(matches []search.Match) []string {
+	out := []string{}
+	for _, m := range matches {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func TestIndex_Languages(t *testing.T) {
+	index := search.NewIndex([]search.Document{
+		{ID: "book", Fields: []search.Field{{Text: "Il libro dell'anno", Weight: 1}}},
+		{ID: "shirt", Fields: []search.Field{{Text: "Maglietta in cotone", Weight: 1}}},
+		{ID: "case", Fields: []search.Field{{Text: "Leather phone cases", Weight: 1}}},
+		{ID: "bag", Fields: []search.Field{{Text: "Borsa con tasche", Weight: 1}}},
+		{ID: "coffee", Fields: []search.Field{{Text: "Caffè della mattina", Weight: 1}}},
+		{ID: "kids", Fields: []search.Field{{Text: "The children's batteries", Weight: 1}}},
+	})
+	require.Equal(t, 6, index.Len())
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"libri", []string{"book"}},             // plurale italiano
+		{"anno", []string{"book"}},              // l'elisione non nasconde la parola
+		{"magliette cotone", []string{"shirt"}}, // tutte le parole devono esserci
+		{"case", []string{"case"}},              // plurale inglese
+		{"tasca", []string{"bag"}},              // tasche -> tasc
+		{"caffe", []string{"coffee"}},           // senza accento
+		{"CAFFÈ", []string{"coffee"}},
+		{"children battery", []string{"kids"}},
+		{"il della the", []string{}}, // solo parole vuote
+		{"maglietta pelle", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			require.Equal(t, tt.want, ids(index.Match(tt.query)))
+		})
+	}
+}
+
+func TestIndex_Prefix(t *testing.T) {
+	index := search.NewIndex([]search.Document{
+		{ID: "charger", Fields: []search.Field{{Text: "USB charger", Weight: 1}}},
+		{ID: "cable", Fields: []search.Field{{Text: "USB cable", Weight: 1}}},
+		{ID: "chair", Fields: []search.Field{{Text: "Office chair", Weight: 1}}},
+	})
+
+	require.Equal(t, []string{"chair", "charger"}, ids(index.Match("cha")))
+	require.Equal(t, []string{"charger"}, ids(index.Match("usb charg")))
+	// una sola lettera non vale come prefisso
+	require.Empty(t, index.Match("c"))
+
+	// la parola intera pesa più di un prefisso
+	matches := index.Match("chair")
+	require.Equal(t, []string{"chair"}, ids(matches))
+	prefix := index.Match("chai")
+	require.Greater(t, matches[0].Score, prefix[0].Score)
+}
+
+func TestIndex_Relevance(t *testing.T) {
+	index := search.NewIndex([]search.Document{
+		{ID: "a", Fields: []search.Field{{Text: "Notebook", Weight: 1}, {Text: "Paper notebook with lamp", Weight: 1}}},
+		{ID: "b", Fields: []search.Field{{Text: "Lamp", Weight: 3}, {Text: "Desk lamp", Weight: 1}}},
+		{ID: "c", Fields: []search.Field{{Text: "Desk", Weight: 3}, {Text: "Desk with a lamp", Weight: 1}}},
+	})
+
+	// il campo più pesante vince; a parità di punteggio conta l'ID
+	require.Equal(t, []string{"b", "a", "c"}, ids(index.Match("lamp")))
+	// desk è più rara di lamp: c, con desk nel nome, precede b
+	require.Equal(t, []string{"c", "b"}, ids(index.Match("lamp desk")))
+}
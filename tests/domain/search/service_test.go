@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/category"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/domain/search"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newService() (*search.Service, repository.ProductRepository) {
+	products := repository.NewProductRepository("InMemory")
+	categories := category.NewService(repository.NewCategoryRepository("InMemory"))
+	details := product.NewService(products, repository.NewVatRateRepository("InMemory"), product.WithCategories(categories))
+	return search.NewService(products, details, categories), products
+}
+
+func hitIDs(result *search.Result) []string {
+	out := []string{}
+	for _, h := range result.Hits {
+		out = append(out, h.ID)
+	}
+	return out
+}
+
+func TestSearch_Facets(t *testing.T) {
+	svc, _ := newService()
+	ctx := context.Background()
+
+	// "product" è nel nome di tutti i prodotti numerati
+	result, err := svc.Search(ctx, search.Query{Text: "product", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Total)
+	require.Equal(t, []string{"prod1", "prod2", "prod3", "prod4", "prod5"}, hitIDs(result))
+	require.Equal(t, []search.CategoryFacet{
+		{Slug: "electronics", Name: "Electronics", Count: 3},
+		{Slug: "accessories", Name: "Accessories", Count: 1},
+		{Slug: "gifts", Name: "Gift ideas", Count: 1},
+	}, result.Facets.Categories)
+	// prezzi con IVA: 12.20, 24.40 (x4)
+	require.Equal(t, []search.PriceFacet{{From: 10, To: 25, Count: 5}}, result.Facets.Prices)
+
+	// il filtro di categoria comprende le sottocategorie e lascia intatta la sua faccetta
+	result, err = svc.Search(ctx, search.Query{Text: "product", CountryCode: "IT", Category: "electronics", MaxPrice: 20})
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod1"}, hitIDs(result))
+	require.Equal(t, search.CategoryFacet{Slug: "electronics", Name: "Electronics", Count: 1}, result.Facets.Categories[0])
+	require.Equal(t, []search.PriceFacet{{From: 10, To: 25, Count: 3}}, result.Facets.Prices)
+
+	result, err = svc.Search(ctx, search.Query{Text: "product", CountryCode: "IT", MinPrice: 22, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Total)
+	require.Len(t, result.Hits, 2)
+}
+
+func TestSearch_Fields(t *testing.T) {
+	svc, _ := newService()
+	ctx := context.Background()
+
+	// il nome della categoria, anche della categoria padre, e gli attributi delle varianti sono cercabili
+	result, err := svc.Search(ctx, search.Query{Text: "apparel black", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"tshirt"}, hitIDs(result))
+	require.Equal(t, 18.3, result.Hits[0].PriceWithVAT)
+
+	// il singolare trova la categoria al plurale
+	result, err = svc.Search(ctx, search.Query{Text: "book", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"novel"}, hitIDs(result))
+}
+
+func TestSearch_Errors(t *testing.T) {
+	svc, _ := newService()
+	ctx := context.Background()
+
+	_, err := svc.Search(ctx, search.Query{Text: "  ", CountryCode: "IT"})
+	require.Equal(t, search.ErrEmptyQuery, err)
+	_, err = svc.Search(ctx, search.Query{Text: "product", CountryCode: "IT", Category: "garden"})
+	require.Equal(t, category.ErrCategoryNotFound, err)
+	_, err = svc.Search(ctx, search.Query{Text: "product", CountryCode: "JP"})
+	require.Equal(t, product.ErrInvalidVATRate, err)
+}
+
+func TestSearch_RebuildOnCatalogChange(t *testing.T) {
+	svc, products := newService()
+	ctx := context.Background()
+
+	result, err := svc.Search(ctx, search.Query{Text: "wireless", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Empty(t, result.Hits)
+
+	p, err := products.GetProduct(ctx, "prod1")
+	require.NoError(t, err)
+	p.Name = "Wireless mouse"
+	require.NoError(t, products.Update(ctx, p))
+
+	// l'indice resta quello di prima finché il catalogo non notifica la modifica
+	result, err = svc.Search(ctx, search.Query{Text: "wireless", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Empty(t, result.Hits)
+
+	require.NoError(t, svc.HandleProductEvent(ctx, events.Envelope{Event: events.ProductPriceChanged{ProductID: "prod1"}}))
+	result, err = svc.Search(ctx, search.Query{Text: "wire", CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod1"}, hitIDs(result))
+}
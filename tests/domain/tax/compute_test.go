@@ -0,0 +1,140 @@
+package tax
+
+import (
+	"math"
+	"math/rand"
+	"purchase-cart-service/internal/domain/rounding"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+)
+
+func vat(rate float64) models.TaxRate {
+	return models.TaxRate{Rate: rate, Jurisdictions: []models.TaxJurisdiction{{Type: models.JurisdictionCountry, Name: "XX", Rate: rate}}}
+}
+
+func taxes(amounts []tax.LineAmounts) []float64 {
+	var result []float64
+	for _, a := range amounts {
+		result = append(result, a.Tax)
+	}
+	return result
+}
+
+func TestCompute_Levels(t *testing.T) {
+	// tre righe da 0.10 al 22%: 0.022 di IVA ciascuna
+	lines := []tax.Line{
+		{Quantity: 1, UnitPrice: 0.10, Rate: vat(0.22)},
+		{Quantity: 1, UnitPrice: 0.10, Rate: vat(0.22)},
+		{Quantity: 1, UnitPrice: 0.10, Rate: vat(0.22)},
+	}
+	perLine := rounding.Policy{Level: rounding.PerLine, Method: rounding.HalfUp}
+	perTotal := rounding.Policy{Level: rounding.PerTotal, Method: rounding.HalfUp}
+	require.Equal(t, []float64{0.02, 0.02, 0.02}, taxes(tax.Compute(lines, perLine)))
+	// per aliquota: 0.066 -> 0.07, spalmato sulle righe
+	require.Equal(t, []float64{0.03, 0.02, 0.02}, taxes(tax.Compute(lines, perTotal)))
+
+	// una riga da 3 x 0.10: per unità 3 x 0.02, per riga 0.066 -> 0.07
+	line := []tax.Line{{Quantity: 3, UnitPrice: 0.10, Rate: vat(0.22)}}
+	perUnit := rounding.Policy{Level: rounding.PerUnit, Method: rounding.HalfUp}
+	require.Equal(t, []float64{0.06}, taxes(tax.Compute(line, perUnit)))
+	require.Equal(t, []float64{0.07}, taxes(tax.Compute(line, perLine)))
+}
+
+func TestCompute_Methods(t *testing.T) {
+	// 0.25 al 10% è 0.025, un pareggio
+	lines := []tax.Line{{Quantity: 1, UnitPrice: 0.25, Rate: vat(0.10)}}
+	require.Equal(t, []float64{0.03}, taxes(tax.Compute(lines, rounding.Policy{Level: rounding.PerLine, Method: rounding.HalfUp})))
+	require.Equal(t, []float64{0.02}, taxes(tax.Compute(lines, rounding.Policy{Level: rounding.PerLine, Method: rounding.HalfEven})))
+	require.Equal(t, []float64{0.02}, taxes(tax.Compute(lines, rounding.Policy{Level: rounding.PerLine, Method: rounding.Bankers})))
+}
+
+var levels = []string{rounding.PerUnit, rounding.PerLine, rounding.PerTotal}
+
+func TestCompute_GrossKeepsThePrice(t *testing.T) {
+	// 3 x 0.99 IVA inclusa al 22%: per unità 3 x 0.18, per riga 2.97 / 1.22 * 0.22 = 0.5356 -> 0.54
+	lines := []tax.Line{{Quantity: 3, UnitPrice: 0.99, Gross: true, Rate: vat(0.22)}}
+	for _, level := range levels {
+		amounts := tax.Compute(lines, rounding.Policy{Level: level, Method: rounding.HalfUp})
+		require.Equal(t, 2.97, amounts[0].Total, level)
+		require.Equal(t, 0.54, amounts[0].Tax, level)
+		require.Equal(t, 2.43, amounts[0].Net, level)
+	}
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// randomLines genera fino a 8 righe con prezzi, quantità, aliquote e modalità casuali
+func randomLines(r *rand.Rand) []tax.Line {
+	rates := []float64{0, 0.04, 0.05, 0.1, 0.19, 0.21, 0.22, 0.255, 0.08875}
+	lines := make([]tax.Line, 1+r.Intn(8))
+	for i := range lines {
+		lines[i] = tax.Line{
+			Quantity:  1 + r.Intn(20),
+			UnitPrice: float64(1+r.Intn(50000)) / 100,
+			Gross:     r.Intn(2) == 0,
+			Rate:      vat(rates[r.Intn(len(rates))]),
+		}
+	}
+	return lines
+}
+
+// le proprietà valgono per ogni livello e metodo di arrotondamento
+func TestCompute_TotalsReconcile(t *testing.T) {
+	for _, level := range levels {
+		for _, method := range []string{rounding.HalfUp, rounding.HalfEven, rounding.Bankers} {
+			policy := rounding.Policy{Level: level, Method: method}
+			property := func(seed int64) bool {
+				lines := randomLines(rand.New(rand.NewSource(seed)))
+				amounts := tax.Compute(lines, policy)
+				exactByRate := map[float64]float64{}
+				taxByRate := map[float64]int64{}
+				for i, l := range lines {
+					a := amounts[i]
+					// netto più imposta è il totale della riga, al centesimo
+					if cents(a.Net)+cents(a.Tax) != cents(a.Total) {
+						return false
+					}
+					// una riga IVA inclusa costa esattamente il prezzo di listino
+					price := policy.Round(float64(l.Quantity) * l.UnitPrice)
+					if l.Gross && cents(a.Total) != cents(price) || !l.Gross && cents(a.Net) != cents(price) {
+						return false
+					}
+					// le quote delle giurisdizioni sommano l'imposta della riga
+					var shares int64
+					for _, s := range a.Shares {
+						shares += cents(s.Amount)
+					}
+					if len(a.Shares) > 0 && shares != cents(a.Tax) {
+						return false
+					}
+					exact := price * l.Rate.Rate
+					if l.Gross {
+						exact = price * l.Rate.Rate / (1 + l.Rate.Rate)
+					}
+					// a parte l'arrotondamento per unità, ogni riga è entro un centesimo dal valore esatto
+					if level != rounding.PerUnit && math.Abs(a.Tax-exact) >= 0.01+1e-9 {
+						return false
+					}
+					exactByRate[l.Rate.Rate] += exact
+					taxByRate[l.Rate.Rate] += cents(a.Tax)
+				}
+				// per aliquota, l'imposta delle righe è l'arrotondamento del totale esatto
+				if level == rounding.PerTotal {
+					for rate, exact := range exactByRate {
+						if taxByRate[rate] != cents(policy.Round(exact)) {
+							return false
+						}
+					}
+				}
+				return true
+			}
+			require.NoError(t, quick.Check(property, &quick.Config{MaxCount: 500}), "%s %s", level, method)
+		}
+	}
+}
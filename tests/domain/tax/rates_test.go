@@ -0,0 +1,51 @@
+package tax
+
+import (
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRates(t *testing.T) {
+	regions, err := tax.ReadRates(strings.NewReader(`# commento
+zip,state,state_rate,county,county_rate,city,city_rate,special,special_rate
+73301,tx,0.0625,,,Austin,0.01,Capital Metro Transit,0.01
+98101,WA,0.065,,,Seattle,0.0215,,
+`))
+	require.NoError(t, err)
+	require.Equal(t, []models.SalesTaxRegion{
+		{State: "TX", PostalCode: "73301", StateRate: 0.0625, City: "Austin", CityRate: 0.01, Special: "Capital Metro Transit", SpecialRate: 0.01},
+		{State: "WA", PostalCode: "98101", StateRate: 0.065, City: "Seattle", CityRate: 0.0215},
+	}, regions, "le colonne sono lette per nome")
+}
+
+func TestReadRates_Errors(t *testing.T) {
+	const header = "state,zip,state_rate,county,county_rate,city,city_rate,special,special_rate\n"
+	cases := map[string]struct {
+		table    string
+		contains string
+	}{
+		"colonna mancante":     {"state,zip,state_rate\nTX,73301,0.0625\n", `"county"`},
+		"stato non valido":     {header + "Texas,73301,0.0625,,,,,,\n", "line 2: state"},
+		"ZIP non valido":       {header + "TX,7330,0.0625,,,,,,\n", "line 2: zip"},
+		"aliquota percentuale": {header + "TX,73301,6.25,,,,,,\n", "state_rate"},
+		"aliquota senza nome":  {header + "TX,73301,0.0625,,0.01,,,,\n", "county_rate"},
+		"ZIP duplicato":        {header + "TX,73301,0.0625,,,,,,\nTX,73301,0.0625,,,,,,\n", "line 3: duplicate"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tax.ReadRates(strings.NewReader(tc.table))
+			require.ErrorIs(t, err, tax.ErrInvalidRateTable)
+			require.Contains(t, err.Error(), tc.contains)
+		})
+	}
+}
+
+func TestLoadRatesFile_Shipped(t *testing.T) {
+	regions, err := tax.LoadRatesFile("../../../data/us_sales_tax.csv")
+	require.NoError(t, err)
+	require.NotEmpty(t, regions)
+}
@@ -0,0 +1,148 @@
+package tax
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tabella di prova: aliquote distinte per riconoscere la località applicata
+var regions = []models.SalesTaxRegion{
+	{State: "TX", PostalCode: "73301", StateRate: 0.0625, City: "Austin", CityRate: 0.01, Special: "Capital Metro Transit", SpecialRate: 0.01},
+	{State: "TX", PostalCode: "79901", StateRate: 0.0625, County: "El Paso", CountyRate: 0.005, City: "El Paso", CityRate: 0.01},
+	{State: "CA", PostalCode: "95814", StateRate: 0.0725, County: "Sacramento", CountyRate: 0.005, Special: "Sacramento Districts", SpecialRate: 0.01},
+	{State: "CA", PostalCode: "94103", StateRate: 0.0725, Special: "San Francisco Districts", SpecialRate: 0.01375},
+	{State: "NY", PostalCode: "10001", StateRate: 0.04, City: "New York City", CityRate: 0.045, Special: "MCTD", SpecialRate: 0.00375},
+	{State: "NY", PostalCode: "14604", StateRate: 0.04, County: "Monroe", CountyRate: 0.04},
+}
+
+func newService(t *testing.T, originState, originZIP string) *tax.Service {
+	t.Helper()
+	salesTax := repository.NewSalesTaxRepository("InMemory")
+	require.NoError(t, salesTax.ReplaceAll(context.Background(), regions))
+	origin := models.Destination{CountryCode: "US", Region: originState, PostalCode: originZIP}
+	return tax.NewService(repository.NewVatRateRepository("InMemory"), tax.WithSalesTax(salesTax, origin))
+}
+
+func us(state, zip string) models.Destination {
+	return models.Destination{CountryCode: "US", Region: state, PostalCode: zip}
+}
+
+func TestLookup_VAT(t *testing.T) {
+	svc := newService(t, "TX", "73301")
+	rate, err := svc.Lookup(context.Background(), models.Destination{CountryCode: "IT"})
+	require.NoError(t, err)
+	require.Equal(t, &models.TaxRate{Rate: 0.22, Jurisdictions: []models.TaxJurisdiction{{Type: models.JurisdictionCountry, Name: "IT", Rate: 0.22}}}, rate)
+
+	_, err = svc.Lookup(context.Background(), models.Destination{CountryCode: "JP"})
+	require.ErrorIs(t, err, tax.ErrRateNotFound)
+
+	// senza tabella gli Stati Uniti restano all'aliquota piatta del paese
+	flat, err := tax.NewService(repository.NewVatRateRepository("InMemory")).Lookup(context.Background(), us("TX", "73301"))
+	require.NoError(t, err)
+	require.Zero(t, flat.Rate)
+}
+
+func TestLookup_Sourcing(t *testing.T) {
+	cases := map[string]struct {
+		origin        [2]string
+		destination   models.Destination
+		rate          float64
+		jurisdictions []string
+	}{
+		"stato destination-based": {
+			origin: [2]string{"NY", "10001"}, destination: us("NY", "14604"),
+			rate: 0.08, jurisdictions: []string{"NY", "Monroe"},
+		},
+		"stato origin-based": {
+			origin: [2]string{"TX", "73301"}, destination: us("tx", "79901-1234"),
+			rate: 0.0825, jurisdictions: []string{"TX", "Austin", "Capital Metro Transit"},
+		},
+		"vendita tra stati": {
+			origin: [2]string{"TX", "73301"}, destination: us("NY", "10001"),
+			rate: 0.08875, jurisdictions: []string{"NY", "New York City", "MCTD"},
+		},
+		"California: distretti della destinazione": {
+			origin: [2]string{"CA", "95814"}, destination: us("CA", "94103"),
+			rate: 0.09125, jurisdictions: []string{"CA", "Sacramento", "San Francisco Districts"},
+		},
+		"stato non coperto": {
+			origin: [2]string{"TX", "73301"}, destination: us("OR", "97201"),
+			rate: 0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rate, err := newService(t, tc.origin[0], tc.origin[1]).Lookup(context.Background(), tc.destination)
+			require.NoError(t, err)
+			require.Equal(t, tc.rate, rate.Rate)
+			var names []string
+			var sum float64
+			for _, j := range rate.Jurisdictions {
+				names = append(names, j.Name)
+				sum += j.Rate
+			}
+			require.Equal(t, tc.jurisdictions, names)
+			require.InDelta(t, rate.Rate, sum, 1e-9)
+		})
+	}
+}
+
+func TestLookup_DestinationErrors(t *testing.T) {
+	svc := newService(t, "TX", "73301")
+	_, err := svc.Lookup(context.Background(), us("", "73301"))
+	require.ErrorIs(t, err, tax.ErrIncompleteDestination)
+	_, err = svc.Lookup(context.Background(), us("TX", ""))
+	require.ErrorIs(t, err, tax.ErrIncompleteDestination)
+	_, err = svc.Lookup(context.Background(), us("TX", "10001"))
+	require.ErrorIs(t, err, tax.ErrPostalCodeNotFound, "lo ZIP appartiene a un altro stato")
+
+	// origine assente dalla tabella: errore anche se la destinazione è nota
+	_, err = newService(t, "TX", "75201").Lookup(context.Background(), us("TX", "79901"))
+	require.ErrorIs(t, err, tax.ErrPostalCodeNotFound)
+}
+
+func TestUnitPrices(t *testing.T) {
+	net, gross := tax.UnitPrices(10, models.PriceModeNet, 0.22)
+	require.Equal(t, 10.0, net)
+	require.Equal(t, 12.20, gross)
+
+	net, gross = tax.UnitPrices(12.20, models.PriceModeGross, 0.22)
+	require.Equal(t, 10.0, net)
+	require.Equal(t, 12.20, gross)
+
+	// 9.99 IVA inclusa al 19%: 1.595 -> 1.60 di imposta
+	net, gross = tax.UnitPrices(9.99, models.PriceModeGross, 0.19)
+	require.Equal(t, 8.39, net)
+	require.Equal(t, 9.99, gross)
+}
+
+func TestLookupClass(t *testing.T) {
+	svc := newService(t, "TX", "73301")
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		destination models.Destination
+		class       string
+		want        float64
+	}{
+		{"ridotta", models.Destination{CountryCode: "IT"}, models.TaxClassReduced, 0.10},
+		{"super ridotta", models.Destination{CountryCode: "FR"}, models.TaxClassSuperReduced, 0.021},
+		{"super ridotta assente, vale la ridotta", models.Destination{CountryCode: "DE"}, models.TaxClassSuperReduced, 0.07},
+		{"zero", models.Destination{CountryCode: "IT"}, models.TaxClassZero, 0},
+		{"sales tax senza classi ridotte", us("TX", "73301"), models.TaxClassReduced, 0.0825},
+		{"sales tax esente", us("TX", "73301"), models.TaxClassZero, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := svc.LookupClass(ctx, tt.destination, tt.class)
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, rate.Rate, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package tax
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/tax"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTerritoryService(t *testing.T) *tax.Service {
+	t.Helper()
+	territories, err := tax.LoadTerritoriesFile("../../../data/vat_territories.csv")
+	require.NoError(t, err)
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	require.NoError(t, vatRepo.ReplaceTerritories(territories))
+	return tax.NewService(vatRepo)
+}
+
+func TestLookup_Territories(t *testing.T) {
+	svc := newTerritoryService(t)
+	cases := map[string]struct {
+		destination models.Destination
+		territory   string
+		rate        float64
+	}{
+		"Canarie, Las Palmas":      {models.Destination{CountryCode: "ES", PostalCode: "35001"}, "Canary Islands", 0},
+		"Canarie, Tenerife":        {models.Destination{CountryCode: "ES", PostalCode: "38001"}, "Canary Islands", 0},
+		"Canarie, per regione":     {models.Destination{CountryCode: "ES", Region: "cn"}, "Canary Islands", 0},
+		"Ceuta":                    {models.Destination{CountryCode: "ES", PostalCode: "51001"}, "Ceuta", 0},
+		"Melilla":                  {models.Destination{CountryCode: "ES", PostalCode: "52001"}, "Melilla", 0},
+		"Spagna continentale":      {models.Destination{CountryCode: "ES", PostalCode: "28013"}, "", 0.21},
+		"Livigno":                  {models.Destination{CountryCode: "IT", PostalCode: "23041"}, "Livigno", 0},
+		"Campione d'Italia":        {models.Destination{CountryCode: "IT", PostalCode: "22061"}, "Campione d'Italia", 0},
+		"Bormio, vicino a Livigno": {models.Destination{CountryCode: "IT", PostalCode: "23032"}, "", 0.22},
+		"Helgoland":                {models.Destination{CountryCode: "DE", PostalCode: "27498"}, "Heligoland", 0},
+		"Büsingen":                 {models.Destination{CountryCode: "DE", PostalCode: "78266"}, "Büsingen am Hochrhein", 0},
+		"Germania continentale":    {models.Destination{CountryCode: "DE", PostalCode: "27499"}, "", 0.19},
+		"Åland":                    {models.Destination{CountryCode: "FI", PostalCode: "22100"}, "Åland Islands", 0},
+		"Monte Athos":              {models.Destination{CountryCode: "GR", PostalCode: "63086"}, "Mount Athos", 0},
+		"Irlanda del Nord":         {models.Destination{CountryCode: "GB", PostalCode: "bt1 1aa"}, "Northern Ireland", 0.2},
+		"Irlanda del Nord, UK":     {models.Destination{CountryCode: "UK", PostalCode: "BT48 6DQ"}, "Northern Ireland", 0.2},
+		"Gran Bretagna":            {models.Destination{CountryCode: "GB", PostalCode: "B1 1AA"}, "", 0.2},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rate, err := svc.Lookup(context.Background(), tc.destination)
+			require.NoError(t, err)
+			require.Equal(t, tc.rate, rate.Rate)
+			if tc.territory == "" {
+				require.Nil(t, rate.Territory)
+				return
+			}
+			require.NotNil(t, rate.Territory)
+			require.Equal(t, tc.territory, rate.Territory.Name)
+			if tc.rate == 0 {
+				require.Empty(t, rate.Jurisdictions, "fuori dal territorio IVA non c'è imposta da ripartire")
+			}
+		})
+	}
+}
+
+func TestLookup_NorthernIrelandJurisdiction(t *testing.T) {
+	rate, err := newTerritoryService(t).Lookup(context.Background(), models.Destination{CountryCode: "GB", PostalCode: "BT1 1AA"})
+	require.NoError(t, err)
+	require.Equal(t, "XI", rate.Territory.Code)
+	require.Equal(t, []models.TaxJurisdiction{{Type: models.JurisdictionTerritory, Name: "Northern Ireland", Rate: 0.2}}, rate.Jurisdictions)
+}
+
+func TestReadTerritories_Errors(t *testing.T) {
+	const header = "country,postal_code,region,territory,code,rate\n"
+	cases := map[string]struct {
+		table    string
+		contains string
+	}{
+		"colonna mancante":      {"country,postal_code,territory,rate\nES,35,Canary Islands,0\n", `"region"`},
+		"né CAP né regione":     {header + "ES,,,Canary Islands,,0\n", "line 2: exactly one"},
+		"CAP e regione":         {header + "ES,35,CN,Canary Islands,,0\n", "line 2: exactly one"},
+		"nome mancante":         {header + "ES,35,,,,0\n", "territory"},
+		"aliquota non valida":   {header + "ES,35,,Canary Islands,,7\n", "rate"},
+		"codice IVA non valido": {header + "GB,BT,,Northern Ireland,XIR,0.2\n", "code"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tax.ReadTerritories(strings.NewReader(tc.table))
+			require.ErrorIs(t, err, tax.ErrInvalidTerritoryTable)
+			require.Contains(t, err.Error(), tc.contains)
+		})
+	}
+}
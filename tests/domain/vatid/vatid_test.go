@@ -0,0 +1,92 @@
+package vatid
+
+import (
+	"context"
+	"purchase-cart-service/internal/domain/vatid"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// numeri pubblicati come esempi validi dalle amministrazioni o dalle librerie di riferimento
+var validNumbers = map[string]string{
+	"ATU13585627":     "ATU13585627",
+	"BE 0403.019.261": "BE0403019261",
+	"DE136695976":     "DE136695976",
+	"DK13585628":      "DK13585628",
+	"EE100931558":     "EE100931558",
+	"GR094259216":     "EL094259216",
+	"ESB58378431":     "ESB58378431",
+	"ES54362315K":     "ES54362315K",
+	"ESX2482300W":     "ESX2482300W",
+	"FI20774740":      "FI20774740",
+	"FR40303265045":   "FR40303265045",
+	"HR33392005961":   "HR33392005961",
+	"it 00743110157":  "IT00743110157",
+	"LU15027442":      "LU15027442",
+	"NL004495445B01":  "NL004495445B01",
+	"PL8567346215":    "PL8567346215",
+	"PT501964843":     "PT501964843",
+	"SE123456789701":  "SE123456789701",
+	"SI50223054":      "SI50223054",
+}
+
+func TestParse_Valid(t *testing.T) {
+	for input, normalized := range validNumbers {
+		n, err := vatid.Parse(input)
+		require.NoError(t, err, input)
+		require.Equal(t, normalized, n.String(), input)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := map[string]error{
+		"":                vatid.ErrInvalidFormat,
+		"DE":              vatid.ErrInvalidFormat,
+		"DE13669597":      vatid.ErrInvalidFormat,
+		"DE13669597A":     vatid.ErrInvalidFormat,
+		"DE136695977":     vatid.ErrInvalidChecksum,
+		"IT00743110158":   vatid.ErrInvalidChecksum,
+		"ATU13585628":     vatid.ErrInvalidChecksum,
+		"FR41303265045":   vatid.ErrInvalidChecksum,
+		"NL004495446B01":  vatid.ErrInvalidChecksum,
+		"US123456789":     vatid.ErrUnsupportedCountry,
+		"GB123456789":     vatid.ErrUnsupportedCountry,
+		"123456789":       vatid.ErrInvalidFormat,
+		"IT 0074311015 7": nil,
+	}
+	for input, want := range cases {
+		_, err := vatid.Parse(input)
+		if want == nil {
+			require.NoError(t, err, input)
+			continue
+		}
+		require.ErrorIs(t, err, want, input)
+	}
+}
+
+func TestNumber_CountryCode(t *testing.T) {
+	n, err := vatid.Parse("EL094259216")
+	require.NoError(t, err)
+	require.Equal(t, "GR", n.CountryCode(), "il prefisso EL corrisponde alla Grecia")
+	require.True(t, vatid.IsEU("GR"))
+	require.False(t, vatid.IsEU("XI"), "l'Irlanda del Nord non è uno stato membro")
+	require.False(t, vatid.IsEU("CH"))
+}
+
+func TestStubVerifier(t *testing.T) {
+	verifier := vatid.NewStubVerifier("it 00743110157")
+	registered, err := vatid.Parse("IT00743110157")
+	require.NoError(t, err)
+	unregistered, err := vatid.Parse("DE136695976")
+	require.NoError(t, err)
+
+	v, err := verifier.Verify(context.Background(), registered)
+	require.NoError(t, err)
+	require.True(t, v.Valid)
+	require.NotEmpty(t, v.RequestID)
+
+	v, err = verifier.Verify(context.Background(), unregistered)
+	require.NoError(t, err)
+	require.False(t, v.Valid)
+}
@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/webhook"
+	"purchase-cart-service/internal/outbox"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "s3cr3t"
+
+type received struct {
+	deliveryID string
+	event      string
+	payload    webhook.EventPayload
+}
+
+// receiver è un endpoint httptest che verifica la firma e risponde con gli
+// status code forniti, poi 200
+type receiver struct {
+	t      *testing.T
+	server *httptest.Server
+	codes  []int
+	calls  atomic.Int32
+
+	mu       sync.Mutex
+	received []received
+}
+
+func newReceiver(t *testing.T, codes ...int) *receiver {
+	rc := &receiver{t: t, codes: codes}
+	rc.server = httptest.NewServer(http.HandlerFunc(rc.handle))
+	t.Cleanup(rc.server.Close)
+	return rc
+}
+
+func (rc *receiver) handle(w http.ResponseWriter, r *http.Request) {
+	call := int(rc.calls.Add(1))
+	body, _ := io.ReadAll(r.Body)
+	ts, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if err != nil || !webhook.Verify(testSecret, ts, body, r.Header.Get(webhook.HeaderSignature)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if call <= len(rc.codes) {
+		w.WriteHeader(rc.codes[call-1])
+		return
+	}
+	var payload webhook.EventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rc.mu.Lock()
+	rc.received = append(rc.received, received{
+		deliveryID: r.Header.Get(webhook.HeaderDeliveryID),
+		event:      r.Header.Get(webhook.HeaderEvent),
+		payload:    payload,
+	})
+	rc.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) deliveries() []received {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]received(nil), rc.received...)
+}
+
+type fixture struct {
+	svc         *webhook.Service
+	dispatcher  *webhook.Dispatcher
+	deadLetters repository.WebhookDeadLetterRepository
+	outbox      repository.OutboxRepository
+	relay       *outbox.Relay
+}
+
+func newFixture(t *testing.T, maxAttempts int) *fixture {
+	deadLetters := repository.NewWebhookDeadLetterRepository("InMemory")
+	dispatcher := webhook.NewDispatcher(webhook.DispatcherConfig{
+		Workers:        2,
+		QueueSize:      10,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Timeout:        time.Second,
+	}, deadLetters, nil)
+	t.Cleanup(func() { _ = dispatcher.Close(context.Background()) })
+	svc := webhook.NewService(repository.NewWebhookRepository("InMemory"), deadLetters, dispatcher)
+	bus := events.NewBus()
+	bus.Subscribe("webhooks", svc.HandleOrderEvent, webhook.EventTypes...)
+	outboxRepo := repository.NewOutboxRepository("InMemory")
+	return &fixture{
+		svc:         svc,
+		dispatcher:  dispatcher,
+		deadLetters: deadLetters,
+		outbox:      outboxRepo,
+		relay:       outbox.NewRelay(outboxRepo, outbox.RelayConfig{}, outbox.BusSinks(bus)...),
+	}
+}
+
+func newOrderService(f *fixture) *order.Service {
+	return order.NewService(repository.NewOrderRepository("InMemory", repository.WithOutbox(f.outbox)), repository.NewVatRateRepository("InMemory"), repository.NewProductRepository("InMemory"))
+}
+
+// flush inoltra gli eventi dell'outbox e attende le consegne in coda
+func (f *fixture) flush(t *testing.T) {
+	t.Helper()
+	_, err := f.relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, f.dispatcher.Close(context.Background()))
+}
+
+func subscribe(t *testing.T, f *fixture, url string, events ...string) *models.WebhookSubscription {
+	sub, err := f.svc.CreateSubscription(context.Background(), webhook.SubscriptionInput{
+		URL:    url,
+		Events: events,
+		Secret: testSecret,
+		Active: true,
+	})
+	require.NoError(t, err)
+	return sub
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	sig := webhook.Sign(testSecret, 1700000000, body)
+	require.True(t, webhook.Verify(testSecret, 1700000000, body, sig))
+	require.False(t, webhook.Verify("other", 1700000000, body, sig))
+	require.False(t, webhook.Verify(testSecret, 1700000001, body, sig))
+	require.False(t, webhook.Verify(testSecret, 1700000000, []byte(`{"id":"2"}`), sig))
+}
+
+func TestOrderEvents_DeliveredToMatchingSubscriptions(t *testing.T) {
+	f := newFixture(t, 1)
+	rc := newReceiver(t)
+	subscribe(t, f, rc.server.URL, "order.created", "order.shipped")
+	orders := newOrderService(f)
+	ctx := context.Background()
+
+	created, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 2}})
+	require.NoError(t, err)
+	_, err = orders.MarkPaid(ctx, created.ID)
+	require.NoError(t, err)
+	_, err = orders.ShipOrder(ctx, created.ID)
+	require.NoError(t, err)
+	f.flush(t)
+
+	got := rc.deliveries()
+	require.Len(t, got, 2)
+	events := map[string]webhook.EventPayload{}
+	for _, d := range got {
+		require.NotEmpty(t, d.deliveryID)
+		require.Equal(t, d.event, d.payload.Type)
+		events[d.event] = d.payload
+	}
+	require.Equal(t, created.ID, events["order.created"].Data.OrderID)
+	require.Equal(t, models.OrderStatusCreated, events["order.created"].Data.Status)
+	require.Len(t, events["order.created"].Data.Items, 1)
+	require.Equal(t, models.OrderStatusShipped, events["order.shipped"].Data.Status)
+}
+
+func TestOrderEvents_InactiveOrUnsubscribedNotDelivered(t *testing.T) {
+	f := newFixture(t, 1)
+	rc := newReceiver(t)
+	subscribe(t, f, rc.server.URL, "order.cancelled")
+	sub := subscribe(t, f, rc.server.URL, "order.created")
+	_, err := f.svc.UpdateSubscription(context.Background(), sub.ID, webhook.SubscriptionInput{
+		URL:    rc.server.URL,
+		Events: []string{"order.created"},
+		Active: false,
+	})
+	require.NoError(t, err)
+	orders := newOrderService(f)
+
+	_, err = orders.CreateOrder(context.Background(), "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+	f.flush(t)
+	require.Empty(t, rc.deliveries())
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	f := newFixture(t, 4)
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	subscribe(t, f, rc.server.URL, "order.created")
+	orders := newOrderService(f)
+
+	_, err := orders.CreateOrder(context.Background(), "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+	f.flush(t)
+
+	require.EqualValues(t, 3, rc.calls.Load(), "due fallimenti e un successo")
+	require.Len(t, rc.deliveries(), 1)
+	deadLetters, err := f.deadLetters.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}
+
+func TestDispatcher_DeadLetterAndRedeliver(t *testing.T) {
+	f := newFixture(t, 2)
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	subscribe(t, f, rc.server.URL, "order.created")
+	orders := newOrderService(f)
+	ctx := context.Background()
+
+	_, err := orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+	_, err = f.relay.RelayPending(ctx)
+	require.NoError(t, err)
+
+	var deadLetters []*models.WebhookDeadLetter
+	require.Eventually(t, func() bool {
+		deadLetters, err = f.svc.GetAllDeadLetters(ctx)
+		return err == nil && len(deadLetters) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+	require.Equal(t, 2, deadLetters[0].Attempts)
+	require.Equal(t, "order.created", deadLetters[0].EventType)
+	require.Empty(t, rc.deliveries())
+
+	require.NoError(t, f.svc.Redeliver(ctx, deadLetters[0].ID))
+	require.NoError(t, f.dispatcher.Close(ctx))
+
+	got := rc.deliveries()
+	require.Len(t, got, 1)
+	require.Equal(t, deadLetters[0].DeliveryID, got[0].deliveryID, "l'ID di consegna resta stabile")
+	remaining, err := f.svc.GetAllDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	require.Equal(t, webhook.ErrDeadLetterNotFound, f.svc.Redeliver(ctx, deadLetters[0].ID))
+}
+
+// una consegna che non può essere rimessa in coda resta tra i dead letter
+func TestRedeliver_KeepsDeadLetterWhenEnqueueFails(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+	sub := subscribe(t, f, "http://127.0.0.1:1", "order.created")
+	deadLetter := &models.WebhookDeadLetter{DeliveryID: "delivery-1", SubscriptionID: sub.ID, EventType: "order.created", Payload: []byte(`{}`)}
+	require.NoError(t, f.deadLetters.Save(ctx, deadLetter))
+	require.NoError(t, f.dispatcher.Close(ctx))
+
+	require.ErrorIs(t, f.svc.Redeliver(ctx, deadLetter.ID), webhook.ErrDispatcherClosed)
+	remaining, err := f.svc.GetAllDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+}
+
+func TestService_Validation(t *testing.T) {
+	f := newFixture(t, 1)
+	ctx := context.Background()
+
+	_, err := f.svc.CreateSubscription(ctx, webhook.SubscriptionInput{URL: "not a url", Events: []string{"order.created"}})
+	require.Equal(t, webhook.ErrInvalidURL, err)
+	_, err = f.svc.CreateSubscription(ctx, webhook.SubscriptionInput{URL: "https://example.com"})
+	require.Equal(t, webhook.ErrInvalidEvents, err)
+	_, err = f.svc.GetSubscription(ctx, "missing")
+	require.Equal(t, webhook.ErrSubscriptionNotFound, err)
+}
@@ -0,0 +1,332 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"purchase-cart-service/internal/domain/events"
+	"purchase-cart-service/internal/domain/order"
+	"purchase-cart-service/internal/domain/product"
+	"purchase-cart-service/internal/outbox"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink registra i messaggi ricevuti e fallisce finché fail è true
+type recordingSink struct {
+	name string
+	mu   sync.Mutex
+	fail bool
+	ids  []string
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("sink down")
+	}
+	s.ids = append(s.ids, msg.ID)
+	return nil
+}
+
+func (s *recordingSink) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ids...)
+}
+
+func (s *recordingSink) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+type fixture struct {
+	outbox   repository.OutboxRepository
+	orders   *order.Service
+	products *product.Service
+}
+
+func newFixture() *fixture {
+	outboxRepo := repository.NewOutboxRepository("InMemory")
+	productRepo := repository.NewProductRepository("InMemory", repository.WithOutbox(outboxRepo))
+	vatRepo := repository.NewVatRateRepository("InMemory")
+	return &fixture{
+		outbox:   outboxRepo,
+		orders:   order.NewService(repository.NewOrderRepository("InMemory", repository.WithOutbox(outboxRepo)), vatRepo, productRepo),
+		products: product.NewService(productRepo, vatRepo),
+	}
+}
+
+func TestDomainServices_WriteOutboxWithChanges(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	created, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 2}})
+	require.NoError(t, err)
+	_, err = f.orders.CancelOrder(ctx, created.ID)
+	require.NoError(t, err)
+	_, err = f.products.UpdatePrice(ctx, "prod2", 25, "")
+	require.NoError(t, err)
+	// un prezzo invariato non genera eventi
+	_, err = f.products.UpdatePrice(ctx, "prod2", 25, "")
+	require.NoError(t, err)
+	// una transizione rifiutata non genera eventi
+	_, err = f.orders.ShipOrder(ctx, created.ID)
+	require.Equal(t, order.ErrInvalidStatusTransition, err)
+
+	pending, err := f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	require.Equal(t, events.TypeOrderCreated, pending[0].EventType)
+	require.Equal(t, created.ID, pending[0].AggregateID)
+	require.Equal(t, events.TypeOrderCancelled, pending[1].EventType)
+	require.Equal(t, events.TypeProductPriceChanged, pending[2].EventType)
+
+	envelope, err := events.Decode(pending[2])
+	require.NoError(t, err)
+	require.Equal(t, events.ProductPriceChanged{ProductID: "prod2", OldPrice: 20, NewPrice: 25}, envelope.Event)
+}
+
+func TestRelay_DeliversInOrderAndMarksDispatched(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+		require.NoError(t, err)
+	}
+	pending, err := f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	var want []string
+	for _, m := range pending {
+		want = append(want, m.ID)
+	}
+
+	sink := &recordingSink{name: "rec"}
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{BatchSize: 2}, sink)
+	n, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, want, sink.received())
+
+	pending, err = f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestRelay_FailingSinkRetriedWithoutDuplicates(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+		require.NoError(t, err)
+	}
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", fail: true}
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{}, healthy, flaky)
+
+	n, err := relay.RelayPending(ctx)
+	require.Error(t, err)
+	var sinkErr *outbox.SinkError
+	require.ErrorAs(t, err, &sinkErr)
+	require.Equal(t, "flaky", sinkErr.Sink)
+	require.Zero(t, n)
+	require.Len(t, healthy.received(), 3, "un sink in errore non blocca gli altri")
+	require.Empty(t, flaky.received())
+
+	pending, err := f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.Contains(t, pending[0].LastError, "sink down")
+
+	flaky.setFail(false)
+	n, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Len(t, healthy.received(), 3, "nessun duplicato sui sink già serviti")
+	require.Len(t, flaky.received(), 3)
+	require.Equal(t, healthy.received(), flaky.received(), "stesso ordine su entrambi i sink")
+}
+
+// poisonSink fallisce sempre il messaggio poison e riceve gli altri
+type poisonSink struct {
+	recordingSink
+	poison string
+}
+
+func (s *poisonSink) Send(ctx context.Context, msg *models.OutboxMessage) error {
+	if msg.ID == s.poison {
+		return errors.New("cannot handle")
+	}
+	return s.recordingSink.Send(ctx, msg)
+}
+
+// un messaggio fallito per MaxAttempts passate esce dalla coda e sblocca i successivi
+func TestRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+		require.NoError(t, err)
+	}
+	pending, err := f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	healthy := &recordingSink{name: "healthy"}
+	poisoned := &poisonSink{recordingSink: recordingSink{name: "poisoned"}, poison: pending[0].ID}
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{MaxAttempts: 2}, healthy, poisoned)
+
+	_, err = relay.RelayPending(ctx)
+	require.Error(t, err)
+	pending, err = f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 2, "il secondo messaggio attende il primo")
+	require.Empty(t, poisoned.received())
+
+	_, err = relay.RelayPending(ctx)
+	require.Error(t, err)
+	pending, err = f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "il primo messaggio è scartato al secondo tentativo")
+
+	n, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{pending[0].ID}, poisoned.received())
+	require.Len(t, healthy.received(), 2)
+	pending, err = f.outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+// un handler del bus in errore viene ritentato da solo, senza ripetere gli altri
+func TestRelay_BusHandlersDeliveredSeparately(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+
+	bus := events.NewBus()
+	healthyCalls, flakyCalls := 0, 0
+	bus.Subscribe("healthy", func(ctx context.Context, e events.Envelope) error {
+		healthyCalls++
+		return nil
+	}, events.TypeOrderCreated)
+	bus.Subscribe("flaky", func(ctx context.Context, e events.Envelope) error {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return errors.New("boom")
+		}
+		return nil
+	}, events.TypeOrderCreated)
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{}, outbox.BusSinks(bus)...)
+
+	_, err = relay.RelayPending(ctx)
+	var sinkErr *outbox.SinkError
+	require.ErrorAs(t, err, &sinkErr)
+	require.Equal(t, "bus:flaky", sinkErr.Sink)
+
+	n, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, 1, healthyCalls, "l'handler già servito non riceve l'evento di nuovo")
+	require.Equal(t, 2, flakyCalls)
+}
+
+func TestRelay_StartAndStop(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	sink := &recordingSink{name: "rec"}
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{PollInterval: 5 * time.Millisecond}, sink)
+	relay.Start()
+
+	_, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(sink.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, relay.Stop(ctx))
+	// Stop esegue un ultimo passaggio per i messaggi rimasti
+	_, err = f.products.UpdatePrice(ctx, "prod1", 11, "")
+	require.NoError(t, err)
+	require.NoError(t, relay.Stop(ctx))
+	require.Len(t, sink.received(), 2)
+}
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, err := f.products.UpdatePrice(ctx, "prod1", 12, "")
+	require.NoError(t, err)
+	_, err = f.products.UpdatePrice(ctx, "prod1", 13, "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	_, err = outbox.NewRelay(f.outbox, outbox.RelayConfig{}, outbox.NewFileSink(path)).RelayPending(ctx)
+	require.NoError(t, err)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2)
+	require.Equal(t, events.TypeProductPriceChanged, lines[0]["type"])
+	require.Equal(t, "prod1", lines[0]["aggregate_id"])
+	require.Equal(t, 13.0, lines[1]["data"].(map[string]any)["new_price"])
+}
+
+func TestHTTPSink_PostsWithIdempotencyKey(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	created, err := f.orders.CreateOrder(ctx, "IT", []order.CreateItem{{ProductID: "prod1", Quantity: 1}})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var keys []string
+	var bodies []map[string]any
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	relay := outbox.NewRelay(f.outbox, outbox.RelayConfig{}, outbox.NewHTTPSink(srv.URL, time.Second, nil))
+	_, err = relay.RelayPending(ctx)
+	require.Error(t, err, "la prima risposta 502 lascia il messaggio in outbox")
+	_, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 1)
+	require.Equal(t, bodies[0]["id"], keys[0])
+	require.Equal(t, events.TypeOrderCreated, bodies[0]["type"])
+	require.Equal(t, created.ID, bodies[0]["aggregate_id"])
+}
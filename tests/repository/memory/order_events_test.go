@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/models"
+	"purchase-cart-service/repository"
+	"purchase-cart-service/repository/memory"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newOrder() *models.Order {
+	return &models.Order{
+		Status:          models.OrderStatusCreated,
+		ShippingAddress: models.Address{Line1: "Via Po 3", City: "Torino", PostalCode: "10123", CountryCode: "IT"},
+		Items: []models.Item{{ProductID: "prod1", Name: "Product 1", Quantity: 2, UnitPrice: 10, VATRate: 0.22, VAT: 24.4, Taxes: []models.LineTax{
+			{TaxJurisdiction: models.TaxJurisdiction{Type: models.JurisdictionCountry, Name: "IT", Rate: 0.22}, Amount: 4.4},
+		}}},
+		TotalPrice: 24.4,
+		TotalVAT:   4.4,
+	}
+}
+
+func TestEventSourced_SaveUpdateReplay(t *testing.T) {
+	repo := memory.NewEventSourcedOrderRepository(nil, 0)
+	ctx := context.Background()
+	order := newOrder()
+	require.NoError(t, repo.Save(ctx, order))
+	require.NotEmpty(t, order.ID)
+
+	changed := *order
+	changed.Status = models.OrderStatusShipped
+	require.NoError(t, repo.Update(ctx, &changed))
+	// un aggiornamento senza differenze non aggiunge eventi
+	require.NoError(t, repo.Update(ctx, &changed))
+
+	got, err := repo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.OrderStatusShipped, got.Status)
+	require.Equal(t, order.Items, got.Items)
+	require.Equal(t, order.ShippingAddress, got.ShippingAddress)
+	require.Equal(t, order.TotalPrice, got.TotalPrice)
+
+	history, err := repo.GetHistory(ctx, order.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, models.OrderEventCreated, history[0].Type)
+	require.Equal(t, 1, history[0].Version)
+	require.Equal(t, models.OrderEventStatusChanged, history[1].Type)
+	require.Equal(t, 2, history[1].Version)
+	require.JSONEq(t, `{"from":"created","to":"shipped"}`, string(history[1].Data))
+}
+
+func TestEventSourced_ReturnedOrdersAreIndependent(t *testing.T) {
+	repo := memory.NewEventSourcedOrderRepository(nil, 0)
+	ctx := context.Background()
+	order := newOrder()
+	require.NoError(t, repo.Save(ctx, order))
+
+	got, err := repo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+	got.Status = models.OrderStatusCancelled
+	got.Items[0].Quantity = 99
+
+	again, err := repo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.OrderStatusCreated, again.Status, "solo Update modifica lo stream")
+	require.Equal(t, 2, again.Items[0].Quantity)
+}
+
+func TestEventSourced_SnapshotsMatchFullReplay(t *testing.T) {
+	ctx := context.Background()
+	withSnapshots := memory.NewEventSourcedOrderRepository(nil, 3)
+	withoutSnapshots := memory.NewEventSourcedOrderRepository(nil, 1000)
+	a, b := newOrder(), newOrder()
+	require.NoError(t, withSnapshots.Save(ctx, a))
+	b.ID = a.ID
+	require.NoError(t, withoutSnapshots.Save(ctx, b))
+
+	for i := 1; i <= 10; i++ {
+		for _, repo := range []*memory.EventSourcedOrderRepository{withSnapshots, withoutSnapshots} {
+			current, err := repo.GetByID(ctx, a.ID)
+			require.NoError(t, err)
+			current.Items[0].Quantity = i + 2
+			current.TotalPrice = float64(i) * 12.2
+			require.NoError(t, repo.Update(ctx, current))
+		}
+	}
+
+	got, err := withSnapshots.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	want, err := withoutSnapshots.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	require.Equal(t, want.Items, got.Items)
+	require.Equal(t, want.TotalPrice, got.TotalPrice)
+	require.Equal(t, 12, got.Items[0].Quantity)
+
+	history, err := withSnapshots.GetHistory(ctx, a.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 11, "gli snapshot non sostituiscono gli eventi")
+}
+
+func TestEventSourced_UnknownOrder(t *testing.T) {
+	repo := memory.NewEventSourcedOrderRepository(nil, 0)
+	ctx := context.Background()
+	got, err := repo.GetByID(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	history, err := repo.GetHistory(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, history)
+	require.Error(t, repo.Update(ctx, &models.Order{ID: "missing"}))
+}
+
+func TestEventSourced_AppendsOutboxWithEvents(t *testing.T) {
+	outbox := repository.NewOutboxRepository("InMemory")
+	repo := repository.NewOrderRepository("InMemory", repository.WithOutbox(outbox), repository.WithEventSourcing(10))
+	ctx := context.Background()
+	require.NoError(t, repo.Save(ctx, newOrder(), &models.OutboxMessage{EventType: "order.created"}))
+
+	pending, err := outbox.GetPending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	_, ok := repo.(repository.OrderHistoryRepository)
+	require.True(t, ok)
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"purchase-cart-service/repository/memory"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductRepository_LookupBySKU(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewProductRepository(nil)
+
+	// uno SKU trova il prodotto padre
+	p, err := repo.GetProduct(ctx, "tshirt-m-white")
+	require.NoError(t, err)
+	require.Equal(t, "tshirt", p.ID)
+
+	products, err := repo.GetProducts(ctx, []string{"prod1", "tshirt-s-black", "missing"})
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	require.Equal(t, "tshirt", products["tshirt-s-black"].ID)
+
+	p, err = repo.GetProduct(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, p)
+}
+
+func TestProductRepository_Stock(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewProductRepository(nil)
+	before, err := repo.GetProduct(ctx, "tshirt")
+	require.NoError(t, err)
+
+	ok, err := repo.ReserveStock(ctx, "tshirt-l-white", 5)
+	require.NoError(t, err)
+	require.True(t, ok)
+	// la disponibilità non va sotto zero e la prenotazione fallita non toglie nulla
+	ok, err = repo.ReserveStock(ctx, "tshirt-l-white", 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = repo.ReserveStock(ctx, "missing", 1)
+	require.Error(t, err)
+
+	p, err := repo.GetProduct(ctx, "tshirt-l-white")
+	require.NoError(t, err)
+	v, _ := p.Variant("tshirt-l-white")
+	require.Zero(t, v.Stock)
+	// il prodotto letto prima non cambia
+	v, _ = before.Variant("tshirt-l-white")
+	require.Equal(t, 5, v.Stock)
+
+	require.NoError(t, repo.ReleaseStock(ctx, "tshirt-l-white", 2))
+	p, err = repo.GetProduct(ctx, "tshirt")
+	require.NoError(t, err)
+	v, _ = p.Variant("tshirt-l-white")
+	require.Equal(t, 2, v.Stock)
+}
+
+// Update da una copia letta prima di una prenotazione non ripristina lo stock
+func TestProductRepository_UpdateKeepsStock(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewProductRepository(nil)
+	stale, err := repo.GetProduct(ctx, "tshirt")
+	require.NoError(t, err)
+	before, _ := stale.Variant("tshirt-m-white")
+
+	ok, err := repo.ReserveStock(ctx, "tshirt-m-white", 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	stale.Price = 21
+	require.NoError(t, repo.Update(ctx, stale))
+	v, _ := stale.Variant("tshirt-m-white")
+	require.Equal(t, before.Stock-2, v.Stock, "la copia aggiornata riporta lo stock salvato")
+
+	p, err := repo.GetProduct(ctx, "tshirt")
+	require.NoError(t, err)
+	require.Equal(t, 21.0, p.Price)
+	v, _ = p.Variant("tshirt-m-white")
+	require.Equal(t, before.Stock-2, v.Stock)
+}